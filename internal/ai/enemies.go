@@ -0,0 +1,152 @@
+// Package ai drives the enemies roaming a level
+package ai
+
+import (
+	"slices"
+
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// DefaultCooldown is how many ticks an enemy waits between steps unless
+// its level says otherwise
+const DefaultCooldown = 30
+
+// EnemySystem moves a level's enemies through the physics engine, each on
+// its own cooldown, so they run in real time alongside the player's turns
+type EnemySystem struct {
+	engine *physics.PhysicsEngine
+	agents []*agent
+}
+
+// agent is the state an enemy keeps between steps
+type agent struct {
+	enemy *sprites.Enemy
+	timer utils.Timer
+	// step is the index of the next move on the enemy's patrol
+	step int
+}
+
+// NewEnemySystem creates a system for the enemies found in engine
+func NewEnemySystem(engine *physics.PhysicsEngine) *EnemySystem {
+	s := &EnemySystem{engine: engine}
+	for _, obj := range engine.Objects() {
+		if enemy, ok := obj.(*sprites.Enemy); ok {
+			cooldown := enemy.Cooldown
+			if cooldown <= 0 {
+				cooldown = DefaultCooldown
+			}
+			s.agents = append(s.agents, &agent{enemy: enemy, timer: utils.NewTimer(cooldown)})
+		}
+	}
+	return s
+}
+
+// Update advances the cooldowns by one tick and returns the steps taken by
+// the enemies whose cooldown ran out. Chasers head for the nearest of
+// targets.
+func (s *EnemySystem) Update(targets []utils.Cell) []physics.Move {
+	var moves []physics.Move
+	objects := s.engine.Objects()
+	for _, a := range s.agents {
+		if !slices.Contains(objects, sprites.Sprite(a.enemy)) {
+			// crushed
+			continue
+		}
+		if !a.timer.Update() {
+			continue
+		}
+		a.timer.Reset()
+		if move, ok := s.step(a, nearest(a.enemy.Position(), targets)); ok {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// step moves a along its patrol or toward target. A patrolling enemy that
+// finds its way blocked waits and tries the same step again next time.
+func (s *EnemySystem) step(a *agent, target utils.Cell) (physics.Move, bool) {
+	if a.enemy.Chase {
+		for _, dir := range towards(a.enemy.Position(), target) {
+			if move := s.engine.MoveObject(a.enemy, dir); move.Moved() {
+				return move, true
+			}
+		}
+		return physics.Move{}, false
+	}
+	if len(a.enemy.Patrol) == 0 {
+		return physics.Move{}, false
+	}
+	move := s.engine.MoveObject(a.enemy, a.enemy.Patrol[a.step])
+	if !move.Moved() {
+		return move, false
+	}
+	a.step = (a.step + 1) % len(a.enemy.Patrol)
+	return move, true
+}
+
+// towards lists the directions that bring from closer to to, the axis with
+// the longer way to go first
+func towards(from, to utils.Cell) []utils.Direction {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	var horizontal, vertical []utils.Direction
+	switch {
+	case dx > 0:
+		horizontal = []utils.Direction{utils.Right}
+	case dx < 0:
+		horizontal = []utils.Direction{utils.Left}
+	}
+	switch {
+	case dy > 0:
+		vertical = []utils.Direction{utils.Down}
+	case dy < 0:
+		vertical = []utils.Direction{utils.Up}
+	}
+	if abs(dy) > abs(dx) {
+		return append(vertical, horizontal...)
+	}
+	return append(horizontal, vertical...)
+}
+
+// nearest returns the target closest to from, or from itself when there
+// are no targets
+func nearest(from utils.Cell, targets []utils.Cell) utils.Cell {
+	res, best := from, -1
+	for _, t := range targets {
+		if d := abs(t.X-from.X) + abs(t.Y-from.Y); best < 0 || d < best {
+			res, best = t, d
+		}
+	}
+	return res
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Snapshot records how far along its patrol each enemy is
+type Snapshot struct {
+	steps []int
+}
+
+// Snapshot captures the patrol progress so it can be restored with the
+// rest of the level
+func (s *EnemySystem) Snapshot() Snapshot {
+	res := Snapshot{steps: make([]int, len(s.agents))}
+	for i, a := range s.agents {
+		res.steps[i] = a.step
+	}
+	return res
+}
+
+// Restore puts every enemy back at the patrol step s recorded
+func (s *EnemySystem) Restore(snap Snapshot) {
+	for i, a := range s.agents {
+		a.step = snap.steps[i]
+	}
+}
@@ -0,0 +1,54 @@
+// Package clipboard moves text to and from the system clipboard. Desktop
+// builds go through the platform's clipboard tools, the browser build
+// through the Clipboard API.
+//
+// Where the system clipboard can't be reached, such as on a machine
+// without clipboard tools or in a browser that withholds permission, the
+// package degrades to a clipboard private to the game, so copying and
+// pasting within it keeps working.
+package clipboard
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrUnavailable is returned when the system clipboard can't be reached
+var ErrUnavailable = errors.New("clipboard unavailable")
+
+// Result is the outcome of a Read
+type Result struct {
+	Text string
+	Err  error
+}
+
+var (
+	mu    sync.Mutex
+	local string
+)
+
+// Write puts s on the clipboard. It returns ErrUnavailable when only the
+// game's own clipboard took it.
+func Write(s string) error {
+	mu.Lock()
+	local = s
+	mu.Unlock()
+	return writeSystem(s)
+}
+
+// Read asks for the clipboard's text. Browsers answer asynchronously, so
+// the result arrives on the returned channel, which receives exactly one
+// value. When the system clipboard can't be read, the result holds the
+// text last written by the game along with ErrUnavailable.
+func Read() <-chan Result {
+	res := make(chan Result, 1)
+	readSystem(func(s string, err error) {
+		if err != nil {
+			mu.Lock()
+			s = local
+			mu.Unlock()
+		}
+		res <- Result{Text: s, Err: err}
+	})
+	return res
+}
@@ -0,0 +1,75 @@
+//go:build !js
+
+package clipboard
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// tool is a pair of commands that copy stdin to the clipboard and paste
+// it to stdout
+type tool struct {
+	copy  []string
+	paste []string
+}
+
+// tools lists the clipboard tools to try on each platform, in order
+var tools = map[string][]tool{
+	"darwin": {
+		{[]string{"pbcopy"}, []string{"pbpaste"}},
+	},
+	"windows": {
+		{
+			[]string{"powershell", "-NoProfile", "-Command", "$input | Set-Clipboard"},
+			[]string{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"},
+		},
+	},
+	"linux": {
+		{[]string{"wl-copy"}, []string{"wl-paste", "--no-newline"}},
+		{[]string{"xclip", "-selection", "clipboard"}, []string{"xclip", "-selection", "clipboard", "-o"}},
+		{[]string{"xsel", "--clipboard", "--input"}, []string{"xsel", "--clipboard", "--output"}},
+	},
+}
+
+// command returns the first command of the platform's tools that is
+// installed, as picked by pick
+func command(pick func(tool) []string) (*exec.Cmd, error) {
+	for _, t := range tools[runtime.GOOS] {
+		args := pick(t)
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		return exec.Command(path, args[1:]...), nil
+	}
+	return nil, ErrUnavailable
+}
+
+func writeSystem(s string) error {
+	cmd, err := command(func(t tool) []string { return t.copy })
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(s)
+	if err := cmd.Run(); err != nil {
+		return errors.Join(ErrUnavailable, err)
+	}
+	return nil
+}
+
+func readSystem(done func(string, error)) {
+	cmd, err := command(func(t tool) []string { return t.paste })
+	if err != nil {
+		done("", err)
+		return
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		done("", errors.Join(ErrUnavailable, err))
+		return
+	}
+	done(strings.TrimRight(string(out), "\r\n"), nil)
+}
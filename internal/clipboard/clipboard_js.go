@@ -0,0 +1,55 @@
+//go:build js
+
+package clipboard
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// system returns the browser's Clipboard API, which is missing outside
+// secure contexts
+func system() (js.Value, bool) {
+	navigator := js.Global().Get("navigator")
+	if navigator.IsUndefined() {
+		return navigator, false
+	}
+	clipboard := navigator.Get("clipboard")
+	return clipboard, !clipboard.IsUndefined()
+}
+
+// writeSystem hands s to the browser, which writes it asynchronously and
+// may still refuse, for example outside a user gesture; the game's own
+// clipboard holds it either way
+func writeSystem(s string) error {
+	clipboard, ok := system()
+	if !ok {
+		return ErrUnavailable
+	}
+	clipboard.Call("writeText", s)
+	return nil
+}
+
+func readSystem(done func(string, error)) {
+	clipboard, ok := system()
+	if !ok {
+		done("", ErrUnavailable)
+		return
+	}
+	var then, catch js.Func
+	release := func() {
+		then.Release()
+		catch.Release()
+	}
+	then = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		release()
+		done(args[0].String(), nil)
+		return nil
+	})
+	catch = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		release()
+		done("", errors.Join(ErrUnavailable, errors.New(args[0].Call("toString").String())))
+		return nil
+	})
+	clipboard.Call("readText").Call("then", then).Call("catch", catch)
+}
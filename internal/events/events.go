@@ -0,0 +1,111 @@
+// Package events runs seasonal events, turning on cosmetic touches such as
+// snow on the menus for the dates each one runs. The events are built
+// into the game, so no network is needed to know which are on.
+package events
+
+import (
+	_ "embed"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed events.toml
+var definitions []byte
+
+// dateLayout is how events give their first and last days
+const dateLayout = "01-02"
+
+// Event is a seasonal event, running on the same dates every year
+type Event struct {
+	ID    string `toml:"id"`
+	Title string `toml:"title"`
+	// From and To are the first and last days of the event, as "MM-DD".
+	// When To comes before From, the event runs over the new year.
+	From string `toml:"from"`
+	To   string `toml:"to"`
+	// Greeting is shown on the menus while the event runs
+	Greeting string `toml:"greeting"`
+	// Snow falls on the menus
+	Snow bool `toml:"snow"`
+	// DailyBoard colors the board of the daily puzzle, as "#rrggbb"
+	DailyBoard string `toml:"daily_board"`
+	from, to   int
+	dailyBoard color.Color
+}
+
+// All returns every event built into the game
+func All() ([]Event, error) {
+	var doc struct {
+		Events []Event `toml:"event"`
+	}
+	if err := toml.Unmarshal(definitions, &doc); err != nil {
+		return nil, err
+	}
+	for i := range doc.Events {
+		if err := doc.Events[i].parse(); err != nil {
+			return nil, fmt.Errorf("event %q: %w", doc.Events[i].ID, err)
+		}
+	}
+	return doc.Events, nil
+}
+
+// Active returns the events running on the day of t
+func Active(t time.Time) ([]Event, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	var res []Event
+	for _, e := range all {
+		if e.On(t) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// On reports whether e runs on the day of t
+func (e Event) On(t time.Time) bool {
+	day := monthDay(t)
+	if e.from <= e.to {
+		return day >= e.from && day <= e.to
+	}
+	return day >= e.from || day <= e.to
+}
+
+// DailyColor returns the color of the daily puzzle's board during e, if
+// it has one
+func (e Event) DailyColor() (color.Color, bool) {
+	return e.dailyBoard, e.dailyBoard != nil
+}
+
+// parse checks the event's dates and colors and keeps them parsed
+func (e *Event) parse() error {
+	from, err := time.Parse(dateLayout, e.From)
+	if err != nil {
+		return fmt.Errorf("bad start %q", e.From)
+	}
+	to, err := time.Parse(dateLayout, e.To)
+	if err != nil {
+		return fmt.Errorf("bad end %q", e.To)
+	}
+	e.from, e.to = monthDay(from), monthDay(to)
+	if e.DailyBoard != "" {
+		var c color.NRGBA
+		if _, err := fmt.Sscanf(e.DailyBoard, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+			return fmt.Errorf("bad daily board color %q", e.DailyBoard)
+		}
+		c.A = 0xff
+		e.dailyBoard = c
+	}
+	return nil
+}
+
+// monthDay numbers the day of t within its year as month*100 + day, which
+// orders days without caring about leap years
+func monthDay(t time.Time) int {
+	return int(t.Month())*100 + t.Day()
+}
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const (
+	// ambienceSeconds is the length of each synthesized loop
+	ambienceSeconds = 6
+	// ambienceFadeTicks is how long one ambience takes to cross-fade into
+	// the next
+	ambienceFadeTicks = ebiten.DefaultTPS
+	// DefaultAmbienceVolume keeps ambience well under the other sounds
+	DefaultAmbienceVolume = 0.4
+)
+
+// ambiences builds the loop for each ambience a level or section can name
+var ambiences = map[string]func(*rand.Rand) []float64{
+	"wind": synthWind,
+	"cave": synthCave,
+	"lava": synthLava,
+}
+
+// ambience keeps a background loop playing under the rest of the game's
+// sound, cross-fading from one loop to the next when the level changes.
+// Its volume is set apart from the other sounds.
+type ambience struct {
+	name   string
+	volume float64
+	// current fades in while the players in fading fade out
+	current *audio.Player
+	fading  []*audio.Player
+	tick    int
+	loops   map[string][]byte
+}
+
+func newAmbience() *ambience {
+	return &ambience{volume: DefaultAmbienceVolume, loops: make(map[string][]byte)}
+}
+
+// SetAmbienceVolume sets the ambience volume, from 0 for silence to 1
+func (g *Game) SetAmbienceVolume(v float64) {
+	g.ambience.volume = min(max(v, 0), 1)
+}
+
+// Play cross-fades into the named loop, or into silence for "". Asking
+// for the loop already playing changes nothing.
+func (a *ambience) Play(name string) {
+	if name == a.name {
+		return
+	}
+	a.name = name
+	if a.current != nil {
+		a.fading = append(a.fading, a.current)
+		a.current = nil
+	}
+	a.tick = 0
+	if name == "" {
+		return
+	}
+	synth, ok := ambiences[name]
+	if !ok {
+		log.Warn("unknown ambience", "name", name)
+		return
+	}
+	pcm, ok := a.loops[name]
+	if !ok {
+		pcm = encodePCM(synth(rand.New(rand.NewPCG(uint64(len(name)), 0))))
+		a.loops[name] = pcm
+	}
+	player, err := audioContext.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm))))
+	if err != nil {
+		log.Error("cannot play ambience", "name", name, "err", err)
+		return
+	}
+	buffered(player).SetVolume(0)
+	player.Play()
+	a.current = player
+}
+
+// Update advances the cross-fade by one tick
+func (a *ambience) Update() {
+	a.tick = min(a.tick+1, ambienceFadeTicks)
+	p := float64(a.tick) / ambienceFadeTicks
+	if a.current != nil {
+		a.current.SetVolume(a.volume * p)
+	}
+	if len(a.fading) == 0 {
+		return
+	}
+	if p == 1 {
+		for _, player := range a.fading {
+			player.Close()
+		}
+		a.fading = a.fading[:0]
+		return
+	}
+	for _, player := range a.fading {
+		player.SetVolume(min(player.Volume(), a.volume*(1-p)))
+	}
+}
+
+// encodePCM turns samples between -1 and 1 into a 16-bit stereo buffer
+func encodePCM(samples []float64) []byte {
+	return encodePanned(samples, 0)
+}
+
+// encodePanned is encodePCM panned between the left channel at -1 and the
+// right one at 1, the far channel fading out as the near one stays
+func encodePanned(samples []float64, pan float64) []byte {
+	left, right := min(1, 1-pan), min(1, 1+pan)
+	buf := make([]byte, len(samples)*4)
+	for i, s := range samples {
+		s = min(max(s, -1), 1) * math.MaxInt16
+		binary.LittleEndian.PutUint16(buf[i*4:], uint16(int16(s*left)))
+		binary.LittleEndian.PutUint16(buf[i*4+2:], uint16(int16(s*right)))
+	}
+	return buf
+}
+
+// loopable folds the tail of samples beyond n over their head, so the
+// first n samples loop without a click at the seam
+func loopable(samples []float64, n int) []float64 {
+	fade := len(samples) - n
+	for i := range fade {
+		w := float64(i) / float64(fade)
+		samples[i] = samples[n+i]*(1-w) + samples[i]*w
+	}
+	return samples[:n]
+}
+
+// lowPass smooths samples with a one-pole filter; lower k keeps less of
+// the highs
+func lowPass(samples []float64, k float64) {
+	y := 0.0
+	for i, s := range samples {
+		y += k * (s - y)
+		samples[i] = y
+	}
+}
+
+// noise returns n samples of white noise
+func noise(rng *rand.Rand, n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = rng.Float64()*2 - 1
+	}
+	return res
+}
+
+// synthWind is filtered noise swelling and dying down like gusts
+func synthWind(rng *rand.Rand) []float64 {
+	n := ambienceSeconds * sampleRate
+	samples := noise(rng, n+sampleRate/2)
+	lowPass(samples, 0.02)
+	for i := range samples {
+		t := float64(i) / float64(n)
+		samples[i] *= 2.5 * (0.6 + 0.4*math.Sin(2*math.Pi*t*2))
+	}
+	return loopable(samples, n)
+}
+
+// synthCave is water dripping now and then in a quiet, hollow room
+func synthCave(rng *rand.Rand) []float64 {
+	n := ambienceSeconds * sampleRate
+	samples := noise(rng, n+sampleRate/2)
+	lowPass(samples, 0.005)
+	for range 7 {
+		start := rng.IntN(n)
+		freq := 900 + rng.Float64()*600
+		length := sampleRate / 8
+		phase := 0.0
+		for i := range min(length, len(samples)-start) {
+			t := float64(i) / float64(length)
+			phase += 2 * math.Pi * freq * (1 - 0.4*t) / float64(sampleRate)
+			samples[start+i] += math.Sin(phase) * (1 - t) * (1 - t) * 0.3
+		}
+	}
+	return loopable(samples, n)
+}
+
+// synthLava is a low rumble with bursts of crackling
+func synthLava(rng *rand.Rand) []float64 {
+	n := ambienceSeconds * sampleRate
+	samples := noise(rng, n+sampleRate/2)
+	lowPass(samples, 0.003)
+	for i := range samples {
+		samples[i] *= 4
+	}
+	for range 60 {
+		start := rng.IntN(n)
+		length := sampleRate/200 + rng.IntN(sampleRate/100)
+		for i := range min(length, len(samples)-start) {
+			t := float64(i) / float64(length)
+			samples[start+i] += (rng.Float64()*2 - 1) * (1 - t) * 0.25
+		}
+	}
+	return loopable(samples, n)
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/zrcoder/icer/internal/profile"
+)
+
+const defaultSampleRate = 44100
+
+// sampleRate is the rate the device is opened at and every sound
+// synthesized at
+var sampleRate = defaultSampleRate
+
+var (
+	audioContext *audio.Context
+	// bufferSize is how much sound each player queues ahead; 0 leaves it
+	// to the driver
+	bufferSize time.Duration
+	popSound   []byte
+	testTone   []byte
+)
+
+// AudioConfig is read from audio.toml in the profile directory. Smaller
+// buffers cut the delay before sounds are heard; larger ones stop
+// crackling on slow devices such as Bluetooth headsets.
+type AudioConfig struct {
+	// SampleRate is the rate to open the device at, such as 44100 or 48000
+	SampleRate int `toml:"sample_rate"`
+	// BufferMillis is how many milliseconds of sound to queue ahead; 0
+	// leaves it to the driver
+	BufferMillis int `toml:"buffer_ms"`
+}
+
+// audioMigrations upgrade audio.toml files written for older versions of
+// the game. The format hasn't changed yet.
+var audioMigrations []profile.Migration
+
+// DefaultAudioConfig is the audio settings used when there are none
+func DefaultAudioConfig() AudioConfig {
+	return AudioConfig{SampleRate: defaultSampleRate}
+}
+
+// LoadAudioConfig reads the audio settings, if there are any. When they
+// can't be read, the defaults are returned along with the error.
+func LoadAudioConfig() (AudioConfig, error) {
+	c := DefaultAudioConfig()
+	if _, err := profile.DecodeFile("audio.toml", audioMigrations, &c); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return DefaultAudioConfig(), err
+	}
+	return c, nil
+}
+
+// SetupAudio opens the audio device with the settings c. The device can
+// only be opened once, so this must be called before the game starts.
+func SetupAudio(c AudioConfig) error {
+	if audioContext != nil {
+		return errors.New("audio is already set up")
+	}
+	if c.SampleRate < 8000 || c.SampleRate > 192000 {
+		return fmt.Errorf("unsupported sample rate %d", c.SampleRate)
+	}
+	if c.BufferMillis < 0 {
+		return fmt.Errorf("negative audio buffer of %d ms", c.BufferMillis)
+	}
+	sampleRate = c.SampleRate
+	bufferSize = time.Duration(c.BufferMillis) * time.Millisecond
+	audioContext = audio.NewContext(sampleRate)
+	popSound = synthPop()
+	testTone = synthTestTone()
+	log.Debug("audio set up", "rate", sampleRate, "buffer", bufferSize)
+	return nil
+}
+
+// ensureAudio opens the device with the default settings unless
+// SetupAudio already has
+func ensureAudio() {
+	if audioContext == nil {
+		SetupAudio(DefaultAudioConfig())
+	}
+}
+
+// buffered sets the configured buffer size on p
+func buffered(p *audio.Player) *audio.Player {
+	if bufferSize > 0 {
+		p.SetBufferSize(bufferSize)
+	}
+	return p
+}
+
+// playSound plays a 16-bit stereo PCM buffer once
+func playSound(pcm []byte) {
+	buffered(audioContext.NewPlayerFromBytes(pcm)).Play()
+}
+
+// playPanned plays samples between -1 and 1 once, panned from -1 for
+// fully left to 1 for fully right
+func playPanned(samples []float64, pan float64) {
+	playSound(encodePanned(samples, pan))
+}
+
+// playTestTone plays a tone to check the output device and describes the
+// settings it plays with
+func playTestTone() string {
+	playSound(testTone)
+	buffer := "driver default buffer"
+	if bufferSize > 0 {
+		buffer = fmt.Sprintf("%d ms buffer", bufferSize.Milliseconds())
+	}
+	return fmt.Sprintf("Test tone: %d Hz, %s", sampleRate, buffer)
+}
+
+// synthPop builds a short rising blip used for star pop-ins
+func synthPop() []byte {
+	const duration = 0.12
+	n := int(float64(sampleRate) * duration)
+	buf := make([]byte, n*4)
+	phase := 0.0
+	for i := range n {
+		t := float64(i) / float64(n)
+		freq := 660 + 660*t
+		phase += 2 * math.Pi * freq / float64(sampleRate)
+		v := int16(math.Sin(phase) * (1 - t) * 0.3 * math.MaxInt16)
+		binary.LittleEndian.PutUint16(buf[i*4:], uint16(v))
+		binary.LittleEndian.PutUint16(buf[i*4+2:], uint16(v))
+	}
+	return buf
+}
+
+// synthTestTone builds a steady 440 Hz beep, faded in and out so it
+// doesn't click
+func synthTestTone() []byte {
+	const (
+		duration = 0.8
+		fade     = 0.05
+	)
+	n := int(float64(sampleRate) * duration)
+	samples := make([]float64, n)
+	for i := range samples {
+		t := float64(i) / float64(sampleRate)
+		env := min(1, t/fade, (duration-t)/fade)
+		samples[i] = math.Sin(2*math.Pi*440*t) * env * 0.3
+	}
+	return encodePCM(samples)
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/rendering"
+	"golang.org/x/image/colornames"
+)
+
+// showProblems explains why the current level can't be played, instead
+// of starting it
+func (g *Game) showProblems(problems []string) {
+	g.problems = problems
+	g.setState(StateBroken)
+}
+
+// diagnosticLines formats the diagnostics of a level for showProblems
+func diagnosticLines(ds []levels.Diagnostic) []string {
+	res := make([]string, len(ds))
+	for i, d := range ds {
+		res[i] = d.String()
+	}
+	return res
+}
+
+// updateBroken goes back to the menu once the problems have been read
+func (g *Game) updateBroken() {
+	if g.input.JustPressed(input.ActionBack) || g.input.JustPressed(input.ActionConfirm) {
+		g.setState(StateSelect)
+	}
+}
+
+// drawBroken lists the problems found in the level
+func (g *Game) drawBroken(screen *ebiten.Image) {
+	drawCentered(screen, "This level can't be played", WindowWidth/2, 20)
+	rendering.DrawText(screen, strings.Join(g.problems, "\n"), defaultFace, mirrorX(40), 70, rendering.TextStyle{
+		Color: colornames.Orangered,
+		Width: WindowWidth - 80,
+	})
+	drawCentered(screen, g.input.Prompt(input.ActionBack, "go back"), WindowWidth/2, WindowHeight-30)
+}
+
+// AddNotice adds msg to the notice shown on the menu, for settings that
+// couldn't be loaded before the game started
+func (g *Game) AddNotice(msg string) {
+	if g.notice != "" {
+		g.notice += "\n"
+	}
+	g.notice += msg
+}
+
+// drawNotice shows the notice left from loading the player's progress and
+// settings
+func (g *Game) drawNotice(screen *ebiten.Image) {
+	if g.notice == "" {
+		return
+	}
+	rendering.DrawText(screen, g.notice, defaultFace, WindowWidth/2, WindowHeight-60, rendering.TextStyle{
+		Align:   text.AlignCenter,
+		Color:   colornames.Orange,
+		Outline: colornames.Black,
+		Width:   WindowWidth - 80,
+	})
+}
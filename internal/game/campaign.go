@@ -0,0 +1,125 @@
+package game
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const nodeRadius = 24
+
+// campaignMap is the world-map style alternative to the numeric level select
+type campaignMap struct {
+	tick int
+}
+
+// updateMap handles campaign map state updates
+func (g *Game) updateMap() {
+	g.campaign.tick++
+	if g.input.JustPressed(input.ActionBack) {
+		g.setState(StateSelect)
+		return
+	}
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	x, y := ebiten.CursorPosition()
+	for i, node := range mapNodes(g.levelsManager.CurrentSection()) {
+		cursor := utils.Vec2{X: float64(x), Y: float64(y)}
+		if cursor.Sub(node.Vec2()).Len() <= nodeRadius {
+			if g.levelsManager.CurrentSection().Unlocked(i) {
+				g.startLevel(i)
+			}
+			return
+		}
+	}
+}
+
+// drawMap draws the current section as nodes connected by paths
+func (g *Game) drawMap(screen *ebiten.Image) {
+	section := g.levelsManager.CurrentSection()
+	nodes := mapNodes(section)
+	for i := 1; i < len(nodes); i++ {
+		a, b := nodes[i-1], nodes[i]
+		clr := colornames.Dimgray
+		if section.Level(i - 1).Completed {
+			clr = colornames.Gold
+		}
+		vector.StrokeLine(screen, float32(a.X), float32(a.Y), float32(b.X), float32(b.Y), 6, clr, true)
+	}
+	next := nextLevel(section)
+	for i, node := range nodes {
+		r := float32(nodeRadius)
+		clr := colornames.Dimgray
+		switch {
+		case section.Level(i).Completed:
+			clr = colornames.Gold
+		case i >= section.LevelCount:
+			clr = colornames.Mediumpurple
+		case i == next:
+			clr = colornames.Deepskyblue
+			r *= 1 + 0.12*float32(math.Sin(float64(g.campaign.tick)/8))
+		}
+		vector.DrawFilledCircle(screen, float32(node.X), float32(node.Y), r, clr, true)
+		drawCentered(screen, strconv.Itoa(i+1), int(node.X), int(node.Y)-10)
+	}
+	drawCentered(screen, section.Title, WindowWidth/2, 30)
+	drawCentered(screen, g.input.Prompt(input.ActionBack, "go back"), WindowWidth/2, WindowHeight-50)
+}
+
+// mapNodes returns the node positions declared in the section index,
+// falling back to an evenly spaced row for levels without one
+func mapNodes(s *levels.Section) []utils.Pixel {
+	nodes := make([]utils.Pixel, s.VisibleLevels())
+	for i := range nodes {
+		if i < len(s.Map) {
+			nodes[i] = utils.Pixel{X: float64(s.Map[i][0]), Y: float64(s.Map[i][1])}
+			continue
+		}
+		nodes[i] = utils.Pixel{
+			X: float64(WindowWidth * (i + 1) / (len(nodes) + 1)),
+			Y: WindowHeight / 2,
+		}
+	}
+	return nodes
+}
+
+// nextLevel returns the index of the first level not yet completed
+func nextLevel(s *levels.Section) int {
+	for i := range s.VisibleLevels() {
+		if !s.Level(i).Completed {
+			return i
+		}
+	}
+	return -1
+}
+
+func drawCentered(screen *ebiten.Image, s string, x, y int) {
+	rendering.DrawText(screen, s, defaultFace, float64(x), float64(y), rendering.TextStyle{
+		Align:   text.AlignCenter,
+		Outline: colornames.Black,
+	})
+}
+
+// drawChecks ticks the select UI's buttons for completed levels
+func (g *Game) drawChecks(screen *ebiten.Image) {
+	section := g.levelsManager.CurrentSection()
+	for i, button := range g.levelButtons {
+		if !section.Level(i).Completed {
+			continue
+		}
+		r := button.GetWidget().Rect
+		x, y := float32(r.Max.X-24), float32(r.Min.Y+10)
+		vector.StrokeLine(screen, x, y+8, x+6, y+14, 3, colornames.Limegreen, true)
+		vector.StrokeLine(screen, x+6, y+14, x+16, y, 3, colornames.Limegreen, true)
+	}
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	maxStars      = 3
+	scorePerStar  = 1000
+	confettiCount = 150
+	starRadius    = 36
+)
+
+var confettiColors = []color.Color{
+	colornames.Orange, colornames.Deepskyblue, colornames.Lightgreen,
+	colornames.Hotpink, colornames.Gold, colornames.White,
+}
+
+// celebration plays the win sequence: confetti, star pop-ins and a score count-up
+type celebration struct {
+	timeline   *utils.Timeline
+	confetti   []*confetto
+	stars      int
+	slots      int // maxStars, and one more for the bonus star
+	starScales [maxStars + 1]float64
+	shownScore int
+}
+
+type confetto struct {
+	pos   utils.Vec2
+	vel   utils.Vec2
+	angle float64
+	spin  float64
+	color color.Color
+}
+
+// newCelebration shows stars earned out of maxStars, with a slot for the
+// bonus star when the level has one to give
+func newCelebration(stars int, bonus bool, score int) *celebration {
+	c := &celebration{
+		timeline: utils.NewTimeline(),
+		confetti: make([]*confetto, confettiCount),
+		stars:    stars,
+		slots:    maxStars,
+	}
+	if bonus {
+		c.slots++
+	}
+	for i := range c.confetti {
+		c.confetti[i] = &confetto{
+			pos:   utils.Vec2{X: rand.Float64() * WindowWidth, Y: -rand.Float64() * WindowHeight / 2},
+			vel:   utils.Vec2{X: rand.Float64()*2 - 1, Y: rand.Float64()*2 + 1},
+			angle: rand.Float64() * math.Pi,
+			spin:  rand.Float64()*0.2 - 0.1,
+			color: confettiColors[rand.IntN(len(confettiColors))],
+		}
+	}
+	for i := range stars {
+		start := 20 + i*15
+		c.timeline.At(start, func() { playSound(popSound) })
+		c.timeline.During(start, 12, func(p float64) { c.starScales[i] = utils.EaseOutBack(p) })
+	}
+	c.timeline.During(20+c.slots*15, 60, func(p float64) {
+		c.shownScore = int(float64(score) * p)
+	})
+	return c
+}
+
+// Update advances the sequence by one tick
+func (c *celebration) Update() {
+	c.timeline.Update()
+	for _, p := range c.confetti {
+		p.vel.Y += 0.05
+		p.pos = p.pos.Add(p.vel).Add(utils.Vec2{X: math.Sin(p.angle) * 0.5})
+		p.angle += p.spin
+	}
+}
+
+// Draw renders the current frame of the sequence
+func (c *celebration) Draw(screen *ebiten.Image) {
+	for _, p := range c.confetti {
+		w := float32(8 * math.Abs(math.Cos(p.angle)))
+		vector.DrawFilledRect(screen, float32(p.pos.X), float32(p.pos.Y), w+1, 4, p.color, false)
+	}
+	cx := float32(WindowWidth / 2)
+	cy := float32(WindowHeight / 2)
+	for i := range c.slots {
+		x := cx + (float32(i)-float32(c.slots-1)/2)*starRadius*2.5
+		if i >= c.stars {
+			drawStar(screen, x, cy, starRadius, colornames.Dimgray)
+			continue
+		}
+		drawStar(screen, x, cy, starRadius*float32(c.starScales[i]), colornames.Gold)
+	}
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(cx), float64(cy)+starRadius*2)
+	op.PrimaryAlign = text.AlignCenter
+	op.ColorScale.ScaleWithColor(colornames.White)
+	text.Draw(screen, fmt.Sprintf("SCORE %d", c.shownScore), defaultFace, op)
+}
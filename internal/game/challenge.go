@@ -0,0 +1,66 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/zrcoder/icer/internal/levels"
+	"golang.org/x/image/colornames"
+)
+
+const heartRadius = 10
+
+// challenge tracks the optional limited-retry mode: every section grants
+// a fixed number of lives, each loss costs one and earned stars refill them.
+// The lives spent are saved with the player's progress.
+type challenge struct {
+	enabled bool
+	levels  *levels.Manager
+}
+
+func newChallenge(m *levels.Manager) *challenge {
+	return &challenge{levels: m}
+}
+
+// Lives returns the lives left in section
+func (c *challenge) Lives(s *levels.Section) int {
+	return s.Lives - s.LivesLost
+}
+
+// CanPlay reports whether section still has a life to spend
+func (c *challenge) CanPlay(s *levels.Section) bool {
+	return !c.enabled || c.Lives(s) > 0
+}
+
+// LoseLife spends one life in section
+func (c *challenge) LoseLife(s *levels.Section) {
+	if c.enabled {
+		c.levels.SetLivesLost(s, s.LivesLost+1)
+	}
+}
+
+// Refill restores one life per earned star, up to the section's maximum
+func (c *challenge) Refill(s *levels.Section, stars int) {
+	if c.enabled {
+		c.levels.SetLivesLost(s, s.LivesLost-stars)
+	}
+}
+
+// Reset restores all lives in section
+func (c *challenge) Reset(s *levels.Section) {
+	c.levels.SetLivesLost(s, 0)
+}
+
+// Draw renders the section's lives as hearts in the top right corner
+func (c *challenge) Draw(screen *ebiten.Image, s *levels.Section) {
+	if !c.enabled {
+		return
+	}
+	lives := c.Lives(s)
+	for i := range s.Lives {
+		x := float32(WindowWidth - (s.Lives-i)*heartRadius*3)
+		clr := colornames.Crimson
+		if i >= lives {
+			clr = colornames.Dimgray
+		}
+		drawHeart(screen, x, heartRadius, heartRadius, clr)
+	}
+}
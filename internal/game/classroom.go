@@ -0,0 +1,95 @@
+package game
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// classroom records how a student fares on every level attempted during a
+// session, for their teacher to collect as a CSV file when it ends
+type classroom struct {
+	student  string
+	dir      string
+	started  time.Time
+	attempts []attempt
+}
+
+// attempt is one level played to a win or a loss
+type attempt struct {
+	level  string // the level's code
+	title  string
+	solved bool
+	moves  int
+	ticks  int
+	hints  int
+}
+
+// SetClassroom records the session of student, to be exported into dir
+func (g *Game) SetClassroom(student, dir string) {
+	g.classroom = &classroom{student: student, dir: dir, started: time.Now()}
+}
+
+// recordAttempt notes the end of the current level for the classroom
+func (g *Game) recordAttempt(solved bool) {
+	if g.classroom == nil {
+		return
+	}
+	g.classroom.attempts = append(g.classroom.attempts, attempt{
+		level:  g.levelsManager.Code(),
+		title:  g.levelsManager.CurrentLevel().Title,
+		solved: solved,
+		moves:  g.rules.MovesTaken(),
+		ticks:  g.elapsed,
+		hints:  g.hints,
+	})
+}
+
+// ExportClassroom writes the session's results to the classroom folder,
+// one row per attempt, and returns the file written. It does nothing
+// outside classroom mode.
+func (g *Game) ExportClassroom() (string, error) {
+	c := g.classroom
+	if c == nil {
+		return "", nil
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.csv", fileSafe(c.student), c.started.Format("20060102-150405"))
+	path := filepath.Join(c.dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"student", "level", "title", "solved", "moves", "seconds", "hints"})
+	for _, a := range c.attempts {
+		w.Write([]string{
+			c.student, a.level, a.title, strconv.FormatBool(a.solved),
+			strconv.Itoa(a.moves), strconv.Itoa(a.ticks / ebiten.DefaultTPS), strconv.Itoa(a.hints),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// fileSafe replaces the characters of s that don't belong in a file name
+func fileSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"image"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// captionTicks is how long a caption stays up, the last
+	// captionFadeTicks of it fading out
+	captionTicks     = 2 * ebiten.DefaultTPS
+	captionFadeTicks = ebiten.DefaultTPS / 3
+	// nearEnemy is how many steps away an enemy is heard approaching
+	nearEnemy = 3
+	// captionInset keeps captions for cells off the screen inside it
+	captionInset = 40
+)
+
+// cueKind is an event the player hears about
+type cueKind int
+
+const (
+	cueHiss cueKind = iota
+	cueEnemy
+	cueBlast
+)
+
+// cueSounds and cueLabels give the sound of each cue and the caption
+// shown for it
+var (
+	cueSounds = map[cueKind]func() []float64{
+		cueHiss:  synthHiss,
+		cueEnemy: synthGrowl,
+		cueBlast: synthBoom,
+	}
+	cueLabels = map[cueKind]string{
+		cueHiss:  "hiss",
+		cueEnemy: "growl",
+		cueBlast: "boom",
+	}
+)
+
+// caption shows a cue on screen for players who can't hear it, until
+// its expiry runs
+type caption struct {
+	kind   cueKind
+	pos    utils.Cell
+	expiry *utils.Task
+}
+
+// cue plays the sound of something happening at pos, panned toward where
+// it is on screen, and with captions on also shows it
+func (g *Game) cue(kind cueKind, pos utils.Cell) {
+	p := g.renderer.CenterOf(image.Rect(0, 0, WindowWidth, WindowHeight), pos)
+	pan := min(max(p.X/WindowWidth*2-1, -1), 1)
+	playPanned(cueSounds[kind](), pan*0.8)
+	if g.captionsOn {
+		c := &caption{kind: kind, pos: pos}
+		c.expiry = g.timers.After(captionTicks, func() {
+			g.captions = slices.DeleteFunc(g.captions, func(other *caption) bool { return other == c })
+		})
+		g.captions = append(g.captions, c)
+	}
+}
+
+// SetCaptions turns captions for sound cues on or off
+func (g *Game) SetCaptions(on bool) {
+	g.captionsOn = on
+	if !on {
+		g.clearCaptions()
+	}
+}
+
+// clearCaptions takes every caption off the screen
+func (g *Game) clearCaptions() {
+	for _, c := range g.captions {
+		c.expiry.Stop()
+	}
+	g.captions = nil
+}
+
+// updateCues toggles captions with F10 and listens for enemies drawing
+// near; captions come down as the game's timers run
+func (g *Game) updateCues() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) {
+		g.SetCaptions(!g.captionsOn)
+		g.warning = "Captions off"
+		if g.captionsOn {
+			g.warning = "Captions on"
+		}
+	}
+	for _, obj := range g.engine.Objects() {
+		enemy, ok := obj.(*sprites.Enemy)
+		if !ok {
+			continue
+		}
+		near := false
+		for _, player := range g.players {
+			near = near || steps(player.Position(), enemy.Position()) <= nearEnemy
+		}
+		// enemies heard already, or near from the start, make no sound
+		if was, seen := g.nearEnemies[enemy]; near && seen && !was {
+			g.cue(cueEnemy, enemy.Position())
+		}
+		g.nearEnemies[enemy] = near
+	}
+}
+
+// steps returns how many steps apart two cells are
+func steps(a, b utils.Cell) int {
+	return max(a.X-b.X, b.X-a.X) + max(a.Y-b.Y, b.Y-a.Y)
+}
+
+// drawCaptions labels each cue where it came from. Cues from cells off
+// the screen are labeled at its edge, with an arrow pointing their way.
+func (g *Game) drawCaptions(screen *ebiten.Image) {
+	for _, c := range g.captions {
+		p := g.renderer.CenterOf(screen.Bounds(), c.pos)
+		x := min(max(p.X, captionInset), WindowWidth-captionInset)
+		y := min(max(p.Y, captionInset), WindowHeight-captionInset)
+		label := cueLabels[c.kind]
+		switch {
+		case p.X < x:
+			label = "← " + label
+		case p.X > x:
+			label += " →"
+		case p.Y < y:
+			label = "↑ " + label
+		case p.Y > y:
+			label += " ↓"
+		}
+		alpha := min(1, float64(c.expiry.Left())/captionFadeTicks)
+		w, h := text.Measure(label, defaultFace, 0)
+		vector.DrawFilledRect(screen, float32(x-w/2-6), float32(y-h/2-3), float32(w+12), float32(h+6),
+			utils.WithAlpha(colornames.Black, 0.7*alpha), false)
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(x, y-h/2)
+		op.PrimaryAlign = text.AlignCenter
+		op.ColorScale.ScaleWithColor(colornames.White)
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		text.Draw(screen, label, defaultFace, op)
+	}
+}
+
+// synthHiss is a burst of bright noise dying away, like a flame doused
+func synthHiss() []float64 {
+	n := sampleRate * 2 / 5
+	samples := noise(rand.New(rand.NewPCG(1, 2)), n)
+	low := 0.0
+	for i, s := range samples {
+		low += 0.1 * (s - low)
+		t := float64(i) / float64(n)
+		samples[i] = (s - low) * (1 - t) * (1 - t) * 0.4
+	}
+	return samples
+}
+
+// synthGrowl is two low pulses, like something heavy drawing near
+func synthGrowl() []float64 {
+	n := sampleRate / 2
+	samples := make([]float64, n)
+	for i := range samples {
+		t := float64(i) / float64(sampleRate)
+		pulse := math.Max(0, math.Sin(2*math.Pi*4*t))
+		samples[i] = math.Sin(2*math.Pi*110*t) * pulse * 0.4
+	}
+	return samples
+}
+
+// synthBoom is a deep thud of noise, like a bomb going off
+func synthBoom() []float64 {
+	n := sampleRate * 3 / 5
+	samples := noise(rand.New(rand.NewPCG(3, 4)), n)
+	lowPass(samples, 0.01)
+	for i := range samples {
+		t := float64(i) / float64(n)
+		samples[i] *= 6 * (1 - t) * (1 - t)
+	}
+	return samples
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+const (
+	defeatFlashTicks = 20
+	defeatDimTicks   = 30
+	defeatMaxDim     = 0.65
+	defeatMaxFlash   = 0.63
+)
+
+var defeatFlash = color.NRGBA{R: 200, A: 255}
+
+// defeat plays the lose sequence: a red flash, a screen dim and then the retry panel
+type defeat struct {
+	timeline     *utils.Timeline
+	flash        float64
+	dim          float64
+	panelVisible bool
+}
+
+func newDefeat() *defeat {
+	d := &defeat{timeline: utils.NewTimeline()}
+	d.timeline.
+		During(0, defeatFlashTicks, func(p float64) { d.flash = 1 - p }).
+		During(defeatFlashTicks/2, defeatDimTicks, func(p float64) { d.dim = p * defeatMaxDim }).
+		At(defeatFlashTicks/2+defeatDimTicks, func() { d.panelVisible = true })
+	return d
+}
+
+// Update advances the sequence by one tick
+func (d *defeat) Update() {
+	d.timeline.Update()
+}
+
+// Draw renders the flash and dim overlays
+func (d *defeat) Draw(screen *ebiten.Image) {
+	if d.dim > 0 {
+		fillScreen(screen, utils.WithAlpha(color.Black, d.dim))
+	}
+	if d.flash > 0 {
+		fillScreen(screen, utils.WithAlpha(defeatFlash, d.flash*defeatMaxFlash))
+	}
+}
+
+func fillScreen(screen *ebiten.Image, c color.Color) {
+	vector.DrawFilledRect(screen, 0, 0, WindowWidth, WindowHeight, c, false)
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// pacingTolerance is how far a tick interval may stray from the
+	// nominal one, as a fraction of it, before it is logged
+	pacingTolerance = 0.5
+	latencySamples  = 60
+)
+
+// diagnostics is a dev overlay, toggled with F3, that measures the parts
+// of input latency the game can see: from polling an input to the end of
+// the tick that applied it, and from there to the next draw
+type diagnostics struct {
+	enabled     bool
+	lastTick    time.Time
+	tickStart   time.Time
+	pendingDraw time.Time
+	toApply     latencyStats
+	toDraw      latencyStats
+	hiccups     int
+}
+
+// latencyStats keeps a sliding window of durations
+type latencyStats struct {
+	samples []time.Duration
+}
+
+func (s *latencyStats) Add(d time.Duration) {
+	s.samples = append(s.samples, d)
+	if len(s.samples) > latencySamples {
+		s.samples = s.samples[1:]
+	}
+}
+
+func (s *latencyStats) String() string {
+	if len(s.samples) == 0 {
+		return "-"
+	}
+	var sum, peak time.Duration
+	for _, d := range s.samples {
+		sum += d
+		peak = max(peak, d)
+	}
+	avg := sum / time.Duration(len(s.samples))
+	return fmt.Sprintf("avg %v max %v", avg.Round(time.Microsecond), peak.Round(time.Microsecond))
+}
+
+// BeginTick is called before input is polled
+func (d *diagnostics) BeginTick() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		d.enabled = !d.enabled
+	}
+	now := time.Now()
+	if !d.lastTick.IsZero() {
+		nominal := time.Second / time.Duration(ebiten.TPS())
+		interval := now.Sub(d.lastTick)
+		if (interval - nominal).Abs() > time.Duration(float64(nominal)*pacingTolerance) {
+			d.hiccups++
+			if d.enabled {
+				log.Warn("irregular frame pacing", "interval", interval, "nominal", nominal)
+			}
+		}
+	}
+	d.lastTick = now
+	d.tickStart = now
+}
+
+// EndTick is called once the tick's logic has run; active tells whether
+// the tick handled new input
+func (d *diagnostics) EndTick(active bool) {
+	if !active {
+		return
+	}
+	now := time.Now()
+	d.toApply.Add(now.Sub(d.tickStart))
+	d.pendingDraw = now
+}
+
+// Draw records the apply-to-draw segment and renders the overlay
+func (d *diagnostics) Draw(screen *ebiten.Image) {
+	if !d.pendingDraw.IsZero() {
+		d.toDraw.Add(time.Since(d.pendingDraw))
+		d.pendingDraw = time.Time{}
+	}
+	if !d.enabled {
+		return
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf(
+		"FPS %.1f TPS %.1f\ninput->apply %s\napply->draw  %s\npacing hiccups %d",
+		ebiten.ActualFPS(), ebiten.ActualTPS(), &d.toApply, &d.toDraw, d.hiccups,
+	), 4, WindowHeight-70)
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/rendering"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	dialogHeight  = 140
+	dialogPadding = 20
+)
+
+// dialog shows paged text at the bottom of the screen, optionally ending
+// with a list of choices; while open it receives all input and the level
+// is paused
+type dialog struct {
+	pages    []string
+	choices  []levels.Choice
+	page     int
+	selected int
+	onChoose func(flag string)
+}
+
+func newDialog(npc levels.NPC, onChoose func(flag string)) *dialog {
+	return &dialog{
+		pages:    npc.Pages,
+		choices:  npc.Choices,
+		onChoose: onChoose,
+	}
+}
+
+// Update advances to the next page or picks the selected choice on
+// confirm, and reports whether the dialog has been closed
+func (d *dialog) Update(in *input.Manager) bool {
+	if in.JustPressed(input.ActionBack) {
+		return true
+	}
+	choosing := d.page == len(d.pages)-1 && len(d.choices) > 0
+	if choosing {
+		if in.JustPressed(input.ActionUp) {
+			d.selected = (d.selected + len(d.choices) - 1) % len(d.choices)
+		}
+		if in.JustPressed(input.ActionDown) {
+			d.selected = (d.selected + 1) % len(d.choices)
+		}
+	}
+	if in.JustPressed(input.ActionConfirm) {
+		if choosing {
+			d.onChoose(d.choices[d.selected].Flag)
+		}
+		d.page++
+	}
+	return d.page >= len(d.pages)
+}
+
+// Draw renders the dialog box with the current page and a prompt
+// matching the device the player is using
+func (d *dialog) Draw(screen *ebiten.Image, in *input.Manager) {
+	if d.page >= len(d.pages) {
+		return
+	}
+	y := float32(WindowHeight - dialogHeight - dialogPadding)
+	w := float32(WindowWidth - dialogPadding*2)
+	vector.DrawFilledRect(screen, dialogPadding, y, w, dialogHeight, colornames.Black, false)
+	vector.StrokeRect(screen, dialogPadding, y, w, dialogHeight, 3, colornames.Gainsboro, false)
+
+	lineHeight := defaultFace.Metrics().HAscent * 1.6
+	// lines start at the right edge of the box for right to left locales,
+	// and wrap at the same width
+	rendering.DrawText(screen, d.pages[d.page], defaultFace, mirrorX(dialogPadding*2), float64(y)+dialogPadding, rendering.TextStyle{
+		Width:       float64(w) - dialogPadding*2,
+		LineSpacing: lineHeight,
+	})
+
+	if d.page == len(d.pages)-1 {
+		for i, choice := range d.choices {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(mirrorX(dialogPadding*3), float64(y)+dialogPadding+lineHeight*float64(i+1))
+			label := "  " + choice.Text
+			if i == d.selected {
+				label = "> " + choice.Text
+				op.ColorScale.ScaleWithColor(colornames.Orange)
+			} else {
+				op.ColorScale.ScaleWithColor(colornames.Gray)
+			}
+			text.Draw(screen, label, defaultFace, op)
+		}
+	}
+
+	prompt := in.Prompt(input.ActionConfirm, "continue")
+	rendering.DrawText(screen, fmt.Sprintf("%s  %d/%d", prompt, d.page+1, len(d.pages)), defaultFace,
+		mirrorX(WindowWidth-dialogPadding*2), float64(y)+dialogHeight-dialogPadding*2, rendering.TextStyle{
+			Color: colornames.Gray,
+			Align: text.AlignEnd,
+		})
+}
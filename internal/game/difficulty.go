@@ -0,0 +1,54 @@
+package game
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/solver"
+)
+
+// rateLevels queues the levels still unrated on the solver pool, behind
+// whatever the player is waiting for, as solving them can take a while.
+// Levels the solver can't rate get a zero rating, so they aren't tried
+// again.
+func (g *Game) rateLevels() {
+	unrated := g.levelsManager.Unrated()
+	g.rating = len(unrated)
+	for _, u := range unrated {
+		g.solving.Background(context.Background(), func(ctx context.Context) func() {
+			d, err := solver.Rate(ctx, u.Section, u.Level, solver.MaxStates)
+			if err != nil {
+				log.Debug("level not rated", "level", u.Level.Title, "err", err)
+			}
+			return func() {
+				g.rating--
+				g.levelsManager.SetDifficulty(u.Key, d)
+				g.rated = true
+			}
+		})
+	}
+}
+
+// updateRatings shows the ratings made so far on the level buttons, and
+// starts on levels left unrated, such as ones reloaded in dev mode
+func (g *Game) updateRatings() {
+	if g.rating == 0 {
+		g.rateLevels()
+	}
+	if g.rated {
+		g.rated = false
+		g.createSelectUI()
+	}
+}
+
+// levelLabel is the text of the button for level i of the current
+// section: its number, over a dot per point of difficulty once rated
+func (g *Game) levelLabel(i int) string {
+	label := strconv.Itoa(i + 1)
+	if d := g.levelsManager.CurrentSection().Level(i).Difficulty; d.Rated() {
+		label += "\n" + strings.Repeat("•", d.Score)
+	}
+	return label
+}
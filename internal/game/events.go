@@ -0,0 +1,100 @@
+package game
+
+import (
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/events"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const snowflakeCount = 120
+
+// snowflake drifts down the menus during a snowy event
+type snowflake struct {
+	pos    utils.Vec2
+	speed  float64
+	radius float32
+	// phase sets where the flake is in its sideways sway
+	phase float64
+}
+
+// SetEvents turns the seasonal events running today on or off. They are
+// on unless the player opts out.
+func (g *Game) SetEvents(on bool) {
+	g.events, g.snow = nil, nil
+	if !on {
+		return
+	}
+	active, err := events.Active(time.Now())
+	if err != nil {
+		log.Error("cannot load events", "err", err)
+		return
+	}
+	g.events = active
+	for _, e := range active {
+		log.Debug("event running", "id", e.ID)
+		if e.Snow && g.snow == nil {
+			g.snow = make([]*snowflake, snowflakeCount)
+			for i := range g.snow {
+				g.snow[i] = &snowflake{
+					pos:    utils.Vec2{X: rand.Float64() * WindowWidth, Y: rand.Float64() * WindowHeight},
+					speed:  rand.Float64()*0.8 + 0.4,
+					radius: float32(rand.Float64()*1.5 + 1),
+					phase:  rand.Float64() * 2 * math.Pi,
+				}
+			}
+		}
+	}
+}
+
+// eventDailyBoard returns the board color an event gives the daily
+// puzzle, or nil
+func (g *Game) eventDailyBoard() color.Color {
+	for _, e := range g.events {
+		if c, ok := e.DailyColor(); ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// updateSnow lets the snow fall, wrapping flakes back to the top
+func (g *Game) updateSnow() {
+	for _, f := range g.snow {
+		f.phase += 0.03
+		f.pos.Y += f.speed
+		f.pos.X += math.Sin(f.phase) * 0.4
+		if f.pos.Y > WindowHeight {
+			f.pos = utils.Vec2{X: rand.Float64() * WindowWidth, Y: -2}
+		}
+	}
+}
+
+// drawEvents draws the snow and greetings of the running events over the
+// menus
+func (g *Game) drawEvents(screen *ebiten.Image) {
+	for _, f := range g.snow {
+		vector.DrawFilledCircle(screen, float32(f.pos.X), float32(f.pos.Y), f.radius, colornames.White, true)
+	}
+	y := float64(WindowHeight - 30)
+	for _, e := range g.events {
+		if e.Greeting == "" {
+			continue
+		}
+		rendering.DrawText(screen, e.Greeting, defaultFace, WindowWidth/2, y, rendering.TextStyle{
+			Align:   text.AlignCenter,
+			Color:   colornames.Lightskyblue,
+			Outline: colornames.Black,
+		})
+		y -= defaultFontSize * 1.5
+	}
+}
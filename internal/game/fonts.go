@@ -0,0 +1,44 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/zrcoder/icer/internal/i18n"
+	"github.com/zrcoder/icer/internal/rendering"
+	"golang.org/x/text/language"
+)
+
+var (
+	// fontSet is the chain of fonts every face is made from. It starts as
+	// the bundled font alone, until setupFonts adds the rest.
+	fontSet = rendering.NewFontSet()
+	// userFont is a font file to draw text with before any other
+	userFont string
+)
+
+// SetFont makes text draw with the font file at path, falling back on the
+// bundled and system fonts for characters it lacks. It must be called
+// before the game is created.
+func SetFont(path string) {
+	userFont = path
+}
+
+// setupFonts loads the full font chain and remakes every face from it,
+// shaped for the locale in use and laid out right to left when the locale
+// is written that way. The menu widgets keep laying their text out left
+// to right, as they place it themselves, though runs in right to left
+// scripts are still shaped and ordered that way.
+func setupFonts() {
+	fontSet = rendering.LoadFontSet(userFont)
+	tag, err := language.Parse(i18n.Current())
+	if err != nil {
+		tag = language.Und
+	}
+	dir := text.DirectionLeftToRight
+	if i18n.RTL() {
+		dir = text.DirectionRightToLeft
+	}
+	defaultFace = fontSet.Face(defaultFontSize, dir, tag)
+	widgetFace = fontSet.Face(defaultFontSize, text.DirectionLeftToRight, tag)
+	standardHUD.face = defaultFace
+	largeHUD.face = fontSet.Face(defaultFontSize*2, dir, tag)
+}
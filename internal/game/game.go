@@ -1,23 +1,123 @@
 package game
 
 import (
+	"context"
+	"image/color"
+	"math/rand/v2"
+
+	"github.com/charmbracelet/log"
 	"github.com/ebitenui/ebitenui"
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/zrcoder/icer/internal/ai"
+	"github.com/zrcoder/icer/internal/clipboard"
+	"github.com/zrcoder/icer/internal/events"
+	"github.com/zrcoder/icer/internal/input"
 	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/links"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/rules"
 	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
 )
 
 // Game represents the main game state and implements ebiten.Game
 type Game struct {
 	state          State
 	player         *sprites.Player
-	objects        []sprites.Sprite
+	players        []*sprites.Player
+	engine         *physics.PhysicsEngine
+	renderer       *rendering.GameRenderer
+	rules          *rules.GameRulesSystem
+	enemies        *ai.EnemySystem
+	pending        []physics.Move
+	history        []snapshot
+	warning        string
 	levelsManager  *levels.Manager
 	selectUI       ebitenui.UI
 	sceneUI        ebitenui.UI
+	loseUI         ebitenui.UI
 	titleContainer *widget.Container
+	celebration    *celebration
+	defeat         *defeat
+	challenge      *challenge
+	retryButton    *widget.Button
+	undoButton     *widget.Button
+	loseReason     *widget.Label
+	randomize      bool
+	seed           uint64
+	campaign       campaignMap
+	dialog         *dialog
+	journal        *journal
+	input          *input.Manager
+	diagnostics    diagnostics
+	layout         *layout
+	elapsed        int
+	shareStatus    string
+	replay         []turn
+	paste          <-chan clipboard.Result
+	link           *links.Link
+	kiosk          *kiosk
+	classroom      *classroom
+	// practice is on while the player may move blocks by hand, and
+	// practiced marks an attempt that has used it
+	practice  bool
+	practiced bool
+	held      sprites.Sprite
+	// view mirrors or turns the board on screen, for left-handed layouts
+	// and upright monitors
+	view     rendering.View
+	hud      *hudTheme
+	ambience *ambience
+	// captions show sound cues on screen when captionsOn, and nearEnemies
+	// marks the enemies already heard drawing near
+	captionsOn  bool
+	captions    []*caption
+	nearEnemies map[*sprites.Enemy]bool
+	// timers run the level's timed actions, such as taking captions down;
+	// they stand still while a dialog is open
+	timers utils.Scheduler
+	// problems are why the level picked can't be played
+	problems []string
+	// notice is shown on the menu until a level starts
+	notice string
+	// clockTheme shades the backgrounds by the time of day
+	clockTheme bool
+	// events are the seasonal events running, snow falls on the menus
+	// during a snowy one, and dailyBoard colors the board of a daily
+	// puzzle opened during an event giving it one
+	events     []events.Event
+	snow       []*snowflake
+	dailyBoard color.Color
+	// randomBoard colors the board of a randomized level, picked by its
+	// seed
+	randomBoard color.Color
+	// levelButtons are the select UI's buttons for the current section's
+	// levels
+	levelButtons []*widget.Button
+	// solving runs the solver for hints, ratings and randomized levels
+	solving *solverPool
+	// rating counts the levels being rated in the background, and rated
+	// is set once one has been, until the level buttons show it
+	rating int
+	rated  bool
+	// shuffle cancels randomizing the level about to start, or finding the
+	// weekly remix, while the solver checks the candidates
+	shuffle context.CancelFunc
+	// remix is the week of the remix challenge being played, or "" for any
+	// other level, and remixChanges says what it changed in which level
+	remix        string
+	remixChanges string
+	// hint cancels the hint asked for, while the solver looks for it, and
+	// hints counts the hints shown during the attempt
+	hint  context.CancelFunc
+	hints int
+	// shownHint is the last hint shown, kept to explain in full if asked
+	// before the next move
+	shownHint *hint
 }
 
 // State represents the current state of the game
@@ -28,6 +128,10 @@ const (
 	StatePlaying
 	StateWin
 	StateLose
+	StateMap
+	StateJournal
+	// StateBroken explains why a level can't be played
+	StateBroken
 )
 
 const (
@@ -38,31 +142,80 @@ const (
 	// Grid settings
 	GridWidth  = 20
 	GridHeight = 15
-	CellSize   = 40
+	CellSize   = utils.CellSize
+
+	// lowTimeTicks is when the countdown of a timed level turns red
+	lowTimeTicks = 10 * ebiten.DefaultTPS
+	// lowMoves is when the moves left on a level with a budget turn red
+	lowMoves = 3
 )
 
 // NewGame creates a new game instance
 func NewGame() *Game {
 	ebiten.SetWindowSize(WindowWidth, WindowHeight)
 	ebiten.SetWindowTitle("ICER - Ice Block Puzzle Game")
+	ensureAudio()
+	setupFonts()
 
 	g := &Game{
 		state:         StateSelect,
 		levelsManager: levels.NewManager(),
+		journal:       newJournal(),
+		input:         input.NewManager(WindowWidth, WindowHeight),
+		hud:           &standardHUD,
+		ambience:      newAmbience(),
+		solving:       newSolverPool(),
 	}
+	g.challenge = newChallenge(g.levelsManager)
+	g.notice = g.levelsManager.Notice()
+	g.SetEvents(true)
 	g.initUI()
 	return g
 }
 
 // Update updates the game logic
 func (g *Game) Update() error {
+	g.diagnostics.BeginTick()
+	g.input.Update()
+	defer g.diagnostics.EndTick(g.input.Active())
+	g.ambience.Update()
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		g.warning = playTestTone()
+		log.Info(g.warning)
+	}
+	if g.kiosk != nil {
+		if g.kiosk.Update() {
+			return ebiten.Termination
+		}
+		if g.kiosk.asking {
+			return nil
+		}
+	}
+	if g.link != nil {
+		g.openLink()
+	}
+	if g.levelsManager.Reload() {
+		g.reloadLevel()
+	}
+	g.solving.Update()
+	g.updateRatings()
+	g.updateSky()
+	g.updateSnow()
 	switch g.state {
 	case StateSelect:
 		g.updateSelect()
 	case StatePlaying:
 		g.updateGame()
-	case StateWin, StateLose:
+	case StateWin:
 		g.updateGameOver()
+	case StateLose:
+		g.updateLose()
+	case StateMap:
+		g.updateMap()
+	case StateJournal:
+		g.updateJournal()
+	case StateBroken:
+		g.updateBroken()
 	}
 	return nil
 }
@@ -79,42 +232,325 @@ func (g *Game) updateSelect() {
 
 // updateGame handles main game state updates
 func (g *Game) updateGame() {
+	if g.dialog != nil {
+		if g.dialog.Update(g.input) {
+			g.dialog = nil
+		}
+		return
+	}
 	g.sceneUI.Update()
-	if ebiten.IsKeyPressed(ebiten.KeySpace) {
-		g.state = StateSelect
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || g.input.JustPressed(input.ActionBack) {
+		g.setState(StateSelect)
+		return
+	}
+	g.updatePaste()
+	g.updatePractice()
+	g.updateView()
+	g.updateHUD()
+	g.timers.Update()
+	g.updateCues()
+	g.elapsed++
+	g.renderer.Update()
+	g.rules.Update()
+	if g.rules.TimedOut() {
+		g.setState(StateLose)
+		return
+	}
+	if len(g.players) > 0 {
+		targets := make([]utils.Cell, len(g.players))
+		for i, player := range g.players {
+			targets[i] = player.Position()
+		}
+		for _, move := range g.enemies.Update(targets) {
+			g.renderer.Glide(move)
+		}
+		if g.rules.Caught() {
+			g.setState(StateLose)
+			return
+		}
+	}
+	if !g.renderer.Busy() {
+		if len(g.pending) > 0 {
+			g.settleMoves()
+			return
+		}
+		if g.dropObjects() {
+			return
+		}
+	}
+	g.updatePlayer()
+}
+
+// updateView mirrors the board with F6 and turns it a quarter clockwise
+// with F7
+func (g *Game) updateView() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyF6):
+		g.view.Mirror = !g.view.Mirror
+	case inpututil.IsKeyJustPressed(ebiten.KeyF7):
+		g.view.Turns = (g.view.Turns + 1) % 4
+	default:
+		return
+	}
+	g.renderer.SetView(g.view)
+	g.warning = "View " + g.view.String()
+}
+
+// openDialog pauses the level and shows an NPC's dialog until the player
+// reads through it; a picked choice raises its flag on the current level
+func (g *Game) openDialog(npc levels.NPC) {
+	if len(npc.Pages) == 0 {
+		return
 	}
-	// x := g.player.Position().X
-	// if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyJ) {
-	// 	if x > 0 {
-	// 		g.player.MoveLeft()
-	// 	}
-	// }
-	// if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyL) {
-	// 	if x < GridWidth-1 {
-	// 		g.player.MoveRight()
-	// 	}
-	// }
+	g.dialog = newDialog(npc, func(flag string) {
+		if flag != "" {
+			g.levelsManager.CurrentLevel().SetFlag(flag)
+		}
+	})
 }
 
 // updateGameOver handles game over state updates
 func (g *Game) updateGameOver() {
+	if g.celebration != nil {
+		g.celebration.Update()
+	}
+	if g.kiosk == nil {
+		g.updateShare()
+	}
 	if ebiten.IsKeyPressed(ebiten.KeySpace) {
-		g.state = StateSelect
+		g.setState(StateSelect)
 		// Reset game state here
 	}
 }
 
+// updateShare copies or saves the result as the player asks
+func (g *Game) updateShare() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.copyResult()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.saveResultCard()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.copySolution()
+	}
+}
+
+// copyResult puts the shareable summary of the win on the clipboard
+func (g *Game) copyResult() {
+	if err := clipboard.Write(g.shareText()); err != nil {
+		log.Warn("cannot copy result", "err", err)
+		g.shareStatus = "Could not reach the clipboard"
+		return
+	}
+	g.shareStatus = "Result copied!"
+}
+
+// copySolution puts the moves that won the level on the clipboard
+func (g *Game) copySolution() {
+	if err := clipboard.Write(g.solution()); err != nil {
+		log.Warn("cannot copy solution", "err", err)
+		g.shareStatus = "Solution copied for pasting in this game only"
+		return
+	}
+	g.shareStatus = "Solution copied!"
+}
+
+// saveResultCard saves the result card as a PNG
+func (g *Game) saveResultCard() {
+	path, err := g.saveShareCard()
+	if err != nil {
+		log.Warn("cannot save result card", "err", err)
+		g.shareStatus = "Could not save the card"
+		return
+	}
+	g.shareStatus = "Card saved to " + path
+}
+
+// updateLose handles the lose sequence and the retry panel
+func (g *Game) updateLose() {
+	g.defeat.Update()
+	if g.defeat.panelVisible {
+		g.loseUI.Update()
+	}
+}
+
+// startLevel begins level i of the current section, sending the player
+// back to the section start once challenge mode has run out of lives. A
+// randomized level begins once the solver has checked it.
+func (g *Game) startLevel(i int) {
+	g.dailyBoard, g.randomBoard = nil, nil
+	g.remix = ""
+	section := g.levelsManager.CurrentSection()
+	if !g.challenge.CanPlay(section) {
+		g.challenge.Reset(section)
+		i = 0
+	}
+	g.levelsManager.SetCurrentLevel(i)
+	if !g.randomize {
+		g.startCurrent()
+		return
+	}
+	g.randomizeLevel(uint64(rand.Uint32()), func(seed uint64, t levels.Transform) {
+		g.seed = seed
+		g.randomBoard = shuffledBoard(seed)
+		log.Debug("level randomized", "seed", g.seed, "transform", t)
+		g.startCurrent()
+	})
+}
+
+// startCurrent plays the current level, showing what stops it if it can't
+func (g *Game) startCurrent() {
+	if err := g.play(); err != nil {
+		log.Error("cannot start level", "err", err)
+		g.showProblems([]string{err.Error()})
+	}
+}
+
+// play starts the current level
+func (g *Game) play() error {
+	g.notice = ""
+	if problems := g.levelsManager.CurrentLevel().Problems(); len(problems) > 0 {
+		g.showProblems(diagnosticLines(problems))
+		return nil
+	}
+	g.journal.Unlock(g.levelsManager.CurrentLevel().SpriteTypes())
+	if err := g.loadLevel(); err != nil {
+		return err
+	}
+	g.setState(StatePlaying)
+	return nil
+}
+
+// reloadLevel restarts the current level after its file changed on disk,
+// if it is being played or was found broken
+func (g *Game) reloadLevel() {
+	if g.state != StatePlaying && g.state != StateBroken {
+		return
+	}
+	if err := g.play(); err != nil {
+		log.Error("cannot reload level", "err", err)
+		g.showProblems([]string{err.Error()})
+		return
+	}
+	g.warning = "Level reloaded"
+}
+
+// canUndoLoss reports whether the move that lost the level can be taken
+// back. Undoing does not give time back, so a timed out level stays lost,
+// and hardcore profiles never undo.
+func (g *Game) canUndoLoss() bool {
+	return len(g.history) > 0 && !g.rules.TimedOut() && !g.levelsManager.Hardcore()
+}
+
+// retryLevel replays the current level from the start
+func (g *Game) retryLevel() {
+	if g.remix != "" {
+		g.startCurrent()
+		return
+	}
+	g.startLevel(g.levelsManager.CurrentLevel().ID)
+}
+
+// setState switches the game state and starts the effects tied to it
+func (g *Game) setState(s State) {
+	g.state = s
+	g.stopShuffle()
+	g.celebration = nil
+	g.defeat = nil
+	g.dialog = nil
+	g.shareStatus = ""
+	if s == StateSelect {
+		// progress may have unlocked levels
+		g.createSelectUI()
+	}
+	switch s {
+	case StateSelect, StateMap, StateJournal, StateBroken:
+		g.ambience.Play("")
+	case StateWin:
+		stars := g.stars()
+		// remixes are played for their own sake, not the original's progress
+		if !g.practiced && g.remix == "" {
+			g.recordAttempt(true)
+			g.levelsManager.CompleteCurrentLevel(stars)
+			g.challenge.Refill(g.levelsManager.CurrentSection(), stars)
+		}
+		_, coins := g.rules.Coins()
+		g.celebration = newCelebration(stars, coins > 0, stars*scorePerStar)
+	case StateLose:
+		section := g.levelsManager.CurrentSection()
+		if !g.practiced && g.remix == "" {
+			g.recordAttempt(false)
+			g.challenge.LoseLife(section)
+		}
+		if g.challenge.CanPlay(section) {
+			g.retryButton.SetText("Retry")
+		} else {
+			g.retryButton.SetText("Restart Section")
+		}
+		undo := g.canUndoLoss()
+		g.undoButton.GetWidget().Disabled = !undo
+		g.undoButton.Focus(undo)
+		g.retryButton.Focus(!undo)
+		switch {
+		case g.rules.Fallen() && g.inWater():
+			g.loseReason.Label = "You fell into the water."
+		case g.rules.Fallen():
+			g.loseReason.Label = "You fell through the floor."
+		case g.rules.TimedOut():
+			g.loseReason.Label = "Time ran out."
+		case g.rules.OutOfMoves():
+			g.loseReason.Label = "You ran out of moves."
+		case g.rules.Caught():
+			g.loseReason.Label = "An enemy caught you."
+		default:
+			g.loseReason.Label = "The ice left can't reach every flame."
+		}
+		g.defeat = newDefeat()
+	}
+}
+
 // drawGame draws the main game
 func (g *Game) drawGame(screen *ebiten.Image) {
-	// TODO
+	g.renderer.Draw(screen)
+	g.drawCaptions(screen)
+	g.drawHUD(screen)
 }
 
 // drawWin draws the win screen
 func (g *Game) drawWin(screen *ebiten.Image) {
-	ebitenutil.DebugPrint(screen, "YOU WIN!\nPress SPACE to continue")
+	if g.celebration != nil {
+		g.celebration.Draw(screen)
+	}
+	msg := "YOU WIN!\n" + g.movesLabel()
+	if label, ok := g.coinsLabel(); ok {
+		msg += "\n" + label
+		if g.rules.AllCoins() {
+			msg += ", bonus star!"
+		}
+	}
+	if g.levelsManager.Hardcore() {
+		msg += "\nHardcore: no undo, no hints"
+	}
+	msg += "\nPress SPACE to continue"
+	if g.practiced {
+		msg = "PRACTICE CLEAR!\nPractice runs don't count towards progress\nPress SPACE to continue"
+	}
+	if g.kiosk == nil {
+		msg += "\nPress C to copy your result, S your solution\nPress P to save a card"
+	}
+	if g.shareStatus != "" {
+		msg += "\n" + g.shareStatus
+	}
+	rendering.DrawText(screen, msg, defaultFace, 20, 20, rendering.TextStyle{
+		Outline: colornames.Black,
+	})
 }
 
 // drawLose draws the lose screen
 func (g *Game) drawLose(screen *ebiten.Image) {
-	ebitenutil.DebugPrint(screen, "GAME OVER\nPress SPACE to continue")
+	g.defeat.Draw(screen)
+	if g.defeat.panelVisible {
+		g.loseUI.Draw(screen)
+	}
 }
@@ -0,0 +1,13 @@
+package game
+
+import "github.com/charmbracelet/log"
+
+// SetHardcore makes the player's profile hardcore for good: undo and hints
+// are off, and wins are marked as hardcore on the win screen and in shared
+// results
+func (g *Game) SetHardcore() {
+	if !g.levelsManager.Hardcore() {
+		log.Info("profile made hardcore")
+	}
+	g.levelsManager.SetHardcore()
+}
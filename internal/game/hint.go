@@ -0,0 +1,115 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/solver"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// hint is the solution found for board, the position a hint was asked for
+type hint struct {
+	board *physics.Board
+	moves []utils.Direction
+	err   error
+}
+
+// showHint starts solving the level from where it stands on the solver
+// pool, as solving it can take a while; showSolution marks the block to
+// push next once the solver is done. Asked again before a move, it
+// explains the whole solution instead.
+func (g *Game) showHint() {
+	if g.hint != nil {
+		return
+	}
+	board, ok := g.engine.Board()
+	if ok && g.shownHint != nil && board.Equal(g.shownHint.board) {
+		g.explain(*g.shownHint)
+		return
+	}
+	if g.levelsManager.Hardcore() {
+		g.warning = "No hints in hardcore mode"
+		return
+	}
+	if !ok || !solver.Supports(g.levelsManager.CurrentSection()) {
+		g.warning = "No hints for this level"
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.hint = cancel
+	g.warning = "Thinking..."
+	g.solving.Go(ctx, func(ctx context.Context) func() {
+		moves, err := solver.Solve(ctx, board.Clone(), solver.MaxStates)
+		return func() {
+			g.hint = nil
+			g.showSolution(hint{board: board, moves: moves, err: err})
+		}
+	})
+}
+
+// dropHint takes down the hint shown, and stops looking for one asked for,
+// once the level moves on from the position it was asked for
+func (g *Game) dropHint() {
+	if g.hint != nil {
+		g.hint()
+		g.hint = nil
+	}
+	g.renderer.ClearHint()
+	g.shownHint = nil
+}
+
+// showSolution shows the hint found in h
+func (g *Game) showSolution(h hint) {
+	switch {
+	case errors.Is(h.err, solver.ErrUnsolvable):
+		g.warning = "There's no way to win from here. " + g.input.Prompt(input.ActionUndo, "undo")
+		return
+	case h.err != nil:
+		log.Debug("no hint", "err", h.err)
+		g.warning = "This one is too tricky for a hint"
+		return
+	}
+	block, dir, ok := solver.NextPush(h.board, h.moves)
+	if !ok {
+		g.warning = ""
+		return
+	}
+	g.renderer.ShowHint(block, dir)
+	g.hints++
+	g.shownHint = &h
+	g.warning = fmt.Sprintf("Push the marked block, %d moves to go. %s", len(h.moves), g.input.Prompt(input.ActionHint, "explain"))
+}
+
+// explain shows every push of the solution h as numbered arrows, blocks
+// putting out a flame told apart from ones moved out of the way or into
+// place, and says which pushes have to wait for others
+func (g *Game) explain(h hint) {
+	pushes := solver.Explain(h.board, h.moves)
+	arrows := make([]rendering.Arrow, len(pushes))
+	var waits []string
+	for i, p := range pushes {
+		arrows[i] = rendering.Arrow{From: p.From, To: p.To, Flame: p.Flame}
+		if len(p.After) == 0 {
+			continue
+		}
+		after := make([]string, len(p.After))
+		for j, a := range p.After {
+			after[j] = strconv.Itoa(a + 1)
+		}
+		waits = append(waits, fmt.Sprintf("%d after %s", i+1, strings.Join(after, ", ")))
+	}
+	g.renderer.ShowPlan(arrows, defaultFace)
+	g.shownHint = nil
+	g.warning = fmt.Sprintf("%d pushes, in order", len(pushes))
+	if len(waits) > 0 {
+		g.warning += "; " + strings.Join(waits, "; ")
+	}
+}
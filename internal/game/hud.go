@@ -0,0 +1,148 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+	"golang.org/x/text/language"
+)
+
+// hudInset keeps the HUD clear of the window's sides, and hudTop and
+// hudBottom of its top and bottom
+const (
+	hudInset  = 20
+	hudTop    = 10
+	hudBottom = 6
+)
+
+// hudTheme is how the HUD over a level is drawn
+type hudTheme struct {
+	face text.Face
+	// text is the usual color, warn marks a running out countdown and
+	// good something complete
+	text, warn, good color.Color
+	shadow           color.Color
+	outline          color.Color
+	// simple leaves out all but what is needed to play: par, coins and
+	// hints are hidden
+	simple bool
+}
+
+var (
+	standardHUD = hudTheme{
+		face:   defaultFace,
+		text:   colornames.Gainsboro,
+		warn:   colornames.Orangered,
+		good:   colornames.Gold,
+		shadow: colornames.Black,
+	}
+	// largeHUD doubles the type and draws it in high contrast for players
+	// who find the standard HUD hard to read. It leaves the board alone,
+	// so it works with any view.
+	largeHUD = hudTheme{
+		face:    fontSet.Face(defaultFontSize*2, text.DirectionLeftToRight, language.Und),
+		text:    colornames.White,
+		warn:    colornames.Yellow,
+		good:    colornames.Yellow,
+		outline: colornames.Black,
+		simple:  true,
+	}
+)
+
+// SetLargeHUD switches between the large-text and standard HUD
+func (g *Game) SetLargeHUD(on bool) {
+	g.hud = &standardHUD
+	if on {
+		g.hud = &largeHUD
+	}
+}
+
+// updateHUD toggles the large-text HUD with F8
+func (g *Game) updateHUD() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
+		g.SetLargeHUD(g.hud != &largeHUD)
+	}
+}
+
+// lineHeight is the distance between two lines of HUD text
+func (t *hudTheme) lineHeight() float64 {
+	m := t.face.Metrics()
+	return m.HAscent + m.HDescent + m.HLineGap
+}
+
+// draw writes s in the theme with its top edge at y, aligned on x
+func (t *hudTheme) draw(screen *ebiten.Image, s string, x, y float64, align text.Align, c color.Color) {
+	rendering.DrawText(screen, s, t.face, x, y, rendering.TextStyle{
+		Color:   c,
+		Align:   align,
+		Shadow:  t.shadow,
+		Outline: t.outline,
+	})
+}
+
+// drawHUD shows the state of the level being played: the moves, keys and
+// coins on the right, and any countdowns on the left
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	t := g.hud
+	right := float64(WindowWidth - hudInset)
+	y := float64(hudTop)
+	moves := g.movesLabel()
+	if t.simple {
+		moves = fmt.Sprintf("Moves %d", g.rules.MovesTaken())
+	}
+	t.draw(screen, moves, mirrorX(right), y, text.AlignEnd, t.text)
+	y += t.lineHeight()
+	if keys := g.rules.Keys(); len(keys) > 0 {
+		for i, key := range keys {
+			x := mirrorSpan(right-float64((i+1)*CellSize), CellSize)
+			sprites.DrawKeyIcon(screen, utils.Pixel{X: x, Y: y}, key)
+		}
+		y += CellSize
+	}
+	if label, ok := g.coinsLabel(); ok && !t.simple {
+		c := t.text
+		if g.rules.AllCoins() {
+			c = t.good
+		}
+		t.draw(screen, label, mirrorX(right), y, text.AlignEnd, c)
+	}
+
+	y = hudTop
+	if label, ok := g.timeLabel(); ok {
+		c := t.text
+		if ticks, _ := g.rules.TimeLeft(); ticks < lowTimeTicks {
+			c = t.warn
+		}
+		t.draw(screen, label, mirrorX(hudInset), y, text.AlignStart, c)
+		y += t.lineHeight()
+	}
+	if label, ok := g.budgetLabel(); ok {
+		c := t.text
+		if left, _ := g.rules.MovesLeft(); left <= lowMoves {
+			c = t.warn
+		}
+		t.draw(screen, label, mirrorX(hudInset), y, text.AlignStart, c)
+	}
+
+	bottom := float64(WindowHeight - hudBottom)
+	if len(g.players) > 1 && g.state == StatePlaying {
+		bottom -= t.lineHeight()
+		t.draw(screen, g.input.Prompt(input.ActionSwitch, "switch characters"), mirrorX(hudInset), bottom, text.AlignStart, t.text)
+	}
+	if g.warning != "" {
+		bottom -= t.lineHeight()
+		t.draw(screen, g.warning, WindowWidth/2, bottom, text.AlignCenter, t.text)
+	}
+	if g.practice && g.state == StatePlaying && !t.simple {
+		bottom -= t.lineHeight()
+		t.draw(screen, "Practice: click a block to pick it up, click again to drop it. F2 to stop", WindowWidth/2, bottom, text.AlignCenter, t.text)
+	}
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	journalRowHeight = 54
+	journalPerPage   = 9
+)
+
+// journalEntry describes one mechanic and how to build its demo sprite
+type journalEntry struct {
+	kind        string
+	title       string
+	description string
+	demo        func(x, y int) sprites.Sprite
+}
+
+var journalEntries = []journalEntry{
+	{"player", "You", "Walk into ice to push it. Undo takes back a move gone wrong.", func(x, y int) sprites.Sprite { return sprites.NewPlayer(x, y) }},
+	{"ice", "Ice", "Push ice blocks to slide them across the floor.", func(x, y int) sprites.Sprite { return sprites.NewIce(x, y) }},
+	{"flame", "Flame", "Put out every flame to clear the level.", func(x, y int) sprites.Sprite { return sprites.NewFlame(x, y) }},
+	{"wall", "Wall", "Walls stop both you and sliding blocks.", func(x, y int) sprites.Sprite { return sprites.NewWall(x, y) }},
+	{"stone", "Stone", "Stones move one cell per push and never melt.", func(x, y int) sprites.Sprite { return sprites.NewStone(x, y) }},
+	{"bomb", "Bomb", "Push a bomb into a flame to blow up the cracked walls around it.", func(x, y int) sprites.Sprite { return sprites.NewBomb(x, y) }},
+	{"crackedwall", "Cracked Wall", "Cracked walls stand firm until a bomb goes off next to them.", func(x, y int) sprites.Sprite { return sprites.NewCrackedWall(x, y) }},
+	{"portal", "Portal", "Step into a portal to come out of its twin.", func(x, y int) sprites.Sprite { return sprites.NewPortal('A', x, y) }},
+	{"pot", "Pot", "Pots heat up next to flames and melt ice.", func(x, y int) sprites.Sprite { return sprites.NewPot(x, y) }},
+	{"fakewall", "Fake Wall", "Some walls are not what they seem.", func(x, y int) sprites.Sprite { return sprites.NewFakeWall(x, y) }},
+	{"gem", "Gem", "Find every gem in a section to open its bonus levels.", func(x, y int) sprites.Sprite { return sprites.NewGem(x, y) }},
+	{"coin", "Coin", "Collect every coin in a level for a bonus star.", func(x, y int) sprites.Sprite { return sprites.NewCoin(x, y) }},
+	{"conveyor", "Conveyor", "Belts carry whatever rests on them after every move.", func(x, y int) sprites.Sprite { return sprites.NewConveyor(x, y, utils.Right) }},
+	{"oneway", "One-Way Tile", "Arrows let you and your ice cross only the way they point.", func(x, y int) sprites.Sprite { return sprites.NewOneWay(x, y, utils.Right) }},
+	{"fan", "Fan", "Fans blow sliding ice one cell aside as it crosses their draft.", func(x, y int) sprites.Sprite { return sprites.NewFan(x, y, utils.Right) }},
+	{"sticky", "Sticky Floor", "Sliding ice stops dead on sticky floor. Getting off takes two tries.", func(x, y int) sprites.Sprite { return sprites.NewStickyFloor(x, y) }},
+	{"crackedfloor", "Cracked Floor", "Cracked floor gives way once crossed. Ice can fill the hole.", func(x, y int) sprites.Sprite { return sprites.NewCrackedFloor(x, y) }},
+	{"hole", "Hole", "Falling into a hole loses the level. Push ice in to fill it.", func(x, y int) sprites.Sprite { return sprites.NewHole(x, y) }},
+	{"water", "Water", "Don't fall in! Push ice into water to freeze it over.", func(x, y int) sprites.Sprite { return sprites.NewWater(x, y) }},
+	{"frozen", "Frozen Water", "Water frozen over by ice is safe to walk on.", func(x, y int) sprites.Sprite { return sprites.NewFrozenGround(x, y) }},
+	{"plate", "Pressure Plate", "Plates switch toggle walls while something rests on them.", func(x, y int) sprites.Sprite { return sprites.NewPlate(x, y) }},
+	{"togglewall", "Toggle Wall", "Toggle walls open and close as their plates are pressed.", func(x, y int) sprites.Sprite { return sprites.NewToggleWall(x, y, false) }},
+	{"lever", "Lever", "Walk into a lever to switch every phase wall at once.", func(x, y int) sprites.Sprite { return sprites.NewLever(x, y) }},
+	{"phasewall", "Phase Wall", "Phase walls come and go as the levers are flipped.", func(x, y int) sprites.Sprite { return sprites.NewPhaseWall(x, y, false) }},
+	{"key", "Key", "Pick up a key to open the doors of its color.", func(x, y int) sprites.Sprite { return sprites.NewKey(x, y, sprites.KeyRed) }},
+	{"door", "Door", "Doors stay shut until you bring a matching key.", func(x, y int) sprites.Sprite { return sprites.NewDoor(x, y, sprites.KeyRed) }},
+	{"enemy", "Enemy", "Keep away from enemies. Sliding ice crushes them.", func(x, y int) sprites.Sprite { return sprites.NewEnemy(x, y) }},
+	{"npc", "Friend", "Bump into friends to hear what they have to say.", func(x, y int) sprites.Sprite { return sprites.NewNPC(x, y, 0) }},
+}
+
+// journalReveals lists the kinds that only come about in play, unlocked
+// along with the kind they come from
+var journalReveals = map[string][]string{
+	"crackedfloor": {"hole"},
+	"water":        {"frozen"},
+}
+
+// journal records which mechanics the player has met so far
+type journal struct {
+	unlocked map[string]bool
+	tick     int
+	page     int
+	demo     *ebiten.Image
+}
+
+func newJournal() *journal {
+	return &journal{
+		unlocked: make(map[string]bool),
+		demo:     ebiten.NewImage(sprites.SpriteWidth, sprites.SpriteHeight),
+	}
+}
+
+// Unlock opens the entries for the given sprite kinds, and for those they
+// reveal
+func (j *journal) Unlock(kinds []string) {
+	for _, kind := range kinds {
+		if !j.unlocked[kind] {
+			j.unlocked[kind] = true
+			log.Debug("journal entry unlocked", "kind", kind)
+		}
+		j.Unlock(journalReveals[kind])
+	}
+}
+
+func journalPages() int {
+	return (len(journalEntries) + journalPerPage - 1) / journalPerPage
+}
+
+// updateJournal handles journal state updates
+func (g *Game) updateJournal() {
+	g.journal.tick++
+	pages := journalPages()
+	if g.input.JustPressed(input.ActionLeft) {
+		g.journal.page = (g.journal.page + pages - 1) % pages
+	}
+	if g.input.JustPressed(input.ActionRight) {
+		g.journal.page = (g.journal.page + 1) % pages
+	}
+	if g.input.JustPressed(input.ActionBack) {
+		g.setState(StateSelect)
+	}
+}
+
+// drawJournal lists the entries of the current page, showing an animated demo sprite for the
+// unlocked ones and a placeholder for the rest
+func (g *Game) drawJournal(screen *ebiten.Image) {
+	drawCentered(screen, "Journal", WindowWidth/2, 20)
+	demo := g.journal.demo
+	first := g.journal.page * journalPerPage
+	entries := journalEntries[first:min(first+journalPerPage, len(journalEntries))]
+	for i, entry := range entries {
+		y := 70 + i*journalRowHeight
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(140, float64(y))
+		if !g.journal.unlocked[entry.kind] {
+			op.ColorScale.ScaleWithColor(colornames.Dimgray)
+			text.Draw(screen, "???", defaultFace, op)
+			continue
+		}
+		demo.Clear()
+		entry.demo(0, 0).Draw(demo)
+		bob := math.Sin(float64(g.journal.tick)/10+float64(i)) * 4
+		dop := &ebiten.DrawImageOptions{}
+		dop.GeoM.Translate(70, float64(y)+bob)
+		screen.DrawImage(demo, dop)
+
+		op.ColorScale.ScaleWithColor(colornames.Orange)
+		text.Draw(screen, entry.title, defaultFace, op)
+		op = &text.DrawOptions{}
+		op.GeoM.Translate(140, float64(y)+24)
+		op.ColorScale.ScaleWithColor(colornames.Gainsboro)
+		text.Draw(screen, entry.description, defaultFace, op)
+	}
+	prompt := g.input.Prompt(input.ActionBack, "go back")
+	if pages := journalPages(); pages > 1 {
+		prompt = fmt.Sprintf("%s/%s: page %d/%d    %s",
+			g.input.Glyph(input.ActionLeft), g.input.Glyph(input.ActionRight), g.journal.page+1, pages, prompt)
+	}
+	drawCentered(screen, prompt, WindowWidth/2, WindowHeight-30)
+}
@@ -0,0 +1,40 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/zrcoder/icer/internal/levels"
+)
+
+func TestJournalCoversBuiltinSprites(t *testing.T) {
+	entries := make(map[string]bool)
+	for _, entry := range journalEntries {
+		if entries[entry.kind] {
+			t.Errorf("two journal entries for %q", entry.kind)
+		}
+		entries[entry.kind] = true
+		if got := entry.demo(0, 0).Type(); got != entry.kind {
+			t.Errorf("journal entry %q shows a %q", entry.kind, got)
+		}
+	}
+	for kind, revealed := range journalReveals {
+		for _, r := range append(revealed, kind) {
+			if !entries[r] {
+				t.Errorf("journal reveals %q, which has no entry", r)
+			}
+		}
+	}
+	// a grid of every printable character places every built-in sprite,
+	// characters outside the default legend making portals
+	var grid []rune
+	for r := '!'; r <= '~'; r++ {
+		grid = append(grid, r)
+	}
+	grid = append(grid, []rune("↑↓←→⇑⇓⇐⇒")...)
+	level := &levels.Level{Grid: string(grid)}
+	for _, kind := range level.SpriteTypes() {
+		if !entries[kind] {
+			t.Errorf("no journal entry for %q", kind)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/profile"
+	"golang.org/x/image/colornames"
+)
+
+// KioskConfig is read from kiosk.toml in the profile directory
+type KioskConfig struct {
+	// Enabled turns kiosk mode on without the -kiosk flag
+	Enabled bool `toml:"enabled"`
+	// Passcode must be typed to quit
+	Passcode string `toml:"passcode"`
+}
+
+// kioskMigrations upgrade kiosk.toml files written for older versions of
+// the game. The format hasn't changed yet.
+var kioskMigrations []profile.Migration
+
+// LoadKioskConfig reads the kiosk settings, if there are any. When they
+// can't be read, the defaults are returned along with the error.
+func LoadKioskConfig() (KioskConfig, error) {
+	var c KioskConfig
+	if _, err := profile.DecodeFile("kiosk.toml", kioskMigrations, &c); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return KioskConfig{}, err
+	}
+	return c, nil
+}
+
+// kiosk locks the game down for unattended machines in classrooms and
+// museums: it runs full screen, quits only once the passcode is typed, and
+// leaves out whatever reaches beyond the game, such as the clipboard,
+// saved cards and links
+type kiosk struct {
+	passcode string
+	entry    []rune
+	asking   bool
+	wrong    bool
+}
+
+// SetKiosk locks the game down, to be quit only with passcode
+func (g *Game) SetKiosk(passcode string) {
+	g.kiosk = &kiosk{passcode: passcode}
+	ebiten.SetFullscreen(true)
+	ebiten.SetWindowClosingHandled(true)
+}
+
+// Update asks for the passcode when someone tries to close the game, or
+// presses Ctrl+Shift+Q where full screen hides the window's controls, and
+// reports whether the right one was typed
+func (k *kiosk) Update() bool {
+	if !k.asking {
+		k.asking = ebiten.IsWindowBeingClosed() ||
+			inpututil.IsKeyJustPressed(ebiten.KeyQ) && ebiten.IsKeyPressed(ebiten.KeyControl) && ebiten.IsKeyPressed(ebiten.KeyShift)
+		k.entry, k.wrong = k.entry[:0], false
+		return false
+	}
+	k.entry = ebiten.AppendInputChars(k.entry)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(k.entry) > 0:
+		k.entry = k.entry[:len(k.entry)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		k.asking = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if string(k.entry) == k.passcode {
+			return true
+		}
+		k.entry, k.wrong = k.entry[:0], true
+	}
+	return false
+}
+
+// Draw shows the passcode prompt, masking what has been typed
+func (k *kiosk) Draw(screen *ebiten.Image) {
+	if !k.asking {
+		return
+	}
+	vector.DrawFilledRect(screen, WindowWidth/4, WindowHeight/2-60, WindowWidth/2, 120, colornames.Black, false)
+	vector.StrokeRect(screen, WindowWidth/4, WindowHeight/2-60, WindowWidth/2, 120, 3, colornames.Gainsboro, false)
+	msg := "Enter the passcode to quit"
+	if k.wrong {
+		msg = "Wrong passcode, try again"
+	}
+	drawCentered(screen, msg, WindowWidth/2, WindowHeight/2-40)
+	drawCentered(screen, strings.Repeat("*", len(k.entry))+"_", WindowWidth/2, WindowHeight/2-10)
+	drawCentered(screen, "Esc to keep playing", WindowWidth/2, WindowHeight/2+20)
+}
@@ -0,0 +1,40 @@
+package game
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/links"
+)
+
+// Open queues the puzzle a deep link points at, to start on the next update
+func (g *Game) Open(link links.Link) {
+	if g.kiosk != nil {
+		log.Warn("links are off in kiosk mode", "link", link)
+		return
+	}
+	g.link = &link
+}
+
+// openLink starts the queued link's puzzle: a level by its code, as is,
+// or the daily puzzle of a date, transformed by a seed taken from it
+func (g *Game) openLink() {
+	link := *g.link
+	g.link = nil
+	g.dailyBoard, g.randomBoard = nil, nil
+	g.remix = ""
+	switch link.Kind {
+	case links.KindDaily:
+		g.dailyBoard = g.eventDailyBoard()
+		g.randomizeLevel(g.levelsManager.SelectDaily(link.Date), func(seed uint64, t levels.Transform) {
+			g.seed = seed
+			log.Debug("daily puzzle", "date", link.Date.Format(links.DateLayout), "level", g.levelsManager.Code(), "transform", t)
+			g.startCurrent()
+		})
+	default:
+		if err := g.levelsManager.SelectCode(link.Code); err != nil {
+			log.Error("cannot open link", "link", link, "err", err)
+			return
+		}
+		g.startCurrent()
+	}
+}
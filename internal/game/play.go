@@ -0,0 +1,365 @@
+package game
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/zrcoder/icer/internal/ai"
+	"github.com/zrcoder/icer/internal/input"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/rules"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// loadLevel builds the current level's sprites, physics, rules and renderer
+func (g *Game) loadLevel() error {
+	level := g.levelsManager.CurrentLevel()
+	objects, width, height, err := level.Build()
+	if err != nil {
+		return err
+	}
+	if g.layout != nil {
+		g.layout.preview.Deallocate()
+	}
+	g.layout = newLayout(objects, width, height)
+	g.ambience.Play(cmp.Or(level.Ambience, g.levelsManager.CurrentSection().Ambience))
+	g.elapsed = 0
+	g.engine = physics.NewPhysicsEngine(width, height, objects)
+	g.engine.SetPortals(physics.NewPortalSystem(level.Portals()))
+	g.engine.SetGravity(level.Gravity)
+	section := g.levelsManager.CurrentSection()
+	g.engine.SetChains(section.Variant(levels.VariantChainPush))
+	g.engine.SetSlippery(section.Variant(levels.VariantPlayerSlides))
+	momentum, ok := physics.ParseMomentum(level.PortalExit)
+	if !ok {
+		return fmt.Errorf("unknown portal exit %q", level.PortalExit)
+	}
+	g.engine.SetMomentum(momentum)
+	g.engine.Settle()
+	g.rules = rules.NewGameRulesSystem(g.engine, rules.Config{
+		Refreeze:  level.Refreeze,
+		Par:       level.Par,
+		TimeLimit: level.TimeLimit * ebiten.DefaultTPS,
+		MaxMoves:  level.MaxMoves,
+		Switches:  level.Switches(),
+		Gates:     level.Gates(),
+		Flag:      level.Flag,
+		Merge:     section.Variant(levels.VariantMerge),
+		Spread:    section.Variant(levels.VariantFlamesSpread),
+	})
+	g.enemies = ai.NewEnemySystem(g.engine)
+	g.renderer = rendering.NewGameRenderer(g.engine)
+	g.renderer.SetView(g.view)
+	g.pending = nil
+	g.history = nil
+	g.replay = nil
+	g.warning = ""
+	g.player = nil
+	g.players = nil
+	g.held = nil
+	g.captions = nil
+	g.timers.Clear()
+	g.nearEnemies = make(map[*sprites.Enemy]bool)
+	g.practiced = g.practice
+	g.hints = 0
+	g.dropHint()
+	for _, obj := range objects {
+		if player, ok := obj.(*sprites.Player); ok {
+			g.players = append(g.players, player)
+		}
+	}
+	if len(g.players) > 0 {
+		g.control(g.players[0])
+	}
+	return nil
+}
+
+// control hands the moves to player, ringing it when the level has other
+// characters to switch to
+func (g *Game) control(player *sprites.Player) {
+	g.player = player
+	if len(g.players) > 1 {
+		g.renderer.SetFocus(player)
+	}
+}
+
+// switchPlayer hands control to the next character
+func (g *Game) switchPlayer() {
+	i := slices.Index(g.players, g.player)
+	g.control(g.players[(i+1)%len(g.players)])
+}
+
+// updatePlayer moves the player one cell per press in any of the four
+// directions, pushing ice and bumping into friends, takes back a move or
+// the whole attempt, or switches between characters
+func (g *Game) updatePlayer() {
+	if g.input.JustPressed(input.ActionRestart) {
+		g.RestartLevel()
+		return
+	}
+	if g.player == nil || g.renderer.Busy() {
+		return
+	}
+	if g.input.JustPressed(input.ActionUndo) {
+		g.Undo()
+		g.warning = ""
+		if g.levelsManager.Hardcore() {
+			g.warning = "No undo in hardcore mode"
+		}
+		return
+	}
+	if len(g.replay) > 0 {
+		t := g.replay[0]
+		g.replay = g.replay[1:]
+		g.control(g.players[t.player])
+		g.movePlayer(t.dir)
+		return
+	}
+	if g.input.JustPressed(input.ActionSwitch) {
+		g.switchPlayer()
+		return
+	}
+	if g.input.JustPressed(input.ActionHint) {
+		g.showHint()
+		return
+	}
+	if dir, ok := g.input.Direction(); ok {
+		// the player presses the way they see, which the view may have
+		// turned away from the level's
+		g.movePlayer(g.view.Unapply(dir))
+	}
+}
+
+func (g *Game) movePlayer(dir utils.Direction) {
+	level := g.levelsManager.CurrentLevel()
+	for _, obj := range g.engine.ObjectsAt(g.player.Position().Step(dir)) {
+		if npc, ok := obj.(*sprites.NPC); ok {
+			g.openDialog(level.Dialog(npc))
+			return
+		}
+	}
+	snapshot := g.snapshot(dir)
+	for _, obj := range g.engine.ObjectsAt(g.player.Position().Step(dir)) {
+		switch obj := obj.(type) {
+		case *sprites.Door:
+			g.rules.Unlock(obj)
+		case *sprites.Lever:
+			// flipping a lever takes a move of its own
+			g.countMove(snapshot)
+			g.rules.FlipLevers()
+			g.checkOutcome()
+			return
+		}
+	}
+	moves := g.engine.MovePlayer(g.player, dir)
+	if len(moves) > 0 {
+		g.countMove(snapshot)
+	}
+	for _, move := range moves {
+		g.renderer.Animate(move)
+		g.pending = append(g.pending, move)
+		if _, ok := move.Object.(*sprites.Player); ok {
+			g.enterCell(move.To())
+		}
+	}
+}
+
+// settleMoves applies the rules to the moves whose animations have just
+// finished, so blocks and flames only vanish once the player sees them meet
+func (g *Game) settleMoves() {
+	for _, move := range g.pending {
+		g.rules.ProcessMove(move)
+		if g.rules.Stranded(move.Object) {
+			g.warning = "That push just lost you the level. " + g.input.Prompt(input.ActionUndo, "undo")
+		}
+	}
+	g.pending = nil
+	for _, pos := range g.rules.Explosions() {
+		g.renderer.Explode(pos)
+		g.cue(cueBlast, pos)
+	}
+	for _, pos := range g.rules.Doused() {
+		g.cue(cueHiss, pos)
+	}
+	g.checkOutcome()
+}
+
+// countMove records a move the player made from snapshot, so it can be
+// undone, and takes down what was shown for the position before it
+func (g *Game) countMove(snapshot snapshot) {
+	g.history = append(g.history, snapshot)
+	g.rules.CountMove()
+	g.warning = ""
+	g.dropHint()
+}
+
+// checkOutcome ends the level once a move has won or lost it
+func (g *Game) checkOutcome() {
+	switch {
+	case g.rules.CheckWin():
+		g.setState(StateWin)
+	case g.rules.CheckLose():
+		g.setState(StateLose)
+	}
+}
+
+// inWater reports whether a player stands in water
+func (g *Game) inWater() bool {
+	for _, player := range g.players {
+		for _, obj := range g.engine.ObjectsAt(player.Position()) {
+			if _, ok := obj.(*sprites.Water); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// enterCell applies what the player finds on arriving at pos
+func (g *Game) enterCell(pos utils.Cell) {
+	for _, obj := range g.engine.ObjectsAt(pos) {
+		switch obj := obj.(type) {
+		case *sprites.Gem:
+			g.engine.Remove(obj)
+			if !g.practiced && g.remix == "" {
+				g.levelsManager.CollectGem()
+			}
+		case *sprites.FakeWall:
+			obj.Revealed = true
+			g.levelsManager.DiscoverCell(pos)
+		}
+	}
+}
+
+// dropObjects lets whatever lost its support since the last move fall, such
+// as ice resting on a block that melted or put out a flame, and reports
+// whether anything did
+func (g *Game) dropObjects() bool {
+	moves := g.engine.Settle()
+	for _, move := range moves {
+		g.renderer.Animate(move)
+		g.pending = append(g.pending, move)
+	}
+	return len(moves) > 0
+}
+
+// stars rates a finished level against its par: within par earns every
+// star, and each half par more costs one, down to a single star.
+// Collecting every coin earns a bonus star on top.
+func (g *Game) stars() int {
+	stars := maxStars
+	par := g.rules.Par()
+	if over := g.rules.MovesTaken() - par; par > 0 && over > 0 {
+		stars = max(1, maxStars-(over*2+par-1)/par)
+	}
+	if g.rules.AllCoins() {
+		stars++
+	}
+	return stars
+}
+
+// movesLabel describes the moves taken so far, against par when rated
+func (g *Game) movesLabel() string {
+	if par := g.rules.Par(); par > 0 {
+		return fmt.Sprintf("Moves %d / Par %d", g.rules.MovesTaken(), par)
+	}
+	return fmt.Sprintf("Moves %d", g.rules.MovesTaken())
+}
+
+// timeLabel shows the time left on a timed level as minutes and seconds
+func (g *Game) timeLabel() (string, bool) {
+	ticks, timed := g.rules.TimeLeft()
+	if !timed {
+		return "", false
+	}
+	secs := (ticks + ebiten.DefaultTPS - 1) / ebiten.DefaultTPS
+	return fmt.Sprintf("Time %d:%02d", secs/60, secs%60), true
+}
+
+// coinsLabel shows the coins collected on a level that has any
+func (g *Game) coinsLabel() (string, bool) {
+	collected, total := g.rules.Coins()
+	if total == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("Coins %d/%d", collected, total), true
+}
+
+// budgetLabel shows the moves left on a level with a move budget
+func (g *Game) budgetLabel() (string, bool) {
+	left, limited := g.rules.MovesLeft()
+	if !limited {
+		return "", false
+	}
+	return fmt.Sprintf("Moves left %d", left), true
+}
+
+// RestartLevel rebuilds the level being played from its initial state,
+// keeping its randomizer transform and any secrets already discovered
+func (g *Game) RestartLevel() {
+	g.dialog = nil
+	if err := g.loadLevel(); err != nil {
+		log.Error("cannot restart level", "err", err)
+		return
+	}
+	log.Debug("level restarted", "id", g.levelsManager.CurrentLevel().ID)
+}
+
+// snapshot records the state of the level before a move
+type snapshot struct {
+	engine  physics.Snapshot
+	rules   rules.Snapshot
+	enemies ai.Snapshot
+	dir     utils.Direction // the move taken from this state
+	player  *sprites.Player // the character that took it
+}
+
+func (g *Game) snapshot(dir utils.Direction) snapshot {
+	return snapshot{
+		engine:  g.engine.Snapshot(),
+		rules:   g.rules.Snapshot(),
+		enemies: g.enemies.Snapshot(),
+		dir:     dir,
+		player:  g.player,
+	}
+}
+
+// Undo takes back the last move, and reports whether there was one.
+// Control goes back to the character that made it. Collected gems stay
+// collected. Hardcore profiles can't undo.
+func (g *Game) Undo() bool {
+	if len(g.history) == 0 || g.levelsManager.Hardcore() {
+		return false
+	}
+	last := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	g.dropHint()
+
+	gems := make(map[sprites.Sprite]bool)
+	for _, obj := range g.engine.Objects() {
+		if _, ok := obj.(*sprites.Gem); ok {
+			gems[obj] = true
+		}
+	}
+	g.engine.Restore(last.engine)
+	g.rules.Restore(last.rules)
+	g.enemies.Restore(last.enemies)
+	g.control(last.player)
+	var collected []sprites.Sprite
+	for _, obj := range g.engine.Objects() {
+		if _, ok := obj.(*sprites.Gem); ok && !gems[obj] {
+			collected = append(collected, obj)
+		}
+	}
+	for _, obj := range collected {
+		g.engine.Remove(obj)
+	}
+	g.pending = nil
+	return true
+}
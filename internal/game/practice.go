@@ -0,0 +1,65 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// updatePractice toggles practice mode with F2. While practicing, a click
+// picks up a block and the next click drops it on any free cell, to try
+// out ideas; a level touched by practice doesn't count towards progress.
+func (g *Game) updatePractice() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		g.practice = !g.practice
+		g.practiced = g.practiced || g.practice
+		g.drop()
+	}
+	if !g.practice || g.renderer.Busy() || !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	x, y := ebiten.CursorPosition()
+	cell := g.renderer.CellAt(image.Rect(0, 0, WindowWidth, WindowHeight), utils.Pixel{X: float64(x), Y: float64(y)})
+	if g.held == nil {
+		for _, obj := range g.engine.ObjectsAt(cell) {
+			if movable(obj) {
+				g.held = obj
+				g.renderer.SetFocus(obj)
+				return
+			}
+		}
+		return
+	}
+	from := g.held.Position()
+	if g.engine.Place(g.held, cell) {
+		// the rules see the block arrive like any other move
+		g.pending = append(g.pending, physics.Move{Object: g.held, From: from, Path: []utils.Cell{cell}})
+	}
+	g.drop()
+}
+
+// drop lets go of the held block and rings the character in control again
+func (g *Game) drop() {
+	if g.held == nil {
+		return
+	}
+	g.held = nil
+	g.renderer.SetFocus(nil)
+	if g.player != nil {
+		g.control(g.player)
+	}
+}
+
+// movable reports whether practice mode can pick obj up
+func movable(obj sprites.Sprite) bool {
+	switch obj.(type) {
+	case *sprites.Ice, *sprites.BigIce, *sprites.Stone, *sprites.Bomb:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"image/color"
+
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/solver"
+)
+
+// randomTries is how many seeds in a row randomizing a level tries before
+// settling for the level as it is
+const randomTries = 8
+
+// randomBoards are the board backgrounds randomized levels are drawn on,
+// one picked by the level's seed
+var randomBoards = []color.Color{
+	boardNight,
+	boardDusk,
+	boardDay,
+	color.NRGBA{R: 0x1e, G: 0x4d, B: 0x4f, A: 0xff}, // glacier teal
+	color.NRGBA{R: 0x23, G: 0x3d, B: 0x2c, A: 0xff}, // pine
+	color.NRGBA{R: 0x4a, G: 0x2c, B: 0x2a, A: 0xff}, // ember
+}
+
+// randomizeLevel transforms the current level by a transform picked from
+// seed, then calls start with the seed used. When the solver finds the
+// transformed level can't be won, the seeds following seed are tried in
+// turn; once randomTries of them have failed, the level is played as it
+// is. Levels the solver can't settle either way are played transformed.
+// The solver checks them on the solver pool, so start is called on a
+// later tick, unless the game moves on first.
+func (g *Game) randomizeLevel(seed uint64, start func(seed uint64, t levels.Transform)) {
+	g.stopShuffle()
+	section := g.levelsManager.CurrentSection()
+	shuffled := make([]*levels.Level, randomTries)
+	for i := range shuffled {
+		shuffled[i], _ = g.levelsManager.RandomizedCurrentLevel(seed + uint64(i))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.shuffle = cancel
+	g.solving.Go(ctx, func(ctx context.Context) func() {
+		found := -1
+		for i, level := range shuffled {
+			_, err := solver.Level(ctx, section, level, solver.MaxStates)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !errors.Is(err, solver.ErrUnsolvable) {
+				found = i
+				break
+			}
+			log.Debug("randomized level unsolvable", "seed", seed+uint64(i))
+		}
+		return func() {
+			g.shuffle = nil
+			if found < 0 {
+				start(seed, levels.Transform{})
+				return
+			}
+			start(seed+uint64(found), g.levelsManager.RandomizeCurrentLevel(seed+uint64(found)))
+		}
+	})
+}
+
+// stopShuffle drops the level being randomized, if any, as the game has
+// moved on from starting it
+func (g *Game) stopShuffle() {
+	if g.shuffle != nil {
+		g.shuffle()
+		g.shuffle = nil
+	}
+}
+
+// shuffledBoard is the board background of a level randomized with seed
+func shuffledBoard(seed uint64) color.Color {
+	return randomBoards[seed%uint64(len(randomBoards))]
+}
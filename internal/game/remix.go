@@ -0,0 +1,77 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/solver"
+)
+
+// remixTries is how many remixes in a row the weekly challenge tries
+// before giving up on the week
+const remixTries = 16
+
+// startRemix starts the remix challenge of the week date falls in: the
+// first remix, seed after seed from the week's, that the solver proves
+// winnable. The solver checks them on the solver pool, so the level
+// starts on a later tick, unless the game moves on first. Everyone gets
+// the same remix in a given week.
+func (g *Game) startRemix(date time.Time) {
+	g.stopShuffle()
+	year, week := date.ISOWeek()
+	seed := levels.RemixSeed(date)
+	remixes := make([]levels.Remix, remixTries)
+	for i := range remixes {
+		remixes[i] = g.levelsManager.Remix(seed + uint64(i))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.shuffle = cancel
+	g.solving.Go(ctx, func(ctx context.Context) func() {
+		for _, r := range remixes {
+			if len(r.Mutations) == 0 {
+				continue
+			}
+			moves, err := solver.Level(ctx, r.Section, r.Level, solver.MaxStates)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				log.Debug("remix rejected", "seed", r.Seed, "level", r.Code(), "err", err)
+				continue
+			}
+			return func() {
+				g.shuffle = nil
+				g.playRemix(fmt.Sprintf("%d-W%02d", year, week), r, len(moves))
+			}
+		}
+		return func() {
+			g.shuffle = nil
+			g.notice = "There's no remix this week"
+		}
+	})
+}
+
+// playRemix plays the remix r of week, its par the length of the shortest
+// solution the solver found
+func (g *Game) playRemix(week string, r levels.Remix, par int) {
+	g.dailyBoard, g.randomBoard = nil, nil
+	g.levelsManager.PlayRemix(r)
+	g.levelsManager.CurrentLevel().Par = par
+	g.remix = week
+	g.remixChanges = fmt.Sprintf("%s with %s", r.Code(), mutationList(r.Mutations))
+	log.Debug("weekly remix", "week", week, "seed", r.Seed, "level", r.Code(), "mutations", r.Mutations)
+	g.startCurrent()
+}
+
+// mutationList spells out the mutations of a remix
+func mutationList(mutations []levels.Mutation) string {
+	names := make([]string, len(mutations))
+	for i, m := range mutations {
+		names[i] = m.String()
+	}
+	return strings.Join(names, ", ")
+}
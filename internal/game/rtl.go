@@ -0,0 +1,19 @@
+package game
+
+import "github.com/zrcoder/icer/internal/i18n"
+
+// mirrorX returns where x lies once the screen is mirrored for a right to
+// left locale. Text aligned to its start or end needs no other change, as
+// right to left faces already flip which side those are.
+func mirrorX(x float64) float64 {
+	return mirrorSpan(x, 0)
+}
+
+// mirrorSpan returns the left edge of the span of width w starting at x,
+// once the screen is mirrored for a right to left locale
+func mirrorSpan(x, w float64) float64 {
+	if !i18n.RTL() {
+		return x
+	}
+	return WindowWidth - x - w
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/colornames"
+)
+
+var whiteImage = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(colornames.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
+
+// drawStar fills a five-pointed star centered at (cx, cy)
+func drawStar(screen *ebiten.Image, cx, cy, r float32, clr color.Color) {
+	if r <= 0 {
+		return
+	}
+	var path vector.Path
+	for i := range 10 {
+		radius := r
+		if i%2 == 1 {
+			radius = r * 0.45
+		}
+		a := float64(i)*math.Pi/5 - math.Pi/2
+		x := cx + radius*float32(math.Cos(a))
+		y := cy + radius*float32(math.Sin(a))
+		if i == 0 {
+			path.MoveTo(x, y)
+		} else {
+			path.LineTo(x, y)
+		}
+	}
+	path.Close()
+	fillPath(screen, &path, clr)
+}
+
+// drawHeart fills a heart of width 2*r whose top edge sits at y
+func drawHeart(screen *ebiten.Image, cx, y, r float32, clr color.Color) {
+	if r <= 0 {
+		return
+	}
+	var path vector.Path
+	path.MoveTo(cx, y+r*0.5)
+	path.CubicTo(cx, y, cx-r, y-r*0.2, cx-r, y+r*0.6)
+	path.CubicTo(cx-r, y+r*1.2, cx-r*0.3, y+r*1.5, cx, y+r*2)
+	path.CubicTo(cx+r*0.3, y+r*1.5, cx+r, y+r*1.2, cx+r, y+r*0.6)
+	path.CubicTo(cx+r, y-r*0.2, cx, y, cx, y+r*0.5)
+	path.Close()
+	fillPath(screen, &path, clr)
+}
+
+// fillPath fills path with a solid color
+func fillPath(screen *ebiten.Image, path *vector.Path, clr color.Color) {
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	cr, cg, cb, ca := clr.RGBA()
+	for i := range vs {
+		vs[i].SrcX, vs[i].SrcY = 1, 1
+		vs[i].ColorR = float32(cr) / 0xffff
+		vs[i].ColorG = float32(cg) / 0xffff
+		vs[i].ColorB = float32(cb) / 0xffff
+		vs[i].ColorA = float32(ca) / 0xffff
+	}
+	screen.DrawTriangles(vs, is, whiteImage, &ebiten.DrawTrianglesOptions{FillRule: ebiten.FillRuleNonZero})
+}
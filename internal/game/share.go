@@ -0,0 +1,273 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/zrcoder/icer/internal/clipboard"
+	"github.com/zrcoder/icer/internal/links"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	cardWidth   = 480
+	cardPadding = 20
+	cardLine    = 28
+)
+
+// shareEmoji maps sprite types to the emoji a shared result shows for
+// them; cells without a listed sprite are plain floor
+var shareEmoji = map[string]string{
+	"wall":         "⬛",
+	"fakewall":     "⬛",
+	"ice":          "🧊",
+	"stone":        "🪨",
+	"bomb":         "💣",
+	"crackedwall":  "🧱",
+	"flame":        "🔥",
+	"portal":       "🌀",
+	"player":       "🙂",
+	"npc":          "🧑",
+	"pot":          "🍲",
+	"gem":          "💎",
+	"coin":         "🪙",
+	"conveyor":     "🟦",
+	"oneway":       "🟪",
+	"fan":          "💨",
+	"crackedfloor": "🟫",
+	"sticky":       "🟤",
+	"hole":         "🕳️",
+	"water":        "🌊",
+	"plate":        "🟨",
+	"togglewall":   "🟧",
+	"lever":        "🕹️",
+	"phasewall":    "🔳",
+	"key":          "🔑",
+	"door":         "🚪",
+	"enemy":        "👾",
+}
+
+const floorEmoji = "⬜"
+
+// layout records the start of a level for sharing: the sprite type shown
+// in every cell and a picture of the board
+type layout struct {
+	cells   [][]string
+	preview *ebiten.Image
+}
+
+// newLayout captures objects as they stand before the first move. Tiles
+// only show where nothing stands on them.
+func newLayout(objects []sprites.Sprite, width, height int) *layout {
+	res := &layout{
+		cells:   make([][]string, height),
+		preview: ebiten.NewImage(width*CellSize, height*CellSize),
+	}
+	for y := range res.cells {
+		res.cells[y] = make([]string, width)
+	}
+	for _, obj := range objects {
+		pos := obj.Position()
+		if pos.X < 0 || pos.X >= width || pos.Y < 0 || pos.Y >= height {
+			continue
+		}
+		if _, ok := obj.(sprites.Tile); ok && res.cells[pos.Y][pos.X] != "" {
+			continue
+		}
+		res.cells[pos.Y][pos.X] = obj.Type()
+	}
+	for _, obj := range objects {
+		if _, ok := obj.(sprites.Tile); ok {
+			obj.Draw(res.preview)
+		}
+	}
+	for _, obj := range objects {
+		if _, ok := obj.(sprites.Tile); !ok {
+			obj.Draw(res.preview)
+		}
+	}
+	return res
+}
+
+// emoji renders the layout one emoji per cell, a row per line
+func (l *layout) emoji() string {
+	var b strings.Builder
+	for _, row := range l.cells {
+		for _, kind := range row {
+			if e, ok := shareEmoji[kind]; ok {
+				b.WriteString(e)
+			} else {
+				b.WriteString(floorEmoji)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// switchLetter marks a switch to the next character in a solution
+const switchLetter = "T"
+
+// turn is one move of a solution and the character that makes it
+type turn struct {
+	player int
+	dir    utils.Direction
+}
+
+// solution spells the moves taken so far, one letter each, with a
+// switchLetter wherever control passed to the next character
+func (g *Game) solution() string {
+	var b strings.Builder
+	current := 0
+	for _, s := range g.history {
+		for i := slices.Index(g.players, s.player); current != i; current = (current + 1) % len(g.players) {
+			b.WriteString(switchLetter)
+		}
+		b.WriteByte(s.dir.Letter())
+	}
+	return b.String()
+}
+
+// parseSolution reads a solution spelled by solution for the characters
+// of the current level
+func (g *Game) parseSolution(s string) ([]turn, bool) {
+	if len(g.players) == 0 {
+		return nil, false
+	}
+	var res []turn
+	for i, part := range strings.Split(strings.ToUpper(s), switchLetter) {
+		dirs, ok := utils.ParseDirections(part)
+		if !ok {
+			return nil, false
+		}
+		for _, dir := range dirs {
+			res = append(res, turn{player: i % len(g.players), dir: dir})
+		}
+	}
+	return res, true
+}
+
+// updatePaste replays a solution pasted with Ctrl+V (Cmd+V on macOS) from
+// the start of the level once the clipboard answers
+func (g *Game) updatePaste() {
+	if g.kiosk != nil {
+		return
+	}
+	if g.paste == nil {
+		if inpututil.IsKeyJustPressed(ebiten.KeyV) &&
+			(ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)) {
+			g.paste = clipboard.Read()
+		}
+		return
+	}
+	var res clipboard.Result
+	select {
+	case res = <-g.paste:
+		g.paste = nil
+	default:
+		return
+	}
+	moves, ok := g.parseSolution(res.Text)
+	if !ok || len(moves) == 0 {
+		if res.Err != nil {
+			log.Warn("cannot paste", "err", res.Err)
+		}
+		g.warning = "The clipboard holds no solution to replay."
+		return
+	}
+	g.RestartLevel()
+	g.replay = moves
+}
+
+// resultTitle names the level just won and rates it in stars
+func (g *Game) resultTitle() string {
+	if g.remix != "" {
+		return fmt.Sprintf("ICER REMIX %s %s", g.remix, strings.Repeat("⭐", g.stars()))
+	}
+	return fmt.Sprintf("ICER %d-%d %s",
+		g.levelsManager.CurrentSection().ID+1, g.levelsManager.CurrentLevel().ID+1,
+		strings.Repeat("⭐", g.stars()))
+}
+
+// resultStats sums up the win in moves and time played, marking wins on
+// hardcore profiles
+func (g *Game) resultStats() string {
+	secs := g.elapsed / ebiten.DefaultTPS
+	res := fmt.Sprintf("%s · %d:%02d", g.movesLabel(), secs/60, secs%60)
+	if g.levelsManager.Hardcore() {
+		res += " · hardcore"
+	}
+	return res
+}
+
+// shareText is the result the player copies, Wordle style: title, stars,
+// stats and the level as an emoji grid, followed by a link to play it
+// unless it was randomized or remixed
+func (g *Game) shareText() string {
+	res := g.resultTitle() + "\n" + g.resultStats() + "\n\n" + g.layout.emoji()
+	if !g.randomize && g.remix == "" {
+		res += links.Link{Kind: links.KindLevel, Code: g.levelsManager.Code()}.String() + "\n"
+	}
+	return res
+}
+
+// shareCard renders the result as an image: the same summary as the text
+// above a picture of the level
+func (g *Game) shareCard() *ebiten.Image {
+	preview := g.layout.preview
+	pw, ph := preview.Bounds().Dx(), preview.Bounds().Dy()
+	scale := min(1, float64(cardWidth-2*cardPadding)/float64(pw))
+	header := float64(2*cardPadding + 2*cardLine)
+	height := int(header + float64(ph)*scale + cardPadding)
+
+	card := ebiten.NewImage(cardWidth, height)
+	card.Fill(colornames.Midnightblue)
+	style := rendering.TextStyle{Color: colornames.Orange, Align: text.AlignCenter, Shadow: colornames.Black}
+	rendering.DrawText(card, g.resultTitle(), defaultFace, cardWidth/2, cardPadding, style)
+	style.Color = colornames.Gainsboro
+	rendering.DrawText(card, g.resultStats(), defaultFace, cardWidth/2, cardPadding+cardLine, style)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate((cardWidth-float64(pw)*scale)/2, header)
+	card.DrawImage(preview, op)
+	return card
+}
+
+// saveShareCard writes the result card as a PNG in the player's home
+// directory and returns its path
+func (g *Game) saveShareCard() (string, error) {
+	card := g.shareCard()
+	defer card.Deallocate()
+	img := image.NewRGBA(card.Bounds())
+	card.ReadPixels(img.Pix)
+
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("icer-%d-%d.png",
+		g.levelsManager.CurrentSection().ID+1, g.levelsManager.CurrentLevel().ID+1))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+// The day's backgrounds, dark enough at every hour for white text and the
+// sprites to stand out
+var (
+	menuNight = color.NRGBA{R: 0x05, G: 0x07, B: 0x14, A: 0xff}
+	menuDusk  = color.NRGBA{R: 0x2e, G: 0x1a, B: 0x33, A: 0xff}
+	menuDay   = color.NRGBA{R: 0x1b, G: 0x2d, B: 0x45, A: 0xff}
+
+	boardNight = colornames.Midnightblue
+	boardDusk  = color.NRGBA{R: 0x4b, G: 0x30, B: 0x60, A: 0xff}
+	boardDay   = color.NRGBA{R: 0x2f, G: 0x5d, B: 0x8a, A: 0xff}
+)
+
+// menuSky and boardSky run through a day from midnight, an entry every
+// three hours: night until 3, dawn at 6, day from 9 to 15, dusk at 18 and
+// night again from 21
+var (
+	menuSky  = utils.Palette{menuNight, menuNight, menuDusk, menuDay, menuDay, menuDay, menuDusk, menuNight, menuNight}
+	boardSky = utils.Palette{boardNight, boardNight, boardDusk, boardDay, boardDay, boardDay, boardDusk, boardNight, boardNight}
+)
+
+// dayFraction is how far through its day t is, from 0 at midnight to 1
+func dayFraction(t time.Time) float64 {
+	h, m, s := t.Clock()
+	return float64(h*3600+m*60+s) / (24 * 3600)
+}
+
+// SetClockTheme turns on or off shading the menu and board backgrounds by
+// the time of day on the system clock
+func (g *Game) SetClockTheme(on bool) {
+	g.clockTheme = on
+}
+
+// updateSky toggles the clock theme with F5 and shades the board for the
+// time of day
+func (g *Game) updateSky() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		g.SetClockTheme(!g.clockTheme)
+		g.warning = "Clock theme off"
+		if g.clockTheme {
+			g.warning = "Clock theme on"
+		}
+	}
+	if g.renderer == nil {
+		return
+	}
+	if g.dailyBoard != nil {
+		g.renderer.SetBackground(g.dailyBoard)
+		return
+	}
+	if g.randomBoard != nil {
+		g.renderer.SetBackground(g.randomBoard)
+		return
+	}
+	if !g.clockTheme {
+		g.renderer.SetBackground(rendering.DefaultBackground)
+		return
+	}
+	g.renderer.SetBackground(boardSky.At(dayFraction(time.Now())))
+}
+
+// menuBackground is the color behind the menus and around the board
+func (g *Game) menuBackground() color.Color {
+	if !g.clockTheme {
+		return colornames.Black
+	}
+	return menuSky.At(dayFraction(time.Now()))
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// spinnerDots is how many dots make up the progress spinner, and
+	// spinnerTicks how long its lit dot stays on each
+	spinnerDots  = 8
+	spinnerTicks = 6
+)
+
+// solverPool runs solver jobs on worker goroutines, away from the game
+// loop, so hints, ratings and randomized levels never hold up a frame.
+// Jobs asked for by the player run ahead of background ones. A job hands
+// back what to do with its result, which Update runs on the game loop
+// unless the job's context was cancelled in the meantime.
+type solverPool struct {
+	mu   sync.Mutex
+	wake *sync.Cond
+	// urgent and background are the jobs waiting for a worker
+	urgent, background []solverJob
+	done               chan solverResult
+	// pending counts the jobs whose results Update hasn't taken in yet
+	pending int
+	ticks   int
+}
+
+// solverJob is work for the pool: run solves on a worker and returns what
+// to do with the result on the game loop, or nil for nothing
+type solverJob struct {
+	ctx context.Context
+	run func(ctx context.Context) func()
+}
+
+type solverResult struct {
+	ctx   context.Context
+	apply func()
+}
+
+// newSolverPool starts a worker for each core the game loop leaves free
+func newSolverPool() *solverPool {
+	p := &solverPool{done: make(chan solverResult)}
+	p.wake = sync.NewCond(&p.mu)
+	for range max(runtime.NumCPU()-1, 1) {
+		go p.work()
+	}
+	return p
+}
+
+// Go queues run to be solved ahead of any background jobs. Cancelling ctx
+// drops the job, and its result if it is already running.
+func (p *solverPool) Go(ctx context.Context, run func(ctx context.Context) func()) {
+	p.queue(&p.urgent, solverJob{ctx, run})
+}
+
+// Background queues run behind every other job
+func (p *solverPool) Background(ctx context.Context, run func(ctx context.Context) func()) {
+	p.queue(&p.background, solverJob{ctx, run})
+}
+
+func (p *solverPool) queue(jobs *[]solverJob, j solverJob) {
+	p.pending++
+	p.mu.Lock()
+	*jobs = append(*jobs, j)
+	p.mu.Unlock()
+	p.wake.Signal()
+}
+
+// work runs jobs as they come, for as long as the game runs
+func (p *solverPool) work() {
+	for {
+		p.mu.Lock()
+		for len(p.urgent) == 0 && len(p.background) == 0 {
+			p.wake.Wait()
+		}
+		jobs := &p.urgent
+		if len(p.urgent) == 0 {
+			jobs = &p.background
+		}
+		j := (*jobs)[0]
+		*jobs = (*jobs)[1:]
+		p.mu.Unlock()
+		var apply func()
+		if j.ctx.Err() == nil {
+			apply = j.run(j.ctx)
+		}
+		p.done <- solverResult{j.ctx, apply}
+		// browsers run the game on one thread; let a frame through
+		runtime.Gosched()
+	}
+}
+
+// Update takes in the jobs finished since the last tick, applying the
+// results of those still wanted
+func (p *solverPool) Update() {
+	if p.pending > 0 {
+		p.ticks++
+	}
+	for {
+		select {
+		case r := <-p.done:
+			p.pending--
+			if r.apply != nil && r.ctx.Err() == nil {
+				r.apply()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Draw spins a ring of dots in the bottom corner while jobs are pending
+func (p *solverPool) Draw(screen *ebiten.Image) {
+	if p.pending == 0 {
+		return
+	}
+	const radius = 10
+	cx, cy := float32(mirrorX(WindowWidth-24)), float32(WindowHeight-24)
+	lit := p.ticks / spinnerTicks % spinnerDots
+	for i := range spinnerDots {
+		a := 2 * math.Pi * float64(i) / spinnerDots
+		x, y := cx+radius*float32(math.Cos(a)), cy+radius*float32(math.Sin(a))
+		clr := colornames.Slategray
+		if i == lit {
+			clr = colornames.White
+		}
+		vector.DrawFilledCircle(screen, x, y, 2.5, clr, true)
+	}
+}
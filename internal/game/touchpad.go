@@ -0,0 +1,22 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/colornames"
+)
+
+var touchPadFill = color.NRGBA{R: 40, G: 40, B: 60, A: 160}
+
+// drawTouchPad draws the on-screen controls while the player uses touch
+func (g *Game) drawTouchPad(screen *ebiten.Image) {
+	for _, b := range g.input.TouchPad() {
+		x, y := float32(b.Rect.Min.X), float32(b.Rect.Min.Y)
+		w, h := float32(b.Rect.Dx()), float32(b.Rect.Dy())
+		vector.DrawFilledRect(screen, x, y, w, h, touchPadFill, false)
+		vector.StrokeRect(screen, x, y, w, h, 2, colornames.Gainsboro, false)
+		drawCentered(screen, b.Label, b.Rect.Min.X+b.Rect.Dx()/2, b.Rect.Min.Y+b.Rect.Dy()/2-10)
+	}
+}
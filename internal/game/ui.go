@@ -1,27 +1,34 @@
 package game
 
 import (
-	"bytes"
 	"fmt"
 	"image/color"
 	"strconv"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/ebitenui/ebitenui/image"
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"github.com/zrcoder/icer/internal/levels/sections"
 	"golang.org/x/image/colornames"
-	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/text/language"
 )
 
+// defaultFontSize is the size of most text, in pixels
+const defaultFontSize = 20
+
 var defaultFace = DefaultFont()
 
+// widgetFace is the face of the menu widgets, which lay their text out
+// left to right whatever the locale
+var widgetFace = DefaultFont()
+
 func (g *Game) initUI() {
 	g.titleContainer = widget.NewContainer()
 	g.createSelectUI()
 	g.createScenUI()
+	g.createLoseUI()
 }
 
 func (g *Game) createSelectUI() {
@@ -36,25 +43,78 @@ func (g *Game) createSelectUI() {
 	)
 
 	// root.AddChild()
+	root.AddChild(g.createModeContainer())
 	root.AddChild(g.createSectionContainer())
 	root.AddChild(g.createLevelContainer())
 	g.selectUI.Container = root
 }
 
 func (g *Game) createSectionContainer() *widget.Container {
-	return g.createSectionLevelContainer("Section", sections.Count, func(i int) {
+	container, _ := g.createSectionLevelContainer("Section", len(g.levelsManager.Sections), g.sectionLabel, g.levelsManager.SectionUnlocked, func(i int) {
 		g.levelsManager.SetCurrentSection(i)
+		g.createSelectUI()
 	})
+	return container
 }
 
 func (g *Game) createLevelContainer() *widget.Container {
-	return g.createSectionLevelContainer("Level", g.levelsManager.CurrentSection().LevelCount, func(i int) {
-		g.levelsManager.SetCurrentLevel(i)
-		g.state = StatePlaying
+	section := g.levelsManager.CurrentSection()
+	container, buttons := g.createSectionLevelContainer("Level", section.LevelCount, g.levelLabel, section.Unlocked, func(i int) {
+		g.startLevel(i)
 	})
+	g.levelButtons = buttons
+	return container
 }
 
-func (g *Game) createSectionLevelContainer(title string, count int, buttonClickHander func(int)) *widget.Container {
+func (g *Game) createModeContainer() *widget.Container {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(18),
+		)),
+	)
+	container.AddChild(createToggle("Challenge", &g.challenge.enabled))
+	container.AddChild(createToggle("Randomizer", &g.randomize))
+	container.AddChild(createWideButton("Map", func(args *widget.ButtonClickedEventArgs) {
+		g.setState(StateMap)
+	}))
+	container.AddChild(createWideButton("Journal", func(args *widget.ButtonClickedEventArgs) {
+		g.setState(StateJournal)
+	}))
+	container.AddChild(createWideButton("Weekly Remix", func(args *widget.ButtonClickedEventArgs) {
+		g.startRemix(time.Now())
+	}))
+	return container
+}
+
+func createToggle(name string, value *bool) *widget.Button {
+	var button *widget.Button
+	button = createWideButton(toggleLabel(name, *value), func(args *widget.ButtonClickedEventArgs) {
+		*value = !*value
+		button.SetText(toggleLabel(name, *value))
+	})
+	return button
+}
+
+func toggleLabel(name string, enabled bool) string {
+	if enabled {
+		return name + ": On"
+	}
+	return name + ": Off"
+}
+
+// sectionLabel is the text of the button for section i, with the stars
+// it asks for while locked
+func (g *Game) sectionLabel(i int) string {
+	label := strconv.Itoa(i + 1)
+	if !g.levelsManager.SectionUnlocked(i) {
+		label += fmt.Sprintf("\n%d stars", g.levelsManager.Sections[i].Gate)
+	}
+	return label
+}
+
+// createSectionLevelContainer lays out a titled row of numbered buttons,
+// disabling those not unlocked, and returns it with the buttons
+func (g *Game) createSectionLevelContainer(title string, count int, buttonLabel func(int) string, unlocked func(int) bool, buttonClickHander func(int)) (*widget.Container, []*widget.Button) {
 	container := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewRowLayout(
 			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
@@ -63,7 +123,7 @@ func (g *Game) createSectionLevelContainer(title string, count int, buttonClickH
 	label := widget.NewLabel(
 		widget.LabelOpts.Text(
 			title,
-			&defaultFace,
+			&widgetFace,
 			&widget.LabelColor{
 				Idle: colornames.White,
 			}),
@@ -75,9 +135,10 @@ func (g *Game) createSectionLevelContainer(title string, count int, buttonClickH
 			widget.GridLayoutOpts.Spacing(18, 0),
 		)),
 	)
+	buttons := make([]*widget.Button, count)
 	for i := range count {
 		button := createButton(
-			strconv.Itoa(i+1),
+			buttonLabel(i),
 			func(args *widget.ButtonClickedEventArgs) {
 				log.Debug("button clicked", "title", title, "id", i)
 				buttonClickHander(i)
@@ -86,10 +147,12 @@ func (g *Game) createSectionLevelContainer(title string, count int, buttonClickH
 		if title == "Section" && i == g.levelsManager.CurrentSection().ID {
 			button.Focus(true)
 		}
+		button.GetWidget().Disabled = !unlocked(i)
+		buttons[i] = button
 		body.AddChild(button)
 	}
 	container.AddChild(body)
-	return container
+	return container, buttons
 }
 
 func createButton(name string, handler func(args *widget.ButtonClickedEventArgs)) *widget.Button {
@@ -105,12 +168,15 @@ func createButton(name string, handler func(args *widget.ButtonClickedEventArgs)
 			Idle:    image.NewBorderedNineSliceColor(colornames.Black, colornames.Gainsboro, 3),
 			Hover:   image.NewBorderedNineSliceColor(color.NRGBA{R: 130, G: 130, B: 150, A: 255}, color.NRGBA{70, 70, 70, 255}, 3),
 			Pressed: image.NewAdvancedNineSliceColor(color.NRGBA{R: 130, G: 130, B: 150, A: 255}, image.NewBorder(3, 2, 2, 2, color.NRGBA{70, 70, 70, 255})),
+			// locked levels and sections
+			Disabled: image.NewBorderedNineSliceColor(colornames.Black, colornames.Dimgray, 3),
 		}),
 		widget.ButtonOpts.Text(
 			name,
-			&defaultFace,
+			&widgetFace,
 			&widget.ButtonTextColor{
-				Idle: colornames.Gainsboro,
+				Idle:     colornames.Gainsboro,
+				Disabled: colornames.Dimgray,
 			},
 		),
 		widget.ButtonOpts.TextProcessBBCode(false),
@@ -125,34 +191,114 @@ func (g *Game) createScenUI() {
 	g.sceneUI.Container = root
 }
 
+func (g *Game) createLoseUI() {
+	root := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
+	)
+	panel := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(image.NewBorderedNineSliceColor(colornames.Black, colornames.Gainsboro, 3)),
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Padding(widget.NewInsetsSimple(30)),
+			widget.RowLayoutOpts.Spacing(15),
+		)),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+				HorizontalPosition: widget.AnchorLayoutPositionCenter,
+				VerticalPosition:   widget.AnchorLayoutPositionCenter,
+			}),
+		),
+	)
+	panel.AddChild(widget.NewLabel(
+		widget.LabelOpts.Text("GAME OVER", &widgetFace, &widget.LabelColor{Idle: colornames.Orangered}),
+	))
+	g.loseReason = widget.NewLabel(
+		widget.LabelOpts.Text("", &widgetFace, &widget.LabelColor{Idle: colornames.Gainsboro}),
+	)
+	panel.AddChild(g.loseReason)
+	// undoing is offered first, as taking back the losing move is usually
+	// all it takes to carry on
+	g.undoButton = createWideButton("Undo last move", func(args *widget.ButtonClickedEventArgs) {
+		if g.canUndoLoss() && g.Undo() {
+			g.setState(StatePlaying)
+		}
+	})
+	panel.AddChild(g.undoButton)
+	g.retryButton = createWideButton("Retry", func(args *widget.ButtonClickedEventArgs) {
+		g.retryLevel()
+	})
+	panel.AddChild(g.retryButton)
+	panel.AddChild(createWideButton("Levels", func(args *widget.ButtonClickedEventArgs) {
+		g.setState(StateSelect)
+	}))
+	root.AddChild(panel)
+	g.loseUI.Container = root
+}
+
+func createWideButton(name string, handler func(args *widget.ButtonClickedEventArgs)) *widget.Button {
+	button := createButton(name, handler)
+	button.GetWidget().MinWidth = 170
+	button.GetWidget().MinHeight = 50
+	return button
+}
+
 // Draw renders the game
 func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Fill(colornames.Black)
+	screen.Fill(g.menuBackground())
 	switch g.state {
 	case StateSelect:
 		g.selectUI.Draw(screen)
+		g.drawChecks(screen)
+		g.drawEvents(screen)
+		g.drawNotice(screen)
 	case StatePlaying:
 		g.updateTitle()
 		g.sceneUI.Draw(screen)
+		g.drawGame(screen)
+		g.challenge.Draw(screen, g.levelsManager.CurrentSection())
+		if g.dialog != nil {
+			g.dialog.Draw(screen, g.input)
+		}
+		g.drawTouchPad(screen)
 	case StateWin:
 		g.drawGame(screen)
 		g.drawWin(screen)
 	case StateLose:
 		g.drawGame(screen)
 		g.drawLose(screen)
+		g.challenge.Draw(screen, g.levelsManager.CurrentSection())
+	case StateMap:
+		g.drawMap(screen)
+		g.drawEvents(screen)
+	case StateJournal:
+		g.drawJournal(screen)
+	case StateBroken:
+		g.drawBroken(screen)
+	}
+	g.solving.Draw(screen)
+	g.diagnostics.Draw(screen)
+	if g.kiosk != nil {
+		g.kiosk.Draw(screen)
 	}
 }
 
 func (g *Game) updateTitle() {
 	g.sceneUI.Container.RemoveChild(g.titleContainer)
 	g.titleContainer.RemoveChildren()
+	title := fmt.Sprintf(
+		"ICE %d-%d",
+		g.levelsManager.CurrentSection().ID+1, g.levelsManager.CurrentLevel().ID+1,
+	)
+	if g.randomize {
+		title += fmt.Sprintf("  seed %d", g.seed)
+	}
+	if g.remix != "" {
+		title = fmt.Sprintf("REMIX %s  %s", g.remix, g.remixChanges)
+	}
 	label := widget.NewLabel(
 		widget.LabelOpts.Text(
-			fmt.Sprintf(
-				"ICE %d-%d",
-				g.levelsManager.CurrentSection().ID+1, g.levelsManager.CurrentLevel().ID+1,
-			),
-			&defaultFace,
+			title,
+			&widgetFace,
 			&widget.LabelColor{
 				Idle:     colornames.Orange,
 				Disabled: colornames.Orange,
@@ -163,13 +309,8 @@ func (g *Game) updateTitle() {
 	g.sceneUI.Container.AddChild(g.titleContainer)
 }
 
+// DefaultFont returns the face most text is drawn with: the chain of
+// fonts set up for the game, left to right
 func DefaultFont() text.Face {
-	s, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
-	if err != nil {
-		panic(err)
-	}
-	return &text.GoTextFace{
-		Source: s,
-		Size:   20,
-	}
+	return fontSet.Face(defaultFontSize, text.DirectionLeftToRight, language.Und)
 }
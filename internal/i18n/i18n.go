@@ -0,0 +1,72 @@
+// Package i18n picks the language the game's text is shown in. Locales
+// are BCP 47 tags such as "fr" or "pt-BR".
+package i18n
+
+import "strings"
+
+// current is the locale in use; empty means the default language, English
+var current string
+
+// Set makes locale, such as "pt-BR" or the "pt_BR.UTF-8" of a POSIX
+// environment, the one in use
+func Set(locale string) {
+	current = Normalize(locale)
+}
+
+// Current returns the locale in use, or "" for the default language
+func Current() string {
+	return current
+}
+
+// rtlLanguages are the languages written right to left
+var rtlLanguages = map[string]bool{
+	"ar": true, "ckb": true, "dv": true, "fa": true, "he": true, "iw": true,
+	"ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// Language returns the language of the locale in use without its region
+// or script, such as "pt" for "pt-BR"
+func Language() string {
+	lang, _, _ := strings.Cut(current, "-")
+	return strings.ToLower(lang)
+}
+
+// RTL reports whether the locale in use is written right to left, such as
+// Arabic or Hebrew
+func RTL() bool {
+	return rtlLanguages[Language()]
+}
+
+// Normalize turns a POSIX or BCP 47 locale into a BCP 47 tag, dropping
+// any encoding or modifier. The C and POSIX locales mean the default
+// language and give "".
+func Normalize(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return locale
+}
+
+// Pick returns the entry of m for the locale in use: the one for the
+// full tag, then the one for its language alone. ok is false when there
+// is neither, and the default should be kept.
+func Pick[T any](m map[string]T) (v T, ok bool) {
+	if current == "" {
+		return v, false
+	}
+	for tag := current; ; {
+		for key, entry := range m {
+			if strings.EqualFold(key, tag) {
+				return entry, true
+			}
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			return v, false
+		}
+		tag = tag[:i]
+	}
+}
@@ -0,0 +1,14 @@
+//go:build js
+
+package i18n
+
+import "syscall/js"
+
+// System returns the locale the user picked for their browser
+func System() string {
+	language := js.Global().Get("navigator").Get("language")
+	if language.Type() != js.TypeString {
+		return ""
+	}
+	return Normalize(language.String())
+}
@@ -0,0 +1,14 @@
+//go:build !js && !windows
+
+package i18n
+
+import (
+	"cmp"
+	"os"
+)
+
+// System returns the locale the user picked for their system, from the
+// environment as POSIX systems set it
+func System() string {
+	return Normalize(cmp.Or(os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")))
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package i18n
+
+import "golang.org/x/sys/windows"
+
+// System returns the locale the user picked for their system, the first
+// of their preferred display languages
+func System() string {
+	langs, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil || len(langs) == 0 {
+		return ""
+	}
+	return Normalize(langs[0])
+}
@@ -0,0 +1,271 @@
+package input
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Device is a family of input devices sharing one set of glyphs
+type Device int
+
+const (
+	DeviceKeyboard Device = iota
+	DeviceXbox
+	DevicePlayStation
+	DeviceSwitch
+	DeviceMouse
+	DeviceTouch
+)
+
+// Action is a logical input, independent of the device producing it
+type Action int
+
+const (
+	ActionConfirm Action = iota
+	ActionBack
+	ActionUp
+	ActionDown
+	ActionLeft
+	ActionRight
+	ActionUndo
+	ActionRestart
+	// ActionSwitch hands control to the next character on levels with
+	// more than one
+	ActionSwitch
+	// ActionHint shows the next push of the shortest solution
+	ActionHint
+)
+
+type binding struct {
+	keys    []ebiten.Key
+	buttons []ebiten.StandardGamepadButton
+}
+
+var bindings = map[Action]binding{
+	ActionConfirm: {[]ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightBottom}},
+	ActionBack:    {[]ebiten.Key{ebiten.KeyEscape}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightRight}},
+	ActionUp:      {[]ebiten.Key{ebiten.KeyUp, ebiten.KeyI}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftTop}},
+	ActionDown:    {[]ebiten.Key{ebiten.KeyDown, ebiten.KeyK}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftBottom}},
+	ActionLeft:    {[]ebiten.Key{ebiten.KeyLeft, ebiten.KeyJ}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftLeft}},
+	ActionRight:   {[]ebiten.Key{ebiten.KeyRight, ebiten.KeyL}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftRight}},
+	ActionUndo:    {[]ebiten.Key{ebiten.KeyU, ebiten.KeyZ}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightTop}},
+	ActionRestart: {[]ebiten.Key{ebiten.KeyR}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonCenterLeft}},
+	ActionSwitch:  {[]ebiten.Key{ebiten.KeyTab}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonFrontTopRight}},
+	ActionHint:    {[]ebiten.Key{ebiten.KeyH}, []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonFrontTopLeft}},
+}
+
+var moves = map[utils.Direction]Action{
+	utils.Up:    ActionUp,
+	utils.Right: ActionRight,
+	utils.Down:  ActionDown,
+	utils.Left:  ActionLeft,
+}
+
+var glyphs = map[Device]map[Action]string{
+	DeviceKeyboard: {
+		ActionConfirm: "Enter", ActionBack: "Esc",
+		ActionUp: "Up", ActionDown: "Down", ActionLeft: "Left", ActionRight: "Right",
+		ActionUndo: "U", ActionRestart: "R", ActionSwitch: "Tab", ActionHint: "H",
+	},
+	DeviceXbox: {
+		ActionConfirm: "A", ActionBack: "B",
+		ActionUp: "D-Up", ActionDown: "D-Down", ActionLeft: "D-Left", ActionRight: "D-Right",
+		ActionUndo: "Y", ActionRestart: "View", ActionSwitch: "RB", ActionHint: "LB",
+	},
+	DevicePlayStation: {
+		ActionConfirm: "Cross", ActionBack: "Circle",
+		ActionUp: "D-Up", ActionDown: "D-Down", ActionLeft: "D-Left", ActionRight: "D-Right",
+		ActionUndo: "Triangle", ActionRestart: "Create", ActionSwitch: "R1", ActionHint: "L1",
+	},
+	// Switch controllers report the face buttons by position, so the bottom
+	// button that confirms is labelled B and the right one A
+	DeviceSwitch: {
+		ActionConfirm: "B", ActionBack: "A",
+		ActionUp: "D-Up", ActionDown: "D-Down", ActionLeft: "D-Left", ActionRight: "D-Right",
+		ActionUndo: "X", ActionRestart: "-", ActionSwitch: "R", ActionHint: "L",
+	},
+	DeviceMouse: {
+		ActionConfirm: "Click", ActionBack: "Right Click",
+	},
+	DeviceTouch: {
+		ActionConfirm: "Tap", ActionBack: "Back",
+		ActionUp: "Pad Up", ActionDown: "Pad Down", ActionLeft: "Pad Left", ActionRight: "Pad Right",
+		ActionUndo: "Undo", ActionRestart: "Reset", ActionSwitch: "Swap", ActionHint: "Hint",
+	},
+}
+
+var mouseBindings = map[Action]ebiten.MouseButton{
+	ActionConfirm: ebiten.MouseButtonLeft,
+	ActionBack:    ebiten.MouseButtonRight,
+}
+
+// Manager maps raw keyboard, mouse, touch and gamepad input to actions and
+// tracks the device the player used last, so prompts and UI affordances
+// can follow whatever the player picks up mid-session
+type Manager struct {
+	device   Device
+	pad      []PadButton
+	gamepads []ebiten.GamepadID
+	keys     []ebiten.Key
+	buttons  []ebiten.StandardGamepadButton
+	touches  []ebiten.TouchID
+	tapped   map[Action]bool
+	cursorX  int
+	cursorY  int
+	active   bool
+}
+
+// NewManager creates an input manager that starts on the keyboard, with
+// the on-screen pad laid out for a screen of the given size
+func NewManager(width, height int) *Manager {
+	return &Manager{
+		pad:    newTouchPad(width, height),
+		tapped: make(map[Action]bool),
+	}
+}
+
+// Update must be called once per tick before querying actions
+func (m *Manager) Update() {
+	prev := m.device
+	m.active = false
+	m.keys = inpututil.AppendJustPressedKeys(m.keys[:0])
+	if len(m.keys) > 0 {
+		m.device = DeviceKeyboard
+		m.active = true
+	}
+	x, y := ebiten.CursorPosition()
+	clicked := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) ||
+		inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+	if x != m.cursorX || y != m.cursorY || clicked {
+		m.device = DeviceMouse
+		m.active = m.active || clicked
+	}
+	m.cursorX, m.cursorY = x, y
+	m.gamepads = ebiten.AppendGamepadIDs(m.gamepads[:0])
+	for _, id := range m.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		m.buttons = inpututil.AppendJustPressedStandardGamepadButtons(id, m.buttons[:0])
+		if len(m.buttons) > 0 {
+			m.device = gamepadDevice(ebiten.GamepadName(id))
+			m.active = true
+		}
+	}
+	clear(m.tapped)
+	m.touches = inpututil.AppendJustPressedTouchIDs(m.touches[:0])
+	for _, id := range m.touches {
+		m.device = DeviceTouch
+		m.active = true
+		tx, ty := ebiten.TouchPosition(id)
+		for _, b := range m.pad {
+			if image.Pt(tx, ty).In(b.Rect) {
+				m.tapped[b.Action] = true
+			}
+		}
+	}
+	if m.device != prev {
+		m.applyCursor()
+	}
+}
+
+// applyCursor shows the mouse cursor only while the mouse is in use
+func (m *Manager) applyCursor() {
+	if m.device == DeviceMouse {
+		ebiten.SetCursorMode(ebiten.CursorModeVisible)
+	} else {
+		ebiten.SetCursorMode(ebiten.CursorModeHidden)
+	}
+}
+
+// JustPressed reports whether action was triggered on this tick by any device
+func (m *Manager) JustPressed(action Action) bool {
+	if m.tapped[action] {
+		return true
+	}
+	if button, ok := mouseBindings[action]; ok && inpututil.IsMouseButtonJustPressed(button) {
+		return true
+	}
+	b := bindings[action]
+	for _, key := range b.keys {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+	for _, id := range m.gamepads {
+		for _, button := range b.buttons {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Direction returns the direction of the movement action triggered on this
+// tick, if any
+func (m *Manager) Direction() (utils.Direction, bool) {
+	for _, dir := range utils.Directions {
+		if m.JustPressed(moves[dir]) {
+			return dir, true
+		}
+	}
+	return utils.Up, false
+}
+
+// Active reports whether any key, button or touch went down on this tick
+func (m *Manager) Active() bool {
+	return m.active
+}
+
+// Device returns the device family used last
+func (m *Manager) Device() Device {
+	return m.device
+}
+
+// Glyph returns the label of the control bound to action on the last used
+// device, falling back to the keyboard when that device has no such control
+func (m *Manager) Glyph(action Action) string {
+	if glyph, ok := glyphs[m.device][action]; ok {
+		return glyph
+	}
+	return glyphs[DeviceKeyboard][action]
+}
+
+// TouchPad returns the on-screen controls to draw, or nil unless the
+// player is using touch
+func (m *Manager) TouchPad() []PadButton {
+	if m.device != DeviceTouch {
+		return nil
+	}
+	return m.pad
+}
+
+// Prompt formats a contextual hint such as "Press [A] to undo"
+func (m *Manager) Prompt(action Action, what string) string {
+	return fmt.Sprintf("Press [%s] to %s", m.Glyph(action), what)
+}
+
+func gamepadDevice(name string) Device {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "playstation"),
+		strings.Contains(name, "dualshock"),
+		strings.Contains(name, "dualsense"),
+		strings.Contains(name, "ps4"),
+		strings.Contains(name, "ps5"):
+		return DevicePlayStation
+	case strings.Contains(name, "nintendo"),
+		strings.Contains(name, "switch"),
+		strings.Contains(name, "joy-con"),
+		strings.Contains(name, "pro controller"):
+		return DeviceSwitch
+	default:
+		return DeviceXbox
+	}
+}
@@ -0,0 +1,45 @@
+package input
+
+import "image"
+
+const (
+	padButtonSize = 64
+	padMargin     = 24
+)
+
+// PadButton is an on-screen control shown while the player uses touch
+type PadButton struct {
+	Action Action
+	Label  string
+	Rect   image.Rectangle
+}
+
+// newTouchPad lays out a D-pad in the bottom left corner and a column of
+// back, undo, reset, swap and hint buttons in the bottom right corner of a
+// screen of the given size
+func newTouchPad(width, height int) []PadButton {
+	cell := func(col, row int) image.Rectangle {
+		x := padMargin + col*padButtonSize
+		y := height - padMargin - (3-row)*padButtonSize
+		return image.Rect(x, y, x+padButtonSize, y+padButtonSize)
+	}
+	back := image.Rect(
+		width-padMargin-padButtonSize, height-padMargin-padButtonSize,
+		width-padMargin, height-padMargin,
+	)
+	undo := back.Sub(image.Pt(0, padButtonSize+padMargin))
+	reset := undo.Sub(image.Pt(0, padButtonSize+padMargin))
+	swap := reset.Sub(image.Pt(0, padButtonSize+padMargin))
+	hint := swap.Sub(image.Pt(0, padButtonSize+padMargin))
+	return []PadButton{
+		{ActionUp, "^", cell(1, 0)},
+		{ActionLeft, "<", cell(0, 1)},
+		{ActionRight, ">", cell(2, 1)},
+		{ActionDown, "v", cell(1, 2)},
+		{ActionBack, "Back", back},
+		{ActionUndo, "Undo", undo},
+		{ActionRestart, "Reset", reset},
+		{ActionSwitch, "Swap", swap},
+		{ActionHint, "Hint", hint},
+	}
+}
@@ -0,0 +1,60 @@
+package levels
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Code returns the current level's code: its section and level numbers
+// counted from 1, as in "2-5", the way level titles show them
+func (m *Manager) Code() string {
+	return code(m.currentSection.ID, m.currentLevel.ID)
+}
+
+// code returns the code of level in section, both counted from 0
+func code(section, level int) string {
+	return fmt.Sprintf("%d-%d", section+1, level+1)
+}
+
+// SelectCode makes the level with the given code current. Bonus levels
+// are only reachable once unlocked.
+func (m *Manager) SelectCode(code string) error {
+	var section, level int
+	if _, err := fmt.Sscanf(code, "%d-%d", &section, &level); err != nil {
+		return fmt.Errorf("bad level code %q: %w", code, err)
+	}
+	if section < 1 || section > len(m.Sections) {
+		return fmt.Errorf("level code %q: no section %d", code, section)
+	}
+	s := m.Sections[section-1]
+	if level < 1 || level > s.VisibleLevels() {
+		return fmt.Errorf("level code %q: no level %d in section %d", code, level, section)
+	}
+	m.SetCurrentSection(section - 1)
+	m.SetCurrentLevel(level - 1)
+	return nil
+}
+
+// SelectDaily makes the daily puzzle of date current: a regular level of
+// the built-in sections picked from the date, the same one for everyone
+// on that day whatever packs they have. It returns
+// the seed to randomize the level with, also taken from the date.
+func (m *Manager) SelectDaily(date time.Time) uint64 {
+	y, mo, d := date.Date()
+	seed := uint64(y*10000 + int(mo)*100 + d)
+	total := 0
+	for _, s := range m.builtin() {
+		total += s.LevelCount
+	}
+	n := rand.New(rand.NewPCG(seed, seed)).IntN(total)
+	for i, s := range m.builtin() {
+		if n < s.LevelCount {
+			m.SetCurrentSection(i)
+			m.SetCurrentLevel(n)
+			break
+		}
+		n -= s.LevelCount
+	}
+	return seed
+}
@@ -0,0 +1,124 @@
+package levels
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+	"github.com/zrcoder/icer/internal/levels/sections"
+)
+
+// sourceDir is where the built-in sections live in the source tree,
+// relative to its root
+const sourceDir = "internal/levels/sections"
+
+// dev is set for level designers, see SetDev
+var dev bool
+
+// SetDev turns dev mode on or off. In dev mode the built-in sections are
+// read from the source tree when the game runs from its root, and every
+// section on disk is watched, so edited levels reload without a restart.
+// It must run before levels are loaded.
+func SetDev(on bool) {
+	dev = on
+}
+
+// builtinFS returns the files of the built-in sections and, when they are
+// read from the source tree in dev mode, the folder holding them
+func builtinFS() (fs.FS, string) {
+	if !dev {
+		return sections.FS, ""
+	}
+	if info, err := os.Stat(sourceDir); err != nil || !info.IsDir() {
+		log.Warn("built-in levels not found on disk, using the embedded ones", "dir", sourceDir)
+		return sections.FS, ""
+	}
+	return os.DirFS(sourceDir), sourceDir
+}
+
+// watch starts watching the folder of every section on disk
+func (m *Manager) watch() {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error("cannot watch levels", "err", err)
+		return
+	}
+	for _, s := range m.Sections {
+		if s.folder == "" {
+			continue
+		}
+		if err := w.Add(s.folder); err != nil {
+			log.Error("cannot watch section", "dir", s.folder, "err", err)
+			continue
+		}
+		log.Info("watching levels", "dir", s.folder)
+	}
+	m.watcher = w
+}
+
+// Reload reads again the level files changed on disk since it was last
+// called, keeping the progress made on them, and reports whether the
+// current level was one of them. It does nothing outside dev mode.
+func (m *Manager) Reload() bool {
+	if m.watcher == nil {
+		return false
+	}
+	changed := make(map[string]bool)
+	for done := false; !done; {
+		select {
+		case e := <-m.watcher.Events:
+			if e.Has(fsnotify.Write) || e.Has(fsnotify.Create) || e.Has(fsnotify.Rename) {
+				changed[filepath.Clean(e.Name)] = true
+			}
+		case err := <-m.watcher.Errors:
+			log.Warn("watching levels", "err", err)
+		default:
+			done = true
+		}
+	}
+	current := false
+	for file := range changed {
+		s, i, ok := m.levelAt(file)
+		if !ok {
+			if filepath.Base(file) == "index.toml" {
+				log.Warn("section changed; restart to load it", "file", file)
+			}
+			continue
+		}
+		level, err := s.readLevel(i)
+		if err != nil {
+			// editors saving through a temporary file may not be done yet
+			log.Debug("cannot reload level", "file", file, "err", err)
+			continue
+		}
+		old := s.levels[i]
+		level.Completed, level.GemsFound = old.Completed, min(old.GemsFound, level.Gems())
+		level.Stars = old.Stars
+		m.applyRating(s, level)
+		s.levels[i] = level
+		log.Info("level reloaded", "file", file)
+		if s == m.currentSection && m.currentLevel.ID == i {
+			m.currentLevel = level
+			current = true
+		}
+	}
+	return current
+}
+
+// levelAt finds the section and index of the level saved in file
+func (m *Manager) levelAt(file string) (*Section, int, bool) {
+	n, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".toml"))
+	if err != nil || filepath.Ext(file) != ".toml" {
+		return nil, 0, false
+	}
+	for _, s := range m.Sections {
+		if s.folder != "" && filepath.Clean(s.folder) == filepath.Dir(file) && n >= 1 && n <= len(s.levels) {
+			return s, n - 1, true
+		}
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,143 @@
+package levels
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/profile"
+)
+
+// MaxDifficulty is the score of the hardest levels; the easiest score 1
+const MaxDifficulty = 5
+
+// ratingsFile caches the difficulty of levels rated on this machine
+const ratingsFile = "ratings.toml"
+
+// ratingVersion changes whenever levels are rated differently, so ratings
+// cached by older versions of the game are made again
+const ratingVersion = 2
+
+// Difficulty rates how hard a level is, from its shortest solution. Level
+// files may give one to replace the computed rating.
+type Difficulty struct {
+	// Score goes from 1 to MaxDifficulty; 0 is unrated
+	Score int `toml:"score"`
+	// Moves is the length of the shortest solution
+	Moves int `toml:"moves"`
+	// Branching is the average number of moves open from each position
+	// on the way to the solution
+	Branching float64 `toml:"branching"`
+	// DeadEnds is the share of those moves that leave the level unwinnable
+	DeadEnds float64 `toml:"dead_ends"`
+}
+
+// Rated reports whether d holds a rating
+func (d Difficulty) Rated() bool {
+	return d.Score > 0
+}
+
+// ratingCache is what ratingsFile holds: the ratings made, keyed by
+// ratingKey. Levels that could not be rated have a zero Difficulty, so
+// they are not tried again.
+type ratingCache struct {
+	Levels map[string]Difficulty `toml:"levels"`
+}
+
+// Unrated is a level without a rating yet, copied so it can be rated
+// away from the game loop
+type Unrated struct {
+	Section *Section
+	Level   *Level
+	Key     string
+}
+
+// ratingKey identifies everything the rating of level in s depends on, so
+// a cached rating is dropped once the level or its rules change
+func (s *Section) ratingKey(level *Level) string {
+	if level.ratingKey == "" {
+		h := sha256.New()
+		fmt.Fprintln(h, ratingVersion, level.Grid, level.legend, level.Gravity, level.Links)
+		fmt.Fprintln(h, s.Variants, s.Chains, s.Merge)
+		level.ratingKey = hex.EncodeToString(h.Sum(nil)[:12])
+	}
+	return level.ratingKey
+}
+
+// loadRatings applies the cached ratings to the levels their files don't
+// rate
+func (m *Manager) loadRatings() {
+	m.ratings = make(map[string]Difficulty)
+	data, err := profile.ReadFile(ratingsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var c ratingCache
+	if err == nil {
+		err = toml.Unmarshal(data, &c)
+	}
+	if err != nil {
+		// the cache is only a shortcut; levels are rated again
+		log.Warn("cannot read level ratings", "err", err)
+		return
+	}
+	m.ratings = c.Levels
+	if m.ratings == nil {
+		m.ratings = make(map[string]Difficulty)
+	}
+	for _, s := range m.Sections {
+		for _, level := range s.levels {
+			m.applyRating(s, level)
+		}
+	}
+}
+
+// applyRating gives level in s its cached rating, unless its file rates it
+func (m *Manager) applyRating(s *Section, level *Level) {
+	if d, ok := m.ratings[s.ratingKey(level)]; ok && !level.Difficulty.Rated() {
+		level.Difficulty = d
+	}
+}
+
+// Unrated returns the levels that are neither rated nor known to be
+// unratable, skipping broken ones
+func (m *Manager) Unrated() []Unrated {
+	var res []Unrated
+	for _, s := range m.Sections {
+		for _, level := range s.levels {
+			if level.Difficulty.Rated() || len(level.problems) > 0 {
+				continue
+			}
+			key := s.ratingKey(level)
+			if _, ok := m.ratings[key]; ok {
+				continue
+			}
+			res = append(res, Unrated{Section: s, Level: level.clone(), Key: key})
+		}
+	}
+	return res
+}
+
+// SetDifficulty records the rating made for the levels with key, a zero
+// one marking them as unratable, and caches it
+func (m *Manager) SetDifficulty(key string, d Difficulty) {
+	m.ratings[key] = d
+	for _, s := range m.Sections {
+		for _, level := range s.levels {
+			m.applyRating(s, level)
+		}
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(ratingCache{Levels: m.ratings}); err != nil {
+		log.Warn("cannot save level ratings", "err", err)
+		return
+	}
+	if err := profile.WriteFile(ratingsFile, buf.Bytes()); err != nil {
+		log.Warn("cannot save level ratings", "err", err)
+	}
+}
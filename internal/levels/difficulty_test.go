@@ -0,0 +1,49 @@
+package levels
+
+import (
+	"testing"
+
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// TestUnratedSharesNothing checks the copies handed to the rater share no
+// state the game changes while the level is played
+func TestUnratedSharesNothing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := NewManager()
+	unrated := m.Unrated()
+	if len(unrated) == 0 {
+		t.Skip("every built-in level is rated")
+	}
+	u := unrated[0]
+	var level *Level
+	for _, l := range u.Section.levels {
+		if u.Section.ratingKey(l) == u.Key {
+			level = l
+			break
+		}
+	}
+	if _, _, _, err := level.Build(); err != nil {
+		t.Fatal(err)
+	}
+	level.Discover(utils.Cell{X: 1, Y: 1})
+	level.SetFlag("door")
+	c := m.Unrated()[0].Level
+	if c == level {
+		t.Fatal("rater handed the level itself")
+	}
+	if c.grid != nil {
+		t.Error("copy shares the sprites of the level's last build")
+	}
+
+	level.Discover(utils.Cell{X: 2, Y: 1})
+	level.SetFlag("gate")
+	if c.Discovered(utils.Cell{X: 2, Y: 1}) || c.Flag("gate") {
+		t.Error("copy sees the level played on")
+	}
+	c.Discover(utils.Cell{X: 3, Y: 1})
+	c.SetFlag("bridge")
+	if level.Discovered(utils.Cell{X: 3, Y: 1}) || level.Flag("bridge") {
+		t.Error("level sees its copy changed")
+	}
+}
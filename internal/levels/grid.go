@@ -0,0 +1,65 @@
+package levels
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// commentPrefix starts a grid line that is ignored, for notes to level authors
+const commentPrefix = "//"
+
+// floor pads short rows so every grid is rectangular
+const floor = '.'
+
+var errEmptyGrid = errors.New("grid has no rows")
+
+// parseGrid splits a grid string into rows of runes. Comment lines and
+// blank lines around the grid are dropped, line endings may be \n or \r\n,
+// and short rows are padded with floor so the result is rectangular.
+func parseGrid(grid string) ([][]rune, error) {
+	rows, _, err := splitGrid(grid)
+	if err != nil {
+		return nil, err
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, floor)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// splitGrid returns the rows of grid as parseGrid does, but before
+// padding, along with the line of grid each row comes from, counted from 0
+func splitGrid(grid string) (rows [][]rune, lines []int, err error) {
+	if !utf8.ValidString(grid) {
+		return nil, nil, errors.New("grid is not valid UTF-8")
+	}
+	for i, line := range strings.Split(grid, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.HasPrefix(strings.TrimSpace(line), commentPrefix) {
+			continue
+		}
+		rows = append(rows, []rune(line))
+		lines = append(lines, i)
+	}
+	blank := func(row []rune) bool {
+		return strings.TrimSpace(string(row)) == ""
+	}
+	for len(rows) > 0 && blank(rows[0]) {
+		rows, lines = rows[1:], lines[1:]
+	}
+	for len(rows) > 0 && blank(rows[len(rows)-1]) {
+		rows, lines = rows[:len(rows)-1], lines[:len(lines)-1]
+	}
+	if len(rows) == 0 {
+		return nil, nil, errEmptyGrid
+	}
+	return rows, lines, nil
+}
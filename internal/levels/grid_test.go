@@ -0,0 +1,125 @@
+package levels
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseGrid(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+		want []string
+		err  error
+	}{
+		{
+			name: "rectangular",
+			grid: "#P#\n#I#\n#F#",
+			want: []string{"#P#", "#I#", "#F#"},
+		},
+		{
+			name: "multi-byte runes",
+			grid: "é冰F\nP🔥.",
+			want: []string{"é冰F", "P🔥."},
+		},
+		{
+			name: "ragged rows padded with floor",
+			grid: "#####\n#P\n#I F#",
+			want: []string{"#####", "#P...", "#I F#"},
+		},
+		{
+			name: "ragged multi-byte rows padded by rune",
+			grid: "冰冰冰\n冰",
+			want: []string{"冰冰冰", "冰.."},
+		},
+		{
+			name: "windows line endings",
+			grid: "#P#\r\n#I#\r\n#F#\r\n",
+			want: []string{"#P#", "#I#", "#F#"},
+		},
+		{
+			name: "comment lines dropped",
+			grid: "// start\n#P#\n  // the ice\n#I#",
+			want: []string{"#P#", "#I#"},
+		},
+		{
+			name: "blank edges trimmed",
+			grid: "\n\n   \n#P#\n\n#I#\n \t\n",
+			want: []string{"#P#", "...", "#I#"},
+		},
+		{
+			name: "inner blank line kept",
+			grid: "P\n\nI",
+			want: []string{"P", ".", "I"},
+		},
+		{
+			name: "empty grid",
+			grid: "",
+			err:  errEmptyGrid,
+		},
+		{
+			name: "only blanks and comments",
+			grid: "\n  \n// nothing here\r\n",
+			err:  errEmptyGrid,
+		},
+		{
+			name: "invalid UTF-8",
+			grid: "P\xffI",
+			err:  errAny,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseGrid(tt.grid)
+			switch {
+			case tt.err == errAny:
+				if err == nil {
+					t.Fatalf("parseGrid(%q) succeeded, want an error", tt.grid)
+				}
+				return
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("parseGrid(%q) error = %v, want %v", tt.grid, err, tt.err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("parseGrid(%q): %v", tt.grid, err)
+			}
+			got := make([]string, len(rows))
+			for i, row := range rows {
+				got[i] = string(row)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseGrid(%q) = %q, want %q", tt.grid, got, tt.want)
+			}
+		})
+	}
+}
+
+// errAny marks a test case expecting some error
+var errAny = errors.New("any error")
+
+func TestSplitGridLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		grid  string
+		lines []int
+	}{
+		{"plain", "P\nI\nF", []int{0, 1, 2}},
+		{"leading blank lines", "\n\nP\nI", []int{2, 3}},
+		{"comments skipped", "P\n// note\nI", []int{0, 2}},
+		{"windows line endings", "\r\nP\r\nI\r\n", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, lines, err := splitGrid(tt.grid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(lines, tt.lines) {
+				t.Errorf("splitGrid(%q) lines = %v, want %v", tt.grid, lines, tt.lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package levels
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Sprite kinds a legend can map grid characters to
+const (
+	KindFloor    = "floor"
+	KindPlayer   = "player"
+	KindWall     = "wall"
+	KindIce      = "ice"
+	KindStone    = "stone"
+	KindFlame    = "flame"
+	KindPot      = "pot"
+	KindGem      = "gem"
+	KindCoin     = "coin"
+	KindNPC      = "npc"
+	KindFakeWall = "fakewall"
+	KindPortal   = "portal"
+	KindCracked  = "cracked"
+	KindPlate    = "plate"
+	KindToggle   = "toggle"
+	KindEnemy    = "enemy"
+	KindWater    = "water"
+	KindBomb     = "bomb"
+	KindSticky   = "sticky"
+	KindLever    = "lever"
+	KindPhase    = "phase"
+	// KindPhaseAlt is a phase wall that starts out passable
+	KindPhaseAlt = "phase-alt"
+	// KindCrackedWall is a wall bombs can destroy
+	KindCrackedWall = "crackedwall"
+	// KindToggleOpen is an inverted toggle wall, open until pressed
+	KindToggleOpen = "toggle-open"
+
+	KindKeyRed     = "key-red"
+	KindKeyBlue    = "key-blue"
+	KindKeyGreen   = "key-green"
+	KindKeyYellow  = "key-yellow"
+	KindDoorRed    = "door-red"
+	KindDoorBlue   = "door-blue"
+	KindDoorGreen  = "door-green"
+	KindDoorYellow = "door-yellow"
+
+	KindConveyorUp    = "conveyor-up"
+	KindConveyorDown  = "conveyor-down"
+	KindConveyorLeft  = "conveyor-left"
+	KindConveyorRight = "conveyor-right"
+
+	KindOneWayUp    = "oneway-up"
+	KindOneWayDown  = "oneway-down"
+	KindOneWayLeft  = "oneway-left"
+	KindOneWayRight = "oneway-right"
+
+	KindFanUp    = "fan-up"
+	KindFanDown  = "fan-down"
+	KindFanLeft  = "fan-left"
+	KindFanRight = "fan-right"
+)
+
+var kinds = map[string]bool{
+	KindFloor: true, KindPlayer: true, KindWall: true, KindIce: true, KindStone: true,
+	KindFlame: true, KindPot: true, KindGem: true, KindCoin: true, KindNPC: true, KindFakeWall: true, KindPortal: true,
+	KindCracked: true, KindConveyorUp: true, KindConveyorDown: true, KindConveyorLeft: true, KindConveyorRight: true,
+	KindOneWayUp: true, KindOneWayDown: true, KindOneWayLeft: true, KindOneWayRight: true,
+	KindFanUp: true, KindFanDown: true, KindFanLeft: true, KindFanRight: true,
+	KindPlate: true, KindToggle: true, KindToggleOpen: true, KindEnemy: true, KindWater: true,
+	KindBomb: true, KindCrackedWall: true, KindSticky: true,
+	KindLever: true, KindPhase: true, KindPhaseAlt: true,
+	KindKeyRed: true, KindKeyBlue: true, KindKeyGreen: true, KindKeyYellow: true,
+	KindDoorRed: true, KindDoorBlue: true, KindDoorGreen: true, KindDoorYellow: true,
+}
+
+// conveyors maps each conveyor kind to the way its belt runs
+var conveyors = map[string]utils.Direction{
+	KindConveyorUp:    utils.Up,
+	KindConveyorRight: utils.Right,
+	KindConveyorDown:  utils.Down,
+	KindConveyorLeft:  utils.Left,
+}
+
+// oneWays maps each one-way kind to the way its arrow points
+var oneWays = map[string]utils.Direction{
+	KindOneWayUp:    utils.Up,
+	KindOneWayRight: utils.Right,
+	KindOneWayDown:  utils.Down,
+	KindOneWayLeft:  utils.Left,
+}
+
+// fans maps each fan kind to the way it blows
+var fans = map[string]utils.Direction{
+	KindFanUp:    utils.Up,
+	KindFanRight: utils.Right,
+	KindFanDown:  utils.Down,
+	KindFanLeft:  utils.Left,
+}
+
+// directional lists the families of kinds that differ only by direction,
+// which transforms turn along with the grid
+var directional = []map[string]utils.Direction{conveyors, oneWays, fans}
+
+// keys and doors map the kinds of keys and doors to their colors
+var (
+	keys = map[string]sprites.KeyColor{
+		KindKeyRed: sprites.KeyRed, KindKeyBlue: sprites.KeyBlue,
+		KindKeyGreen: sprites.KeyGreen, KindKeyYellow: sprites.KeyYellow,
+	}
+	doors = map[string]sprites.KeyColor{
+		KindDoorRed: sprites.KeyRed, KindDoorBlue: sprites.KeyBlue,
+		KindDoorGreen: sprites.KeyGreen, KindDoorYellow: sprites.KeyYellow,
+	}
+)
+
+// defaultLegend is the alphabet used where a pack or level does not say
+// otherwise. Characters missing from every legend become portals, paired
+// by character.
+var defaultLegend = map[rune]string{
+	'M': KindPlayer,
+	'#': KindWall,
+	'I': KindIce,
+	'S': KindStone,
+	'B': KindBomb,
+	'X': KindCrackedWall,
+	'F': KindFlame,
+	'P': KindPot,
+	'G': KindGem,
+	'o': KindCoin,
+	'N': KindNPC,
+	'E': KindEnemy,
+	'H': KindFakeWall,
+	'^': KindConveyorUp,
+	'v': KindConveyorDown,
+	'<': KindConveyorLeft,
+	'>': KindConveyorRight,
+	'↑': KindOneWayUp,
+	'↓': KindOneWayDown,
+	'←': KindOneWayLeft,
+	'→': KindOneWayRight,
+	'⇑': KindFanUp,
+	'⇓': KindFanDown,
+	'⇐': KindFanLeft,
+	'⇒': KindFanRight,
+	'%': KindCracked,
+	',': KindSticky,
+	'~': KindWater,
+	'_': KindPlate,
+	'=': KindToggle,
+	':': KindToggleOpen,
+	'L': KindLever,
+	'|': KindPhase,
+	'!': KindPhaseAlt,
+	'k': KindKeyRed,
+	'd': KindDoorRed,
+	'.': KindFloor,
+	' ': KindFloor,
+}
+
+// buildLegend layers the given [legend] tables over the default alphabet,
+// later tables taking precedence. Keys must be single characters, in any
+// script, and values known sprite kinds.
+func buildLegend(tables ...map[string]string) (map[rune]string, error) {
+	res := make(map[rune]string, len(defaultLegend))
+	for char, kind := range defaultLegend {
+		res[char] = kind
+	}
+	for _, table := range tables {
+		for key, kind := range table {
+			char, size := utf8.DecodeRuneInString(key)
+			if char == utf8.RuneError || size != len(key) {
+				return res, fmt.Errorf("legend key %q must be a single character", key)
+			}
+			if !kinds[kind] {
+				return res, fmt.Errorf("legend maps %q to unknown kind %q", key, kind)
+			}
+			res[char] = kind
+		}
+	}
+	return res, nil
+}
+
+// kind returns the sprite kind char stands for in the level
+func (l *Level) kind(char rune) string {
+	legend := l.legend
+	if legend == nil {
+		legend = defaultLegend
+	}
+	if kind, ok := legend[char]; ok {
+		return kind
+	}
+	if _, ok := custom[char]; ok {
+		return ""
+	}
+	return KindPortal
+}
+
+// char returns the character standing for kind in the level, preferring
+// the lowest one when several do
+func (l *Level) char(kind string) (rune, bool) {
+	legend := l.legend
+	if legend == nil {
+		legend = defaultLegend
+	}
+	var res rune
+	found := false
+	for char, k := range legend {
+		if k == kind && (!found || char < res) {
+			res, found = char, true
+		}
+	}
+	return res, found
+}
@@ -2,44 +2,176 @@ package levels
 
 import (
 	"fmt"
-	"strings"
-
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
 	"strconv"
 
 	"github.com/BurntSushi/toml"
 	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
 	"github.com/zrcoder/icer/internal/levels/sections"
 	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
 )
 
 type Section struct {
 	Meta
 	LevelCount int `toml:"levels"`
-	levels     []*Level
+	BonusCount int `toml:"bonus"`
+	Lives      int `toml:"lives"`
+	// LivesLost counts the lives spent in challenge mode and not yet
+	// refilled
+	LivesLost int      `toml:"-"`
+	Map       [][2]int `toml:"map"` // campaign map position of each level, in pixels
+	// Chains makes ice pushed into another block of ice hand the push on,
+	// setting that block sliding, instead of stopping
+	Chains bool `toml:"chains"`
+	// Merge fuses ice pushed up against another block of ice into one
+	// 1x2 block
+	Merge bool `toml:"merge"`
+	// Variants lists the rule variants the section's levels play by
+	Variants []string `toml:"variants"`
+	// Ambience is the background loop of the section's levels, such as
+	// "wind", "cave" or "lava"
+	Ambience string `toml:"ambience"`
+	// Legend maps grid characters to sprite kinds for every level of the
+	// section, on top of the default alphabet
+	Legend map[string]string `toml:"legend"`
+	// Gate is how many stars must be earned in the sections before this
+	// one to unlock it
+	Gate int `toml:"gate"`
+	// Pack names the external pack the section was loaded from, and is
+	// empty for the sections built into the game
+	Pack   string `toml:"-"`
+	levels []*Level
+	// fsys holds the section's files in dir
+	fsys fs.FS
+	dir  string
+	// folder is where the section's files are on disk, or "" when they
+	// are built in
+	folder string
 }
 
+const defaultLives = 3
+
 type Level struct {
 	Meta
-	Grid    string `toml:"grid"`
+	// UUID keeps the level's identity, and the player's progress on it,
+	// through edits to the level
+	UUID      string `toml:"uuid"`
+	Grid      string `toml:"grid"`
+	Completed bool   `toml:"-"`
+	GemsFound int    `toml:"-"`
+	Stars     int    `toml:"-"` // most stars earned on the level
+	NPCs      []NPC  `toml:"npc"`
+	Refreeze  int    `toml:"refreeze"`   // ticks before melted ice freezes again on its pot; 0 never
+	Par       int    `toml:"par"`        // moves needed by the best known solution; 0 unrated
+	Gravity   bool   `toml:"gravity"`    // side view: the player and ice fall
+	TimeLimit int    `toml:"time_limit"` // seconds to clear the level; 0 untimed
+	MaxMoves  int    `toml:"max_moves"`  // moves allowed to clear the level; 0 unlimited
+	// Difficulty rates the level; when the file leaves it out, it is
+	// computed from the level's solution and cached in the profile
+	Difficulty Difficulty `toml:"difficulty"`
+	// Ambience replaces the section's background loop for this level
+	Ambience string `toml:"ambience"`
+	// PortalExit is what sliding ice does on coming out of a portal:
+	// "keep" sliding (the default), "reverse" or "stop"
+	PortalExit string `toml:"portal_exit"`
+	// Legend maps grid characters to sprite kinds, over the section's legend
+	Legend map[string]string `toml:"legend"`
+	// Links wire pressure plates and flags to toggle walls; without any,
+	// every plate switches every toggle wall
+	Links []Link `toml:"links"`
+	// Enemies sets how the enemies in the grid move; enemies without an
+	// entry chase the player
+	Enemies []Enemy `toml:"enemies"`
+	legend  map[rune]string
 	grid    [][]sprites.Sprite
 	portals map[rune][]*sprites.Portal
+	npcs    []*sprites.NPC
+	plates  []*sprites.Plate
+	toggles []*sprites.ToggleWall
+	links   map[*sprites.Plate][]*sprites.ToggleWall
+	gates   map[string][]*sprites.ToggleWall
+	enemies []*sprites.Enemy
+	flags   map[string]bool
+	// discovered marks the secret cells the player has already walked into
+	discovered map[utils.Cell]bool
+	// file is where the level was loaded from, with its grid starting on
+	// gridLine, and problems the mistakes found in it
+	file     string
+	gridLine int
+	problems []Diagnostic
+	// ratingKey caches the section's ratingKey for the level
+	ratingKey string
+}
+
+// Link wires the pressure plate in one cell to the toggle walls in others.
+// Cells are given as [column, row], counted from 0 at the top left of the
+// grid. A link naming a Flag has no plate: its walls switch once the flag
+// is raised, such as by a dialog choice.
+type Link struct {
+	Plate [2]int   `toml:"plate"`
+	Flag  string   `toml:"flag"`
+	Walls [][2]int `toml:"walls"`
+}
+
+// Enemy sets how the enemy in one cell, given as [column, row], moves:
+// along a patrol spelled in direction letters such as "RRLL", looping back
+// to its start, or chasing the player. Cooldown is how many ticks it waits
+// between steps; 0 picks the default.
+type Enemy struct {
+	At       [2]int `toml:"at"`
+	Patrol   string `toml:"patrol"`
+	Chase    bool   `toml:"chase"`
+	Cooldown int    `toml:"cooldown"`
+}
+
+// NPC holds the dialog of one 'N' tile, matched to tiles in reading order
+type NPC struct {
+	Pages   []string `toml:"pages"`
+	Choices []Choice `toml:"choices"`
+}
+
+// Choice is an answer offered after the last dialog page; picking it sets Flag
+type Choice struct {
+	Text string `toml:"text"`
+	Flag string `toml:"flag"`
 }
 
 type Meta struct {
 	ID          int    `toml:"-"`
 	Title       string `toml:"title"`
 	Description string `toml:"description"`
+	// Translations hold the text in other languages, keyed by locale
+	Translations map[string]Translation `toml:"translations"`
 }
 
 type Manager struct {
 	Sections       []*Section
 	currentLevel   *Level
 	currentSection *Section
+	// notice is left for the player when loading progress went wrong
+	notice string
+	// frozen keeps progress this game can't read from being saved over
+	frozen bool
+	// hardcore is set once the profile is played without undo or hints
+	hardcore bool
+	// watcher reports level files changing in dev mode
+	watcher *fsnotify.Watcher
+	// ratings are the cached level ratings, by ratingKey
+	ratings map[string]Difficulty
 }
 
 func NewManager() *Manager {
 	m := &Manager{}
 	m.load()
+	m.loadProgress()
+	if dev {
+		m.watch()
+	}
 	log.Debug("levels loaded",
 		"sections", len(m.Sections),
 		"section", m.currentSection,
@@ -56,6 +188,57 @@ func (m *Manager) SetCurrentLevel(i int) {
 	m.currentLevel = m.currentSection.levels[i]
 }
 
+// RandomizeCurrentLevel replaces the current level with a copy transformed
+// by a transform picked from seed, leaving the section's original untouched.
+// Gravity levels are never turned, as that would change which way is down;
+// the seed only decides whether they are mirrored.
+func (m *Manager) RandomizeCurrentLevel(seed uint64) Transform {
+	l, t := m.RandomizedCurrentLevel(seed)
+	m.currentLevel = l
+	return t
+}
+
+// RandomizedCurrentLevel returns the copy of the current level
+// RandomizeCurrentLevel would play for seed, and its transform, leaving
+// the current level as it is. The copy shares nothing with the game, so
+// it can be built and solved away from the game loop.
+func (m *Manager) RandomizedCurrentLevel(seed uint64) (*Level, Transform) {
+	t := RandomTransform(seed)
+	if m.currentLevel.Gravity {
+		t = Transform{Mirror: t.Mirror}
+	}
+	return m.currentSection.levels[m.currentLevel.ID].Transform(t), t
+}
+
+// CompleteCurrentLevel marks the current level as completed, keeping the
+// most stars earned on it
+func (m *Manager) CompleteCurrentLevel(stars int) {
+	level := m.currentSection.levels[m.currentLevel.ID]
+	level.Completed = true
+	level.Stars = max(level.Stars, stars)
+	m.saveProgress()
+}
+
+// CollectGem records a secret gem found in the current level
+func (m *Manager) CollectGem() {
+	level := m.currentSection.levels[m.currentLevel.ID]
+	level.GemsFound = min(level.GemsFound+1, level.Gems())
+	m.saveProgress()
+}
+
+// DiscoverCell records a secret cell found in the current level. Cells
+// found on a randomized copy of the level are not saved, as they sit
+// elsewhere in the level's own grid.
+func (m *Manager) DiscoverCell(pos utils.Cell) {
+	if m.currentLevel.Discovered(pos) {
+		return
+	}
+	m.currentLevel.Discover(pos)
+	if m.currentLevel == m.currentSection.levels[m.currentLevel.ID] {
+		m.saveProgress()
+	}
+}
+
 func (m *Manager) CurrentSection() *Section {
 	return m.currentSection
 }
@@ -64,96 +247,406 @@ func (m *Manager) CurrentLevel() *Level {
 }
 
 func (m *Manager) load() {
-	m.Sections = make([]*Section, sections.Count)
-	for i := range m.Sections {
-		m.Sections[i] = m.loadSection(i)
-		m.Sections[i].loadLevels()
-		log.Debug("section loaded", "id", i, "title", m.Sections[i].Title, "levels", m.Sections[i].LevelCount)
+	fsys, folder := builtinFS()
+	for i := range sections.Count {
+		s, err := loadSection(fsys, strconv.Itoa(i+1), len(m.Sections))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if folder != "" {
+			s.folder = filepath.Join(folder, s.dir)
+		}
+		m.add(s)
 	}
+	m.loadPacks()
+	m.loadRatings()
 	m.SetCurrentSection(0)
 }
 
-func (m *Manager) loadSection(section int) *Section {
-	indexPath := strconv.Itoa(section+1) + "/index.toml"
-	indexData, err := sections.FS.ReadFile(indexPath)
+// add appends s to the section list
+func (m *Manager) add(s *Section) {
+	m.Sections = append(m.Sections, s)
+	log.Debug("section loaded", "id", s.ID, "title", s.Title, "levels", s.LevelCount)
+}
+
+// loadSection reads the section in dir of fsys, with its index.toml and
+// levels, as section id
+func loadSection(fsys fs.FS, dir string, id int) (*Section, error) {
+	indexData, err := fs.ReadFile(fsys, path.Join(dir, "index.toml"))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	res := &Section{}
+	res := &Section{fsys: fsys, dir: dir}
 	if err := toml.Unmarshal(indexData, res); err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("%s: %w", path.Join(dir, "index.toml"), err)
 	}
-	res.ID = section
-	return res
+	res.ID = id
+	res.localize()
+	if err := res.checkVariants(); err != nil {
+		log.Error("bad section variants", "section", id+1, "err", err)
+	}
+	if res.Lives <= 0 {
+		res.Lives = defaultLives
+	}
+	return res, res.loadLevels()
 }
 
-func (s *Section) loadLevels() {
-	s.levels = make([]*Level, s.LevelCount)
-	for i := range s.LevelCount {
-		data, err := sections.FS.ReadFile(fmt.Sprintf("%d/%d.toml", s.ID+1, i+1))
-		if err != nil {
-			log.Fatal(err)
-		}
-		var level = &Level{}
-		err = toml.Unmarshal(data, &level)
+// StableID identifies the section across sessions, by its pack and folder
+func (s *Section) StableID() string {
+	return path.Join(s.Pack, s.dir)
+}
+
+// SetLivesLost records the lives spent in section s in challenge mode,
+// keeping between none and all of them
+func (m *Manager) SetLivesLost(s *Section, n int) {
+	n = min(max(n, 0), s.Lives)
+	if n == s.LivesLost {
+		return
+	}
+	s.LivesLost = n
+	m.saveProgress()
+}
+
+// Level returns the level at index i
+func (s *Section) Level(i int) *Level {
+	return s.levels[i]
+}
+
+// BonusUnlocked reports whether every secret gem in the section's regular
+// levels has been found
+func (s *Section) BonusUnlocked() bool {
+	total, found := 0, 0
+	for _, level := range s.levels[:s.LevelCount] {
+		total += level.Gems()
+		found += level.GemsFound
+	}
+	return total > 0 && found == total
+}
+
+// VisibleLevels returns the number of playable levels, counting the bonus
+// levels once they are unlocked
+func (s *Section) VisibleLevels() int {
+	if s.BonusUnlocked() {
+		return s.LevelCount + s.BonusCount
+	}
+	return s.LevelCount
+}
+
+func (s *Section) loadLevels() error {
+	s.levels = make([]*Level, s.LevelCount+s.BonusCount)
+	for i := range s.levels {
+		level, err := s.readLevel(i)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		level.ID = i
-		log.Debug("level loaded", "id", i, "title", level.Title)
 		s.levels[i] = level
 	}
+	return nil
 }
 
-func (s *Section) loadLevel(id int) Level {
-	levelPath := strconv.Itoa(s.Meta.ID) + "/" + strconv.Itoa(id) + ".toml"
-	levelData, err := sections.FS.ReadFile(levelPath)
+// readLevel reads the level at index i from the section's files. Only a
+// file that can't be read is an error; mistakes in it are kept as the
+// level's problems.
+func (s *Section) readLevel(i int) (*Level, error) {
+	name := path.Join(s.dir, fmt.Sprintf("%d.toml", i+1))
+	data, err := fs.ReadFile(s.fsys, name)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	level := &Level{file: name}
+	if err := toml.Unmarshal(data, level); err != nil {
+		// keep the level's place so the rest of the section loads
+		level = &Level{Meta: Meta{Title: "Broken level"}, file: name}
+		level.problems = []Diagnostic{parseDiagnostic(name, err)}
+	} else {
+		level.gridLine = gridLine(data, level.Grid)
+		if level.legend, err = buildLegend(s.Legend, level.Legend); err != nil {
+			level.problems = append(level.problems, Diagnostic{File: name, Msg: err.Error()})
+		}
+		level.problems = append(level.problems, level.Validate()...)
+		for _, msg := range level.checkTranslations() {
+			log.Warn("bad translation", "file", name, "problem", msg)
+		}
+		level.localize()
+	}
+	level.ID = i
+	for _, d := range level.problems {
+		log.Error("bad level", "problem", d)
 	}
+	log.Debug("level loaded", "id", i, "title", level.Title)
+	return level, nil
+}
+
+// Gems returns the number of secret gems placed in the level
+func (l *Level) Gems() int {
+	rows, _ := parseGrid(l.Grid)
+	n := 0
+	for _, row := range rows {
+		for _, ch := range row {
+			if l.kind(ch) == KindGem {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Dialog returns the dialog spoken by npc
+func (l *Level) Dialog(npc *sprites.NPC) NPC {
+	if npc.Index >= len(l.NPCs) {
+		return NPC{}
+	}
+	return l.NPCs[npc.Index]
+}
 
-	var level Level
-	if err := toml.Unmarshal(levelData, &level); err != nil {
-		log.Fatal(err)
+// SetFlag raises a runtime flag, such as one picked in a dialog choice.
+// Flags stay raised when the level is built again.
+func (l *Level) SetFlag(name string) {
+	if l.flags == nil {
+		l.flags = make(map[string]bool)
 	}
+	l.flags[name] = true
+}
 
-	level.regular()
+// Flag reports whether the runtime flag name has been raised
+func (l *Level) Flag(name string) bool {
+	return l.flags[name]
+}
 
-	return level
+// SpriteTypes returns the distinct kinds of sprites placed in the level
+func (l *Level) SpriteTypes() []string {
+	probe := &Level{portals: make(map[rune][]*sprites.Portal), legend: l.legend}
+	seen := make(map[string]bool)
+	var res []string
+	rows, _ := parseGrid(l.Grid)
+	for _, row := range rows {
+		for _, ch := range row {
+			obj := probe.createObject(ch, 0, 0)
+			if obj == nil || seen[obj.Type()] {
+				continue
+			}
+			seen[obj.Type()] = true
+			res = append(res, obj.Type())
+		}
+	}
+	return res
+}
+
+// Portals returns the portals placed by the last Build, keyed by ID in
+// reading order
+func (l *Level) Portals() map[rune][]*sprites.Portal {
+	return l.portals
+}
+
+// Discover marks the secret cell at pos as found
+func (l *Level) Discover(pos utils.Cell) {
+	if l.discovered == nil {
+		l.discovered = make(map[utils.Cell]bool)
+	}
+	l.discovered[pos] = true
+}
+
+// Discovered reports whether the secret cell at pos has been found
+func (l *Level) Discovered(pos utils.Cell) bool {
+	return l.discovered[pos]
+}
+
+// clone returns a copy of the level that shares nothing the game changes
+// while playing it: runtime state is copied and the sprites of the last
+// Build are left out, for the copy to build its own
+func (l *Level) clone() *Level {
+	res := *l
+	res.flags = maps.Clone(l.flags)
+	res.discovered = maps.Clone(l.discovered)
+	res.grid, res.portals, res.npcs = nil, nil, nil
+	res.plates, res.toggles, res.links, res.gates, res.enemies = nil, nil, nil, nil, nil
+	return &res
+}
+
+// Build parses the grid into a fresh set of sprites, so the level can be
+// played from its initial state, and returns them with the grid size
+func (l *Level) Build() (objects []sprites.Sprite, width, height int, err error) {
+	if err := l.regular(); err != nil {
+		return nil, 0, 0, fmt.Errorf("level %d %q: %w", l.ID+1, l.Title, err)
+	}
+	for _, row := range l.grid {
+		for _, obj := range row {
+			if obj != nil {
+				objects = append(objects, obj)
+			}
+		}
+	}
+	return objects, len(l.grid[0]), len(l.grid), nil
 }
 
-func (l *Level) regular() {
+func (l *Level) regular() error {
+	rows, err := parseGrid(l.Grid)
+	if err != nil {
+		return err
+	}
 	l.portals = make(map[rune][]*sprites.Portal)
-	lines := strings.Split(l.Grid, "\n")
-	l.grid = make([][]sprites.Sprite, len(lines))
-	for i, line := range lines {
-		l.grid[i] = make([]sprites.Sprite, len(line))
-		for j, ch := range line {
-			l.grid[i][j] = l.createObject(ch, i, j)
+	l.npcs = nil
+	l.plates, l.toggles = nil, nil
+	l.enemies = nil
+	l.grid = make([][]sprites.Sprite, len(rows))
+	for i, row := range rows {
+		l.grid[i] = make([]sprites.Sprite, len(row))
+		for j, ch := range row {
+			l.grid[i][j] = l.createObject(ch, j, i)
+		}
+	}
+	if err := l.link(); err != nil {
+		return err
+	}
+	return l.arm()
+}
+
+// arm applies the level's enemy entries to the enemies in the grid
+func (l *Level) arm() error {
+	for _, enemy := range l.enemies {
+		enemy.Chase = true
+	}
+	for _, def := range l.Enemies {
+		x, y := def.At[0], def.At[1]
+		var enemy *sprites.Enemy
+		if y >= 0 && y < len(l.grid) && x >= 0 && x < len(l.grid[y]) {
+			enemy, _ = l.grid[y][x].(*sprites.Enemy)
+		}
+		if enemy == nil {
+			return fmt.Errorf("enemy %v is not an enemy", def.At)
 		}
+		patrol, ok := utils.ParseDirections(def.Patrol)
+		if !ok {
+			return fmt.Errorf("enemy %v has a bad patrol %q", def.At, def.Patrol)
+		}
+		enemy.Patrol = patrol
+		enemy.Chase = def.Chase
+		enemy.Cooldown = def.Cooldown
 	}
+	return nil
+}
+
+// link resolves the level's links between pressure plates and toggle walls
+func (l *Level) link() error {
+	l.links = make(map[*sprites.Plate][]*sprites.ToggleWall)
+	l.gates = make(map[string][]*sprites.ToggleWall)
+	if len(l.Links) == 0 {
+		for _, plate := range l.plates {
+			l.links[plate] = l.toggles
+		}
+		return nil
+	}
+	at := func(cell [2]int) sprites.Sprite {
+		x, y := cell[0], cell[1]
+		if y < 0 || y >= len(l.grid) || x < 0 || x >= len(l.grid[y]) {
+			return nil
+		}
+		return l.grid[y][x]
+	}
+	for _, link := range l.Links {
+		var walls []*sprites.ToggleWall
+		for _, cell := range link.Walls {
+			wall, ok := at(cell).(*sprites.ToggleWall)
+			if !ok {
+				return fmt.Errorf("link wall %v is not a toggle wall", cell)
+			}
+			walls = append(walls, wall)
+		}
+		if link.Flag != "" {
+			l.gates[link.Flag] = append(l.gates[link.Flag], walls...)
+			continue
+		}
+		plate, ok := at(link.Plate).(*sprites.Plate)
+		if !ok {
+			return fmt.Errorf("link plate %v is not a pressure plate", link.Plate)
+		}
+		l.links[plate] = append(l.links[plate], walls...)
+	}
+	return nil
+}
+
+// Switches returns the toggle walls each pressure plate placed by the last
+// Build switches
+func (l *Level) Switches() map[*sprites.Plate][]*sprites.ToggleWall {
+	return l.links
+}
+
+// Gates returns the toggle walls each flag switches, among those placed
+// by the last Build
+func (l *Level) Gates() map[string][]*sprites.ToggleWall {
+	return l.gates
 }
 
 func (l *Level) createObject(char rune, x, y int) sprites.Sprite {
-	switch char {
-	case 'M':
+	switch l.kind(char) {
+	case KindPlayer:
 		return sprites.NewPlayer(x, y)
-	case '#':
+	case KindWall:
 		return sprites.NewWall(x, y)
-	case 'I':
+	case KindIce:
 		return sprites.NewIce(x, y)
-	case 'S':
+	case KindStone:
 		return sprites.NewStone(x, y)
-	case 'F':
+	case KindBomb:
+		return sprites.NewBomb(x, y)
+	case KindCrackedWall:
+		return sprites.NewCrackedWall(x, y)
+	case KindFlame:
 		return sprites.NewFlame(x, y)
-	case 'P':
+	case KindPot:
 		return sprites.NewPot(x, y)
-	case '.':
+	case KindGem:
+		return sprites.NewGem(x, y)
+	case KindCoin:
+		return sprites.NewCoin(x, y)
+	case KindNPC:
+		npc := sprites.NewNPC(x, y, len(l.npcs))
+		l.npcs = append(l.npcs, npc)
+		return npc
+	case KindEnemy:
+		enemy := sprites.NewEnemy(x, y)
+		l.enemies = append(l.enemies, enemy)
+		return enemy
+	case KindFakeWall:
+		wall := sprites.NewFakeWall(x, y)
+		wall.Revealed = l.Discovered(wall.Position())
+		return wall
+	case KindCracked:
+		return sprites.NewCrackedFloor(x, y)
+	case KindSticky:
+		return sprites.NewStickyFloor(x, y)
+	case KindLever:
+		return sprites.NewLever(x, y)
+	case KindPhase, KindPhaseAlt:
+		return sprites.NewPhaseWall(x, y, l.kind(char) == KindPhaseAlt)
+	case KindWater:
+		return sprites.NewWater(x, y)
+	case KindConveyorUp, KindConveyorDown, KindConveyorLeft, KindConveyorRight:
+		return sprites.NewConveyor(x, y, conveyors[l.kind(char)])
+	case KindOneWayUp, KindOneWayDown, KindOneWayLeft, KindOneWayRight:
+		return sprites.NewOneWay(x, y, oneWays[l.kind(char)])
+	case KindFanUp, KindFanDown, KindFanLeft, KindFanRight:
+		return sprites.NewFan(x, y, fans[l.kind(char)])
+	case KindPlate:
+		plate := sprites.NewPlate(x, y)
+		l.plates = append(l.plates, plate)
+		return plate
+	case KindToggle, KindToggleOpen:
+		wall := sprites.NewToggleWall(x, y, l.kind(char) == KindToggleOpen)
+		l.toggles = append(l.toggles, wall)
+		return wall
+	case KindKeyRed, KindKeyBlue, KindKeyGreen, KindKeyYellow:
+		return sprites.NewKey(x, y, keys[l.kind(char)])
+	case KindDoorRed, KindDoorBlue, KindDoorGreen, KindDoorYellow:
+		return sprites.NewDoor(x, y, doors[l.kind(char)])
+	case KindFloor:
 		return nil
-	default:
+	case KindPortal:
 		portal := sprites.NewPortal(char, x, y)
 		l.portals[char] = append(l.portals[char], portal)
 		return portal
+	default:
+		return custom[char](x, y)
 	}
 }
@@ -0,0 +1,70 @@
+package levels
+
+import "testing"
+
+func TestFlagGates(t *testing.T) {
+	level := &Level{
+		Grid: `
+#####
+#P_=#
+#  =#
+#####`,
+		Links: []Link{
+			{Plate: [2]int{2, 1}, Walls: [][2]int{{3, 1}}},
+			{Flag: "gate", Walls: [][2]int{{3, 2}}},
+		},
+	}
+	if _, _, _, err := level.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if len(level.Switches()) != 1 {
+		t.Fatalf("plates linked = %d, want 1", len(level.Switches()))
+	}
+	walls := level.Gates()["gate"]
+	if len(walls) != 1 || walls[0].Position().X != 3 || walls[0].Position().Y != 2 {
+		t.Fatalf("walls gated by the flag = %v, want the one in [3, 2]", walls)
+	}
+
+	level.SetFlag("gate")
+	if _, _, _, err := level.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if !level.Flag("gate") {
+		t.Error("flag lowered by building the level again")
+	}
+
+	turned := level.Transform(Transform{Turns: 1})
+	if _, _, _, err := turned.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if len(turned.Gates()["gate"]) != 1 {
+		t.Error("flag link lost when transforming the level")
+	}
+}
+
+func TestFlagLinkBadWall(t *testing.T) {
+	level := &Level{
+		Grid:  "P=",
+		Links: []Link{{Flag: "gate", Walls: [][2]int{{0, 0}}}},
+	}
+	if _, _, _, err := level.Build(); err == nil {
+		t.Error("flag linked to a cell without a toggle wall")
+	}
+}
+
+func TestRandomizeGravityLevel(t *testing.T) {
+	level := &Level{Grid: "P I\n###", Gravity: true}
+	m := &Manager{Sections: []*Section{{levels: []*Level{level}}}}
+	m.SetCurrentSection(0)
+	mirrored := make(map[bool]bool)
+	for seed := range uint64(32) {
+		tr := m.RandomizeCurrentLevel(seed)
+		if tr.Turns%4 != 0 {
+			t.Fatalf("seed %d turned a gravity level: %v", seed, tr)
+		}
+		mirrored[tr.Mirror] = true
+	}
+	if !mirrored[true] || !mirrored[false] {
+		t.Error("seeds don't decide whether gravity levels are mirrored")
+	}
+}
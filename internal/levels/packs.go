@@ -0,0 +1,71 @@
+package levels
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/levels/sections"
+	"github.com/zrcoder/icer/internal/profile"
+)
+
+// packsDir is where external level packs are looked for; empty means the
+// levels folder of the profile
+var packsDir string
+
+// SetPacksDir makes the manager look for external level packs in dir
+// instead of the profile's levels folder. It must run before levels are
+// loaded.
+func SetPacksDir(dir string) {
+	packsDir = dir
+}
+
+// PacksDir returns the folder external level packs are loaded from
+func PacksDir() (string, error) {
+	if packsDir != "" {
+		return packsDir, nil
+	}
+	return profile.Path("levels")
+}
+
+// builtin returns the sections built into the game, which come before
+// any pack's
+func (m *Manager) builtin() []*Section {
+	return m.Sections[:sections.Count]
+}
+
+// loadPacks adds a section after the built-in ones for every external
+// pack: each folder of the packs folder holding an index.toml and level
+// files laid out like the built-in sections. Packs load in name order.
+// A pack that fails to load is left out rather than stopping the game.
+func (m *Manager) loadPacks() {
+	dir, err := PacksDir()
+	if err != nil {
+		log.Warn("cannot locate level packs", "err", err)
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Error("cannot read level packs", "dir", dir, "err", err)
+		}
+		return
+	}
+	fsys := os.DirFS(dir)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := loadSection(fsys, e.Name(), len(m.Sections))
+		if err != nil {
+			log.Error("cannot load level pack", "pack", e.Name(), "err", err)
+			continue
+		}
+		s.Pack = e.Name()
+		s.folder = filepath.Join(dir, e.Name())
+		m.add(s)
+		log.Info("level pack loaded", "pack", s.Pack, "title", s.Title)
+	}
+}
@@ -0,0 +1,298 @@
+package levels
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/profile"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// progressFile is the profile file progress is saved to
+const progressFile = "progress.toml"
+
+// progressMigrations upgrade progress saved by older versions of the game,
+// one format version each; the current version is their number. To change
+// the format, append a migration rewriting the previous version's keys.
+var progressMigrations = []profile.Migration{
+	// 0 is a file without a version, read as the first saved format
+	func(map[string]any) error { return nil },
+	// 1 didn't keep stars; levels completed then count one, so sections
+	// gated on stars open as they did
+	migrateStars,
+}
+
+// Progress is the player's record of every level played, saved between
+// sessions. Records are keyed by stable level IDs rather than by where a
+// level sits in its pack, so reordering levels keeps their progress.
+type Progress struct {
+	Version int `toml:"version"`
+	// Hardcore marks a profile played without undo or hints
+	Hardcore bool            `toml:"hardcore,omitempty"`
+	Levels   []LevelRecord   `toml:"levels"`
+	Sections []SectionRecord `toml:"sections,omitempty"`
+}
+
+// LevelRecord is the progress made on one level
+type LevelRecord struct {
+	// ID is the level's stable ID
+	ID string `toml:"id"`
+	// Code is where the level was last seen, as a fallback for levels
+	// whose ID changed, such as a level without a UUID being edited
+	Code      string `toml:"code"`
+	Completed bool   `toml:"completed"`
+	Gems      int    `toml:"gems"`
+	Stars     int    `toml:"stars"`
+	// Discovered are the secret cells found, as [column, row]
+	Discovered [][2]int `toml:"discovered,omitempty"`
+}
+
+// SectionRecord is the progress made on one section
+type SectionRecord struct {
+	// ID is the section's stable ID
+	ID string `toml:"id"`
+	// LivesLost counts the lives spent in challenge mode
+	LivesLost int `toml:"lives_lost"`
+}
+
+// StableID identifies the level across pack updates: its UUID when the
+// level file declares one, otherwise a hash of its grid
+func (l *Level) StableID() string {
+	if l.UUID != "" {
+		return l.UUID
+	}
+	grid := strings.ReplaceAll(strings.TrimSpace(l.Grid), "\r\n", "\n")
+	sum := sha256.Sum256([]byte(grid))
+	return "grid-" + hex.EncodeToString(sum[:8])
+}
+
+// Progress collects the progress made on every level
+func (m *Manager) Progress() Progress {
+	p := Progress{Version: len(progressMigrations), Hardcore: m.hardcore}
+	for _, s := range m.Sections {
+		if s.LivesLost > 0 {
+			p.Sections = append(p.Sections, SectionRecord{ID: s.StableID(), LivesLost: s.LivesLost})
+		}
+		for i, level := range s.levels {
+			if !level.Completed && level.GemsFound == 0 && len(level.discovered) == 0 {
+				continue
+			}
+			var discovered [][2]int
+			for pos := range level.discovered {
+				discovered = append(discovered, [2]int{pos.X, pos.Y})
+			}
+			slices.SortFunc(discovered, func(a, b [2]int) int {
+				return cmp.Or(cmp.Compare(a[1], b[1]), cmp.Compare(a[0], b[0]))
+			})
+			p.Levels = append(p.Levels, LevelRecord{
+				ID:         level.StableID(),
+				Code:       code(s.ID, i),
+				Completed:  level.Completed,
+				Gems:       level.GemsFound,
+				Stars:      level.Stars,
+				Discovered: discovered,
+			})
+		}
+	}
+	return p
+}
+
+// ApplyProgress restores the progress in p. Each record goes to the level
+// with its ID, wherever that level now sits; records whose ID no longer
+// matches any level fall back to the level at their code, unless that
+// level has a record of its own. Section records go to the section with
+// their ID.
+func (m *Manager) ApplyProgress(p Progress) {
+	byID := make(map[string]*Level)
+	byCode := make(map[string]*Level)
+	for _, s := range m.Sections {
+		for i, level := range s.levels {
+			byID[level.StableID()] = level
+			byCode[code(s.ID, i)] = level
+		}
+	}
+	m.hardcore = p.Hardcore
+	sections := make(map[string]*Section)
+	for _, s := range m.Sections {
+		s.LivesLost = 0
+		sections[s.StableID()] = s
+	}
+	for _, r := range p.Sections {
+		if s, ok := sections[r.ID]; ok {
+			s.LivesLost = min(max(r.LivesLost, 0), s.Lives)
+		}
+	}
+	claimed := make(map[*Level]bool)
+	for _, r := range p.Levels {
+		if level, ok := byID[r.ID]; ok {
+			claimed[level] = true
+		}
+	}
+	for _, r := range p.Levels {
+		level, ok := byID[r.ID]
+		if !ok {
+			level, ok = byCode[r.Code]
+			if !ok || claimed[level] {
+				log.Warn("progress for a level no longer found", "id", r.ID, "code", r.Code)
+				continue
+			}
+			log.Info("progress migrated by position", "id", r.ID, "code", r.Code, "new id", level.StableID())
+		}
+		level.Completed = r.Completed
+		level.GemsFound = min(r.Gems, level.Gems())
+		level.Stars = r.Stars
+		level.discovered = nil
+		for _, cell := range r.Discovered {
+			level.Discover(utils.Cell{X: cell[0], Y: cell[1]})
+		}
+	}
+}
+
+// migrateStars gives every completed level of a version 1 progress file
+// one star. TOML decodes [[levels]] tables as []map[string]any, but an
+// inline array of tables as []any.
+func migrateStars(doc map[string]any) error {
+	var records []map[string]any
+	switch levels := doc["levels"].(type) {
+	case nil:
+	case []map[string]any:
+		records = levels
+	case []any:
+		for _, r := range levels {
+			record, ok := r.(map[string]any)
+			if !ok {
+				return fmt.Errorf("level record %v is not a table", r)
+			}
+			records = append(records, record)
+		}
+	default:
+		return fmt.Errorf("levels is a %T, not a list of records", levels)
+	}
+	for _, r := range records {
+		if completed, _ := r["completed"].(bool); completed {
+			r["stars"] = int64(1)
+		}
+	}
+	return nil
+}
+
+// decodeProgress reads progress saved as TOML, in any format version,
+// and reports whether it was in an older one
+func decodeProgress(data []byte) (Progress, bool, error) {
+	var p Progress
+	migrated, err := profile.Decode(data, progressMigrations, &p)
+	return p, migrated, err
+}
+
+// readProgress reads the saved progress
+func readProgress() (Progress, bool, error) {
+	data, err := profile.ReadFile(progressFile)
+	if err != nil {
+		return Progress{}, false, err
+	}
+	return decodeProgress(data)
+}
+
+// loadProgress restores the saved progress, if any. Damaged progress is
+// set aside and replaced by the copy in the newest good backup, leaving a
+// notice for the player. When some records no longer find their level,
+// or the progress was saved in an older format, the profile is backed up
+// before the next save drops them or rewrites it for good; otherwise it is
+// backed up once a day, so there is a good copy to go back to.
+func (m *Manager) loadProgress() {
+	p, migrated, err := readProgress()
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if errors.Is(err, profile.ErrTooNew) {
+		log.Error("cannot load progress", "err", err)
+		m.notice = "Your progress was saved by a newer version of the game, so it won't be loaded or saved over."
+		m.frozen = true
+		return
+	}
+	if err != nil {
+		log.Error("cannot load progress", "err", err)
+		if p, migrated, err = m.recoverProgress(); err != nil {
+			return
+		}
+	}
+	m.ApplyProgress(p)
+	backup := profile.Checkpoint
+	if migrated || len(m.Progress().Levels) < len(p.Levels) {
+		backup = profile.Backup
+	}
+	if path, err := backup(); err != nil {
+		log.Warn("cannot back up progress", "err", err)
+	} else if path != "" {
+		log.Info("progress backed up", "path", path)
+	}
+	if migrated {
+		log.Info("progress migrated", "version", len(progressMigrations))
+		m.saveProgress()
+	}
+}
+
+// recoverProgress restores damaged progress from the backups
+func (m *Manager) recoverProgress() (Progress, bool, error) {
+	taken, err := profile.Recover(progressFile, func(data []byte) error {
+		_, _, err := decodeProgress(data)
+		return err
+	})
+	if err != nil {
+		log.Error("cannot recover progress", "err", err)
+		m.notice = "Your progress was damaged and no backup of it was found. The damaged file was kept as " + progressFile + ".broken."
+		return Progress{}, false, err
+	}
+	log.Info("progress recovered", "backup", taken)
+	m.notice = "Your progress was damaged, so it was restored from the backup of " + taken.Format("Jan 2 15:04") + "."
+	return readProgress()
+}
+
+// Hardcore reports whether the profile is played without undo or hints
+func (m *Manager) Hardcore() bool {
+	return m.hardcore
+}
+
+// SetHardcore makes the profile hardcore for good, saving it so
+func (m *Manager) SetHardcore() {
+	if m.hardcore {
+		return
+	}
+	m.hardcore = true
+	m.saveProgress()
+}
+
+// Notice returns a message for the player about loading their progress,
+// such as it having been restored from a backup, or "" when all went well
+func (m *Manager) Notice() string {
+	return m.notice
+}
+
+// SaveProgress writes the progress made so far
+func (m *Manager) SaveProgress() error {
+	if m.frozen {
+		return errors.New("progress was saved by a newer version of the game")
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(m.Progress()); err != nil {
+		return err
+	}
+	return profile.WriteFile(progressFile, buf.Bytes())
+}
+
+// saveProgress saves, logging rather than failing, as losing a save
+// shouldn't stop play
+func (m *Manager) saveProgress() {
+	if err := m.SaveProgress(); err != nil {
+		log.Warn("cannot save progress", "err", err)
+	}
+}
@@ -0,0 +1,212 @@
+package levels
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zrcoder/icer/internal/profile"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+func TestDecodeProgressVersions(t *testing.T) {
+	current := len(progressMigrations)
+	tests := []struct {
+		name     string
+		data     string
+		want     []LevelRecord
+		migrated bool
+		err      error
+	}{
+		{
+			name: "unversioned",
+			data: `
+[[levels]]
+id = "a"
+code = "1-1"
+completed = true
+gems = 1
+
+[[levels]]
+id = "b"
+code = "1-2"
+gems = 1
+`,
+			want: []LevelRecord{
+				{ID: "a", Code: "1-1", Completed: true, Gems: 1, Stars: 1},
+				{ID: "b", Code: "1-2", Gems: 1},
+			},
+			migrated: true,
+		},
+		{
+			name: "version 1",
+			data: `
+version = 1
+
+[[levels]]
+id = "a"
+code = "1-1"
+completed = true
+`,
+			want:     []LevelRecord{{ID: "a", Code: "1-1", Completed: true, Stars: 1}},
+			migrated: true,
+		},
+		{
+			name:     "version 1 with inline records",
+			data:     `version = 1` + "\n" + `levels = [{id = "a", completed = true}, {id = "b"}]`,
+			want:     []LevelRecord{{ID: "a", Completed: true, Stars: 1}, {ID: "b"}},
+			migrated: true,
+		},
+		{
+			name:     "version 1 without records",
+			data:     `version = 1`,
+			migrated: true,
+		},
+		{
+			name: "version 2",
+			data: `
+version = 2
+
+[[levels]]
+id = "a"
+code = "1-1"
+completed = true
+stars = 3
+`,
+			want: []LevelRecord{{ID: "a", Code: "1-1", Completed: true, Stars: 3}},
+		},
+		{
+			name: "version 2 with discovered cells",
+			data: `
+version = 2
+
+[[levels]]
+id = "a"
+discovered = [[3, 1], [0, 2]]
+`,
+			want: []LevelRecord{{ID: "a", Discovered: [][2]int{{3, 1}, {0, 2}}}},
+		},
+		{
+			name: "too new",
+			data: "version = 3",
+			err:  profile.ErrTooNew,
+		},
+		{
+			name: "records not a list",
+			data: `levels = "a"`,
+			err:  errAny,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, migrated, err := decodeProgress([]byte(tt.data))
+			switch {
+			case tt.err == errAny:
+				if err == nil {
+					t.Fatal("decoded, want an error")
+				}
+				return
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error = %v, want %v", err, tt.err)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if p.Version != current {
+				t.Errorf("version = %d, want %d", p.Version, current)
+			}
+			if migrated != tt.migrated {
+				t.Errorf("migrated = %v, want %v", migrated, tt.migrated)
+			}
+			if !reflect.DeepEqual(p.Levels, tt.want) {
+				t.Errorf("levels = %+v, want %+v", p.Levels, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressKeepsDiscoveredCells(t *testing.T) {
+	newManager := func() *Manager {
+		level := &Level{Grid: "PHH"}
+		return &Manager{Sections: []*Section{{levels: []*Level{level}}}}
+	}
+	m := newManager()
+	m.Sections[0].levels[0].Discover(utils.Cell{X: 2, Y: 0})
+	m.Sections[0].levels[0].Discover(utils.Cell{X: 1, Y: 0})
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(m.Progress()); err != nil {
+		t.Fatal(err)
+	}
+	p, _, err := decodeProgress(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]int{{1, 0}, {2, 0}}; len(p.Levels) != 1 || !reflect.DeepEqual(p.Levels[0].Discovered, want) {
+		t.Fatalf("records = %+v, want one discovering %v", p.Levels, want)
+	}
+
+	restored := newManager()
+	restored.ApplyProgress(p)
+	level := restored.Sections[0].levels[0]
+	for x, want := range []bool{false, true, true} {
+		if got := level.Discovered(utils.Cell{X: x, Y: 0}); got != want {
+			t.Errorf("cell %d discovered = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestProgressKeepsLivesLost(t *testing.T) {
+	newManager := func() *Manager {
+		return &Manager{Sections: []*Section{
+			{dir: "1", Lives: 3},
+			{dir: "2", Lives: 3},
+			{dir: "1", Pack: "ice", Lives: 2},
+		}}
+	}
+	m := newManager()
+	m.Sections[1].LivesLost = 2
+	m.Sections[2].LivesLost = 1
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(m.Progress()); err != nil {
+		t.Fatal(err)
+	}
+	p, _, err := decodeProgress(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Sections = append(p.Sections, SectionRecord{ID: "gone", LivesLost: 1})
+
+	restored := newManager()
+	restored.Sections[0].LivesLost = 1
+	restored.ApplyProgress(p)
+	for i, want := range []int{0, 2, 1} {
+		if got := restored.Sections[i].LivesLost; got != want {
+			t.Errorf("section %s lives lost = %d, want %d", restored.Sections[i].StableID(), got, want)
+		}
+	}
+}
+
+func TestProgressKeepsHardcore(t *testing.T) {
+	m := &Manager{hardcore: true}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(m.Progress()); err != nil {
+		t.Fatal(err)
+	}
+	p, _, err := decodeProgress(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &Manager{}
+	restored.ApplyProgress(p)
+	if !restored.Hardcore() {
+		t.Error("hardcore profile restored as a regular one")
+	}
+	restored.ApplyProgress(Progress{})
+	if restored.Hardcore() {
+		t.Error("regular profile restored as hardcore")
+	}
+}
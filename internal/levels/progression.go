@@ -0,0 +1,40 @@
+package levels
+
+// Unlocked reports whether level i of the section can be played: the
+// first level always, every other regular level once the one before it
+// is completed, and bonus levels once they are unlocked. Everything is
+// unlocked in dev mode.
+func (s *Section) Unlocked(i int) bool {
+	switch {
+	case dev || i == 0:
+		return true
+	case i < s.LevelCount:
+		return s.levels[i-1].Completed
+	default:
+		return s.BonusUnlocked()
+	}
+}
+
+// Stars returns the stars earned on the section's levels
+func (s *Section) Stars() int {
+	stars := 0
+	for _, level := range s.levels {
+		stars += level.Stars
+	}
+	return stars
+}
+
+// StarsBefore returns the stars earned in the sections before section i
+func (m *Manager) StarsBefore(i int) int {
+	stars := 0
+	for _, s := range m.Sections[:i] {
+		stars += s.Stars()
+	}
+	return stars
+}
+
+// SectionUnlocked reports whether section i can be played, its gate
+// asking for no more stars than the sections before it have earned
+func (m *Manager) SectionUnlocked(i int) bool {
+	return dev || m.StarsBefore(i) >= m.Sections[i].Gate
+}
@@ -0,0 +1,27 @@
+package levels
+
+import (
+	"fmt"
+
+	"github.com/zrcoder/icer/internal/sprites"
+)
+
+// Constructor builds a sprite placed at a grid cell
+type Constructor func(x, y int) sprites.Sprite
+
+var custom = map[rune]Constructor{}
+
+// RegisterSprite binds a grid character to a custom sprite constructor.
+// It must run before levels are loaded, typically from an init function.
+// Characters of the default legend are reserved; a pack's own legend still
+// wins over a registered character.
+func RegisterSprite(char rune, ctor Constructor) error {
+	if _, ok := defaultLegend[char]; ok {
+		return fmt.Errorf("grid character %q is reserved", char)
+	}
+	if _, ok := custom[char]; ok {
+		return fmt.Errorf("grid character %q is already registered", char)
+	}
+	custom[char] = ctor
+	return nil
+}
@@ -0,0 +1,177 @@
+package levels
+
+import (
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Mutation is a change a remix makes to a level
+type Mutation int
+
+const (
+	// MutationFlame adds a flame on an empty floor cell
+	MutationFlame Mutation = iota
+	// MutationIce takes away an ice block
+	MutationIce
+	// MutationWall moves a wall off the grid's edge to an empty floor cell
+	MutationWall
+	// MutationExtraIce adds an ice block on an empty floor cell, which can
+	// make up for an extra flame
+	MutationExtraIce
+	mutationCount
+)
+
+// maxMutations is the most mutations a remix makes
+const maxMutations = 3
+
+func (m Mutation) String() string {
+	switch m {
+	case MutationFlame:
+		return "extra flame"
+	case MutationIce:
+		return "one ice block fewer"
+	case MutationWall:
+		return "moved wall"
+	case MutationExtraIce:
+		return "extra ice block"
+	}
+	return "unknown mutation"
+}
+
+// Remix is a built-in level changed by mutations, the challenge of a week
+type Remix struct {
+	Seed uint64
+	// Section holds the original level
+	Section *Section
+	// Level is the changed copy, its par cleared as the original's no
+	// longer holds
+	Level     *Level
+	Mutations []Mutation
+	// section and level are where the original sits among the built-in
+	// sections
+	section, level int
+}
+
+// Code returns the level code of the original level
+func (r Remix) Code() string {
+	return code(r.section, r.level)
+}
+
+// RemixSeed returns the seed of the remix of the ISO week date falls in
+func RemixSeed(date time.Time) uint64 {
+	year, week := date.ISOWeek()
+	return uint64(year*100 + week)
+}
+
+// Remix picks a built-in level with seed and changes it by one to
+// maxMutations mutations, also picked with seed. Mutations with nowhere to
+// go are left out, so the remix may make fewer; the remixed level may not
+// be winnable. The level is a copy sharing nothing with the game, so it
+// can be solved away from the game loop.
+func (m *Manager) Remix(seed uint64) Remix {
+	r := rand.New(rand.NewPCG(seed, ^seed))
+	total := 0
+	for _, s := range m.builtin() {
+		total += s.LevelCount
+	}
+	n := r.IntN(total)
+	res := Remix{Seed: seed}
+	for i, s := range m.builtin() {
+		if n < s.LevelCount {
+			res.Section, res.section, res.level = s, i, n
+			res.Level, res.Mutations = s.levels[n].mutate(r)
+			break
+		}
+		n -= s.LevelCount
+	}
+	return res
+}
+
+// PlayRemix makes the remix the current level
+func (m *Manager) PlayRemix(r Remix) {
+	m.SetCurrentSection(r.section)
+	m.currentLevel = r.Level
+}
+
+// mutate returns a copy of the level changed by mutations picked with r,
+// and the mutations made
+func (l *Level) mutate(r *rand.Rand) (*Level, []Mutation) {
+	res := l.clone()
+	res.Par = 0
+	rows, err := parseGrid(l.Grid)
+	if err != nil {
+		return res, nil
+	}
+	var made []Mutation
+	for range 1 + r.IntN(maxMutations) {
+		m := Mutation(r.IntN(int(mutationCount)))
+		if l.applyMutation(rows, m, r) {
+			made = append(made, m)
+		}
+	}
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.WriteString(string(row))
+		sb.WriteByte('\n')
+	}
+	res.Grid = sb.String()
+	return res, made
+}
+
+// applyMutation makes m on rows, reporting false when there is nowhere
+// to make it
+func (l *Level) applyMutation(rows [][]rune, m Mutation, r *rand.Rand) bool {
+	empty, ok := l.char(KindFloor)
+	if l.kind(floor) == KindFloor {
+		empty, ok = floor, true
+	}
+	floors := l.cellsOf(rows, KindFloor, false)
+	switch m {
+	case MutationFlame, MutationExtraIce:
+		kind := KindFlame
+		if m == MutationExtraIce {
+			kind = KindIce
+		}
+		char, found := l.char(kind)
+		if !found || len(floors) == 0 {
+			return false
+		}
+		c := floors[r.IntN(len(floors))]
+		rows[c.Y][c.X] = char
+	case MutationIce:
+		ice := l.cellsOf(rows, KindIce, false)
+		if !ok || len(ice) == 0 {
+			return false
+		}
+		c := ice[r.IntN(len(ice))]
+		rows[c.Y][c.X] = empty
+	case MutationWall:
+		walls := l.cellsOf(rows, KindWall, true)
+		if !ok || len(walls) == 0 || len(floors) == 0 {
+			return false
+		}
+		from, to := walls[r.IntN(len(walls))], floors[r.IntN(len(floors))]
+		rows[to.Y][to.X], rows[from.Y][from.X] = rows[from.Y][from.X], empty
+	default:
+		return false
+	}
+	return true
+}
+
+// cellsOf lists the cells of rows holding kind, in reading order, leaving
+// out the grid's edge when inner is set
+func (l *Level) cellsOf(rows [][]rune, kind string, inner bool) []utils.Cell {
+	var res []utils.Cell
+	for y, row := range rows {
+		for x, char := range row {
+			edge := y == 0 || x == 0 || y == len(rows)-1 || x == len(row)-1
+			if l.kind(char) == kind && !(inner && edge) {
+				res = append(res, utils.Cell{X: x, Y: y})
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package levels
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyMutation(t *testing.T) {
+	const grid = "######\n#MI.F#\n#.##.#\n######"
+	tests := []struct {
+		mutation Mutation
+		// counts are how many of each character the grid holds after it
+		counts map[rune]int
+	}{
+		{MutationFlame, map[rune]int{'F': 2, 'I': 1, '#': 18, '.': 2}},
+		{MutationIce, map[rune]int{'F': 1, 'I': 0, '#': 18, '.': 4}},
+		{MutationWall, map[rune]int{'F': 1, 'I': 1, '#': 18, '.': 3}},
+		{MutationExtraIce, map[rune]int{'F': 1, 'I': 2, '#': 18, '.': 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mutation.String(), func(t *testing.T) {
+			l := &Level{Grid: grid}
+			rows, err := parseGrid(grid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !l.applyMutation(rows, tt.mutation, rand.New(rand.NewPCG(1, 2))) {
+				t.Fatal("mutation found nowhere to go")
+			}
+			for char, want := range tt.counts {
+				got := 0
+				for _, row := range rows {
+					got += strings.Count(string(row), string(char))
+				}
+				if got != want {
+					t.Errorf("%d of %q after the mutation, want %d", got, char, want)
+				}
+			}
+			if string(rows[0]) != "######" || string(rows[3]) != "######" {
+				t.Errorf("edge walls moved: %q", rows)
+			}
+		})
+	}
+}
+
+func TestRemixIsDeterministic(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := NewManager()
+	for seed := range uint64(20) {
+		a, b := m.Remix(seed), m.Remix(seed)
+		if a.Level.Grid != b.Level.Grid || !reflect.DeepEqual(a.Mutations, b.Mutations) {
+			t.Fatalf("seed %d remixed two ways", seed)
+		}
+		if a.Level.Par != 0 {
+			t.Errorf("seed %d remix kept the par of the original", seed)
+		}
+	}
+}
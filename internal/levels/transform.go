@@ -0,0 +1,158 @@
+package levels
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Transform is one of the eight symmetries of a grid: an optional horizontal
+// mirror followed by a number of clockwise quarter turns. Apart from gravity,
+// every rule treats the four directions alike and directional tiles turn
+// with the grid, so a transformed level stays solvable.
+type Transform struct {
+	Mirror bool
+	Turns  int
+}
+
+// RandomTransform picks a non-identity transform from seed
+func RandomTransform(seed uint64) Transform {
+	r := rand.New(rand.NewPCG(seed, seed))
+	n := r.IntN(7) + 1
+	return Transform{Mirror: n >= 4, Turns: n % 4}
+}
+
+// Apply returns where d points once the grid has been transformed
+func (t Transform) Apply(d utils.Direction) utils.Direction {
+	if t.Mirror {
+		d = d.Mirror()
+	}
+	for range t.Turns % 4 {
+		d = d.Clockwise()
+	}
+	return d
+}
+
+func (t Transform) String() string {
+	s := fmt.Sprintf("rot%d", t.Turns*90)
+	if t.Mirror {
+		s = "mirror+" + s
+	}
+	return s
+}
+
+// Transform returns a copy of the level with its grid transformed, the
+// cells its links and enemies point at moved along and enemy patrols
+// turned with the grid. Discovered secrets are not carried over as their
+// cells have moved.
+func (l *Level) Transform(t Transform) *Level {
+	res := l.clone()
+	res.Grid = transformGrid(l.Grid, t, func(char rune) rune {
+		return l.turn(char, t)
+	})
+	if rows, err := parseGrid(l.Grid); err == nil {
+		width, height := len(rows[0]), len(rows)
+		res.Links = make([]Link, len(l.Links))
+		for i, link := range l.Links {
+			res.Links[i].Plate = t.cell(link.Plate, width, height)
+			res.Links[i].Flag = link.Flag
+			for _, wall := range link.Walls {
+				res.Links[i].Walls = append(res.Links[i].Walls, t.cell(wall, width, height))
+			}
+		}
+		res.Enemies = make([]Enemy, len(l.Enemies))
+		for i, enemy := range l.Enemies {
+			res.Enemies[i] = enemy
+			res.Enemies[i].At = t.cell(enemy.At, width, height)
+			if patrol, ok := utils.ParseDirections(enemy.Patrol); ok {
+				for j, dir := range patrol {
+					patrol[j] = t.Apply(dir)
+				}
+				res.Enemies[i].Patrol = utils.SpellDirections(patrol)
+			}
+		}
+	}
+	res.discovered = nil
+	return res
+}
+
+// cell returns where the [column, row] cell of a width x height grid ends
+// up once the grid is transformed
+func (t Transform) cell(c [2]int, width, height int) [2]int {
+	x, y := c[0], c[1]
+	if t.Mirror {
+		x = width - 1 - x
+	}
+	for range t.Turns % 4 {
+		x, y = height-1-y, x
+		width, height = height, width
+	}
+	return [2]int{x, y}
+}
+
+// turn returns the character of the directional tile char once turned by
+// t, or char itself for any other tile
+func (l *Level) turn(char rune, t Transform) rune {
+	for _, family := range directional {
+		dir, ok := family[l.kind(char)]
+		if !ok {
+			continue
+		}
+		for kind, d := range family {
+			if d != t.Apply(dir) {
+				continue
+			}
+			if res, ok := l.char(kind); ok {
+				return res
+			}
+		}
+	}
+	return char
+}
+
+// transformGrid mirrors and turns grid, replacing each character with what
+// turn returns for it
+func transformGrid(grid string, t Transform, turn func(rune) rune) string {
+	cells, err := parseGrid(grid)
+	if err != nil {
+		return grid
+	}
+	for _, row := range cells {
+		for i, char := range row {
+			row[i] = turn(char)
+		}
+	}
+	if t.Mirror {
+		for _, row := range cells {
+			for i, j := 0, len(row)-1; i < j; i, j = i+1, j-1 {
+				row[i], row[j] = row[j], row[i]
+			}
+		}
+	}
+	for range t.Turns % 4 {
+		cells = rotateClockwise(cells)
+	}
+	var sb strings.Builder
+	for _, row := range cells {
+		sb.WriteString(string(row))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func rotateClockwise(cells [][]rune) [][]rune {
+	if len(cells) == 0 {
+		return cells
+	}
+	h, w := len(cells), len(cells[0])
+	res := make([][]rune, w)
+	for i := range res {
+		res[i] = make([]rune, h)
+		for j := range res[i] {
+			res[i][j] = cells[h-1-j][i]
+		}
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package levels
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/zrcoder/icer/internal/i18n"
+)
+
+// Translation is the text of a level or section in another language,
+// under [translations.<locale>] in its file. Whatever it leaves out stays
+// in the default language.
+type Translation struct {
+	Title       string `toml:"title"`
+	Description string `toml:"description"`
+	// NPCs translate the dialog of the level's npc entries, in order
+	NPCs []NPCTranslation `toml:"npc"`
+}
+
+// NPCTranslation is the dialog of one NPC in another language
+type NPCTranslation struct {
+	Pages []string `toml:"pages"`
+	// Choices are the texts of the NPC's choices, in order
+	Choices []string `toml:"choices"`
+}
+
+// localize swaps in the title and description for the locale in use
+func (m *Meta) localize() {
+	t, ok := i18n.Pick(m.Translations)
+	if !ok {
+		return
+	}
+	m.Title = cmp.Or(t.Title, m.Title)
+	m.Description = cmp.Or(t.Description, m.Description)
+}
+
+// localize swaps in the level's text for the locale in use, dialog
+// included
+func (l *Level) localize() {
+	l.Meta.localize()
+	t, ok := i18n.Pick(l.Translations)
+	if !ok {
+		return
+	}
+	l.NPCs = slices.Clone(l.NPCs)
+	for i := range min(len(t.NPCs), len(l.NPCs)) {
+		npc := l.NPCs[i]
+		if len(t.NPCs[i].Pages) > 0 {
+			npc.Pages = t.NPCs[i].Pages
+		}
+		npc.Choices = slices.Clone(npc.Choices)
+		for j := range min(len(t.NPCs[i].Choices), len(npc.Choices)) {
+			npc.Choices[j].Text = cmp.Or(t.NPCs[i].Choices[j], npc.Choices[j].Text)
+		}
+		l.NPCs[i] = npc
+	}
+}
+
+// checkTranslations finds translated dialog with no dialog to translate.
+// It is left out rather than stopping the level from being played.
+func (l *Level) checkTranslations() []string {
+	var res []string
+	for _, locale := range slices.Sorted(maps.Keys(l.Translations)) {
+		t := l.Translations[locale]
+		if len(t.NPCs) > len(l.NPCs) {
+			res = append(res, fmt.Sprintf("translation %q has %d npc entries, but the level has %d", locale, len(t.NPCs), len(l.NPCs)))
+		}
+		for i, npc := range t.NPCs[:min(len(t.NPCs), len(l.NPCs))] {
+			if len(npc.Choices) > len(l.NPCs[i].Choices) {
+				res = append(res, fmt.Sprintf("translation %q of npc %d has %d choices, but the npc has %d", locale, i+1, len(npc.Choices), len(l.NPCs[i].Choices)))
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package levels
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Diagnostic is a mistake found in a level file, at a line and column
+// counted from 1 when it can be pinned down, or 0 when it can't
+type Diagnostic struct {
+	File string
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.File)
+	if d.Line > 0 {
+		fmt.Fprintf(&sb, ":%d", d.Line)
+		if d.Col > 0 {
+			fmt.Fprintf(&sb, ":%d", d.Col)
+		}
+	}
+	sb.WriteString(": ")
+	sb.WriteString(d.Msg)
+	return sb.String()
+}
+
+// Problems returns the mistakes found when the level was loaded. A level
+// with problems cannot be played.
+func (l *Level) Problems() []Diagnostic {
+	return l.problems
+}
+
+// Validate checks the level for mistakes that would stop it from being
+// played or won: a grid that can't be read, no player, no flame or no
+// ice, characters the legend doesn't know and portals without a twin,
+// as well as links and enemy entries pointing at the wrong cells. Short
+// rows are not a mistake, as they are padded with floor.
+func (l *Level) Validate() []Diagnostic {
+	var res []Diagnostic
+	report := func(line, col int, format string, args ...any) {
+		res = append(res, Diagnostic{File: l.file, Line: line, Col: col, Msg: fmt.Sprintf(format, args...)})
+	}
+	rows, lines, err := splitGrid(l.Grid)
+	if err != nil {
+		report(l.gridLine, 0, "%v", err)
+		return res
+	}
+	// at returns the line and column in the file of a cell
+	at := func(pos utils.Cell) (int, int) {
+		if l.gridLine == 0 {
+			return 0, 0
+		}
+		return l.gridLine + lines[pos.Y], pos.X + 1
+	}
+	counts := make(map[string]int)
+	portals := make(map[rune][]utils.Cell)
+	var order []rune
+	for y, row := range rows {
+		for x, ch := range row {
+			kind := l.kind(ch)
+			counts[kind]++
+			if kind != KindPortal {
+				continue
+			}
+			if _, ok := portals[ch]; !ok {
+				order = append(order, ch)
+			}
+			portals[ch] = append(portals[ch], utils.Cell{X: x, Y: y})
+		}
+	}
+	for _, need := range [][2]string{{KindPlayer, "player"}, {KindFlame, "flame"}, {KindIce, "ice block"}} {
+		if counts[need[0]] == 0 {
+			report(l.gridLine, 0, "the grid has no %s", need[1])
+		}
+	}
+	for _, ch := range order {
+		cells := portals[ch]
+		line, col := at(cells[0])
+		switch len(cells) {
+		case 1:
+			report(line, col, "unknown character %q; a portal needs a twin with the same character", ch)
+		case 2:
+		default:
+			report(line, col, "portal %q has %d ends, but portals come in pairs", ch, len(cells))
+		}
+	}
+	if err := l.regular(); err != nil {
+		report(0, 0, "%v", err)
+	}
+	slices.SortStableFunc(res, func(a, b Diagnostic) int {
+		return a.Line - b.Line
+	})
+	return res
+}
+
+// parseDiagnostic describes an error unmarshaling file, with its position
+// when the TOML parser gives one
+func parseDiagnostic(file string, err error) Diagnostic {
+	d := Diagnostic{File: file, Msg: err.Error()}
+	var perr toml.ParseError
+	if errors.As(err, &perr) {
+		d.Line, d.Col = perr.Position.Line, perr.Position.Col
+		d.Msg = perr.Message
+	}
+	return d
+}
+
+// gridLine returns the line of data where grid starts, counted from 1,
+// or 0 when it can't be found
+func gridLine(data []byte, grid string) int {
+	i := bytes.Index(data, []byte(grid))
+	if grid == "" || i < 0 {
+		return 0
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
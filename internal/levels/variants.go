@@ -0,0 +1,47 @@
+package levels
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Rule variants a section can turn on in its index.toml, as in
+//
+//	variants = ["ice_chain_push", "flames_spread"]
+const (
+	// VariantChainPush makes ice pushed into another block of ice hand
+	// the push on to it
+	VariantChainPush = "ice_chain_push"
+	// VariantMerge fuses ice pushed up against another block of ice
+	VariantMerge = "ice_merge"
+	// VariantPlayerSlides makes the player slide across frozen ground
+	// the way ice does
+	VariantPlayerSlides = "player_slides_on_ice"
+	// VariantFlamesSpread lights new flames beside burning ones as the
+	// moves go by
+	VariantFlamesSpread = "flames_spread"
+)
+
+var variants = map[string]bool{
+	VariantChainPush: true, VariantMerge: true, VariantPlayerSlides: true, VariantFlamesSpread: true,
+}
+
+// Variant reports whether the section plays by the named rule variant.
+// The older chains and merge switches still turn on their variants.
+func (s *Section) Variant(name string) bool {
+	if name == VariantChainPush && s.Chains || name == VariantMerge && s.Merge {
+		return true
+	}
+	return slices.Contains(s.Variants, name)
+}
+
+// checkVariants reports the first variant the section names that the
+// game doesn't know
+func (s *Section) checkVariants() error {
+	for _, name := range s.Variants {
+		if !variants[name] {
+			return fmt.Errorf("unknown rule variant %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !js
+
+package links
+
+import "strings"
+
+// Launch returns the link the game was started with. Operating systems
+// pass the URL of a clicked link as a command line argument.
+func Launch(args []string) (Link, bool, error) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, Scheme+":") {
+			link, err := Parse(arg)
+			return link, err == nil, err
+		}
+	}
+	return Link{}, false, nil
+}
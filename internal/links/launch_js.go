@@ -0,0 +1,15 @@
+//go:build js
+
+package links
+
+import "syscall/js"
+
+// Launch returns the link in the query string of the page running the
+// game; the browser has no command line, so args are ignored
+func Launch(args []string) (Link, bool, error) {
+	location := js.Global().Get("location")
+	if location.IsUndefined() {
+		return Link{}, false, nil
+	}
+	return FromQuery(location.Get("search").String())
+}
@@ -0,0 +1,95 @@
+// Package links parses the deep links that launch the game straight into
+// a puzzle: icer://level/<code> for a level such as "2-5", and
+// icer://daily/<date> for the daily puzzle of a date such as
+// "2026-10-16". The browser build reads the same from the page's query
+// string, as ?level=<code> or ?daily=<date>.
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Scheme is the URL scheme the game registers for
+const Scheme = "icer"
+
+// DateLayout is how dates are written in daily links
+const DateLayout = time.DateOnly
+
+// Kind tells what a link points at
+type Kind int
+
+const (
+	KindLevel Kind = iota
+	KindDaily
+)
+
+// Link is a parsed deep link
+type Link struct {
+	Kind Kind
+	// Code is the level code of a level link
+	Code string
+	// Date is the day of a daily link
+	Date time.Time
+}
+
+// Parse reads an icer:// URL
+func Parse(raw string) (Link, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Link{}, err
+	}
+	if u.Scheme != Scheme {
+		return Link{}, fmt.Errorf("not an %s link: %q", Scheme, raw)
+	}
+	// icer://level/2-5 puts "level" in the host; accept icer:level/2-5 too
+	path := strings.Trim(u.Host+"/"+strings.TrimPrefix(u.Path, "/"), "/")
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	kind, arg, _ := strings.Cut(path, "/")
+	return parse(kind, arg)
+}
+
+// FromQuery reads a link from the query string of a page's URL
+func FromQuery(query string) (Link, bool, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(query, "?"))
+	if err != nil {
+		return Link{}, false, err
+	}
+	for _, kind := range []string{"level", "daily"} {
+		if arg := values.Get(kind); arg != "" {
+			link, err := parse(kind, arg)
+			return link, err == nil, err
+		}
+	}
+	return Link{}, false, nil
+}
+
+func parse(kind, arg string) (Link, error) {
+	switch kind {
+	case "level":
+		if arg == "" {
+			return Link{}, fmt.Errorf("level link without a code")
+		}
+		return Link{Kind: KindLevel, Code: arg}, nil
+	case "daily":
+		date, err := time.Parse(DateLayout, arg)
+		if err != nil {
+			return Link{}, fmt.Errorf("bad daily date %q: %w", arg, err)
+		}
+		return Link{Kind: KindDaily, Date: date}, nil
+	default:
+		return Link{}, fmt.Errorf("unknown link %q", kind)
+	}
+}
+
+// String writes the link back as an icer:// URL
+func (l Link) String() string {
+	if l.Kind == KindDaily {
+		return fmt.Sprintf("%s://daily/%s", Scheme, l.Date.Format(DateLayout))
+	}
+	return fmt.Sprintf("%s://level/%s", Scheme, l.Code)
+}
@@ -0,0 +1,38 @@
+package links
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const desktopFile = "icer-links.desktop"
+
+// Register makes exe the handler of icer:// links for the current user,
+// through a desktop entry for the scheme's MIME type
+func Register(exe string) error {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	dir = filepath.Join(dir, "applications")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=ICER
+Exec="%s" %%u
+NoDisplay=true
+MimeType=x-scheme-handler/%s;
+`, exe, Scheme)
+	if err := os.WriteFile(filepath.Join(dir, desktopFile), []byte(entry), 0o644); err != nil {
+		return err
+	}
+	return exec.Command("xdg-mime", "default", desktopFile, "x-scheme-handler/"+Scheme).Run()
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !windows
+
+package links
+
+import (
+	"errors"
+	"runtime"
+)
+
+// Register is unsupported here: macOS apps declare their URL schemes in
+// the bundle's Info.plist (CFBundleURLTypes), and browsers take links as
+// page URLs instead
+func Register(exe string) error {
+	return errors.New("registering links is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,23 @@
+package links
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Register makes exe the handler of icer:// links for the current user,
+// through the registry
+func Register(exe string) error {
+	key := `HKCU\Software\Classes\` + Scheme
+	commands := [][]string{
+		{"add", key, "/ve", "/d", "URL:ICER", "/f"},
+		{"add", key, "/v", "URL Protocol", "/d", "", "/f"},
+		{"add", key + `\shell\open\command`, "/ve", "/d", fmt.Sprintf(`"%s" "%%1"`, exe), "/f"},
+	}
+	for _, args := range commands {
+		if err := exec.Command("reg", args...).Run(); err != nil {
+			return fmt.Errorf("reg %v: %w", args, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package physics
+
+import (
+	"math/bits"
+
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Bitset is a set of grid cells, indexed row by row
+type Bitset []uint64
+
+func newBitset(cells int) Bitset {
+	return make(Bitset, (cells+63)/64)
+}
+
+func (s Bitset) Has(i int) bool {
+	return s[i/64]&(1<<(i%64)) != 0
+}
+
+func (s Bitset) Set(i int) {
+	s[i/64] |= 1 << (i % 64)
+}
+
+func (s Bitset) Clear(i int) {
+	s[i/64] &^= 1 << (i % 64)
+}
+
+// Count returns the number of cells in the set
+func (s Bitset) Count() int {
+	n := 0
+	for _, w := range s {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+func (s Bitset) clone() Bitset {
+	return append(Bitset(nil), s...)
+}
+
+func (s Bitset) equal(o Bitset) bool {
+	for i := range s {
+		if s[i] != o[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Board is a compact copy of the state the solver reasons about: solid
+// cells, ice blocks, flames, pickups and the player. It is cheap to clone
+// and compare, unlike the sprites the engine works with.
+type Board struct {
+	Width  int
+	Height int
+	Walls  Bitset
+	// Barriers are fake walls, which the player walks through and ice
+	// stops against. Like walls, they never change, so clones share them.
+	Barriers Bitset
+	Blocks   Bitset
+	Flames   Bitset
+	// Pickups are the gems, coins and keys still lying around. The player
+	// takes them by stepping on them; until then they stop ice.
+	Pickups Bitset
+	// Dead are the cells ice can never get from to a flame, as the
+	// engine's DeadCells finds them when the board is made. Putting out
+	// flames only adds to them, so clones share them as they stand.
+	Dead   Bitset
+	Player int
+	// keys hash the board, and hash is its current Zobrist hash
+	keys *zobrist
+	hash uint64
+}
+
+// Board converts the engine's current state into a compact board. It
+// reports false when the level uses mechanics a board cannot express,
+// such as portals, pots, stones, gravity, push chains or slippery
+// ground.
+func (e *PhysicsEngine) Board() (*Board, bool) {
+	if e.gravity || e.chains || e.slippery {
+		return nil, false
+	}
+	cells := e.width * e.height
+	b := &Board{
+		Width:    e.width,
+		Height:   e.height,
+		Walls:    newBitset(cells),
+		Barriers: newBitset(cells),
+		Blocks:   newBitset(cells),
+		Flames:   newBitset(cells),
+		Pickups:  newBitset(cells),
+		Dead:     e.DeadCells(),
+		Player:   -1,
+		keys:     newZobrist(cells),
+	}
+	for _, obj := range e.objects {
+		i := b.Index(obj.Position())
+		switch obj.(type) {
+		case *sprites.Wall, *sprites.NPC:
+			b.Walls.Set(i)
+		case *sprites.FakeWall:
+			b.Barriers.Set(i)
+		case *sprites.Ice:
+			b.Blocks.Set(i)
+		case *sprites.Flame:
+			b.Flames.Set(i)
+		case *sprites.Gem, *sprites.Coin, *sprites.Key:
+			b.Pickups.Set(i)
+		case *sprites.Player:
+			if b.Player >= 0 {
+				return nil, false
+			}
+			b.Player = i
+		default:
+			return nil, false
+		}
+	}
+	b.hash = b.keys.hash(b)
+	return b, true
+}
+
+// Hash returns the Zobrist hash of the board's player, blocks, flames and
+// pickups, kept up to date as the player moves. Walls and barriers never
+// change, so they are left out.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// Index returns the cell index of pos
+func (b *Board) Index(pos utils.Cell) int {
+	return pos.Y*b.Width + pos.X
+}
+
+// Position returns the grid position of cell i
+func (b *Board) Position(i int) utils.Cell {
+	return utils.Cell{X: i % b.Width, Y: i / b.Width}
+}
+
+// Clone returns an independent copy of the board
+func (b *Board) Clone() *Board {
+	res := *b
+	res.Walls = b.Walls.clone()
+	res.Blocks = b.Blocks.clone()
+	res.Flames = b.Flames.clone()
+	res.Pickups = b.Pickups.clone()
+	return &res
+}
+
+// Equal reports whether two boards hold the same state
+func (b *Board) Equal(o *Board) bool {
+	return b.Width == o.Width && b.Height == o.Height && b.Player == o.Player &&
+		b.Walls.equal(o.Walls) && b.Barriers.equal(o.Barriers) && b.Blocks.equal(o.Blocks) && b.Flames.equal(o.Flames) &&
+		b.Pickups.equal(o.Pickups)
+}
+
+// MovePlayer applies the same movement and extinguishing rules as the
+// engine and the rules system: the player steps one cell, taking any
+// pickup there, or pushes ice that slides until blocked and puts out the
+// first flame it reaches. It reports whether anything moved.
+func (b *Board) MovePlayer(dir utils.Direction) bool {
+	if b.Player < 0 {
+		return false
+	}
+	target, ok := b.step(b.Player, dir)
+	if !ok || b.Walls.Has(target) || b.Flames.Has(target) {
+		return false
+	}
+	if !b.Blocks.Has(target) {
+		b.hash ^= b.keys.player[b.Player] ^ b.keys.player[target]
+		b.Player = target
+		if b.Pickups.Has(target) {
+			b.Pickups.Clear(target)
+			b.hash ^= b.keys.pickups[target]
+		}
+		return true
+	}
+	pos := target
+	for {
+		next, ok := b.step(pos, dir)
+		if !ok || b.Walls.Has(next) || b.Barriers.Has(next) || b.Blocks.Has(next) || b.Pickups.Has(next) || next == b.Player {
+			break
+		}
+		pos = next
+		if b.Flames.Has(pos) {
+			break
+		}
+	}
+	if pos == target {
+		return false
+	}
+	b.Blocks.Clear(target)
+	b.hash ^= b.keys.blocks[target]
+	if b.Flames.Has(pos) {
+		b.Flames.Clear(pos)
+		b.hash ^= b.keys.flames[pos]
+	} else {
+		b.Blocks.Set(pos)
+		b.hash ^= b.keys.blocks[pos]
+	}
+	return true
+}
+
+// Solved reports whether every flame has been put out
+func (b *Board) Solved() bool {
+	return b.Flames.Count() == 0
+}
+
+// step returns the cell next to i in dir, if it lies on the board
+func (b *Board) step(i int, dir utils.Direction) (int, bool) {
+	pos := b.Position(i).Step(dir)
+	if pos.X < 0 || pos.X >= b.Width || pos.Y < 0 || pos.Y >= b.Height {
+		return 0, false
+	}
+	return b.Index(pos), true
+}
@@ -0,0 +1,138 @@
+package physics_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rules"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// syncGrids are levels made only of what boards express
+var syncGrids = map[string]string{
+	"open": `
+#########
+#P  I  F#
+#   I   #
+# F   I #
+#########`,
+	"pickups": `
+########
+#P G  I#
+#  I  C#
+#F  K  #
+#  F   #
+########`,
+	"secrets": `
+#########
+#P  H  I#
+# I #H  #
+#   F  F#
+#H  I   #
+#########`,
+	"corridors": `
+##########
+#P I #  F#
+#  # I   #
+#I   # F #
+#  F   I #
+##########`,
+}
+
+// play moves the player on the engine and applies the rules to the moves,
+// taking gems as the game does on the player's arrival
+func play(e *physics.PhysicsEngine, r *rules.GameRulesSystem, player *sprites.Player, dir utils.Direction) bool {
+	moves := e.MovePlayer(player, dir)
+	for _, m := range moves {
+		if m.Object == player {
+			for _, obj := range e.ObjectsAt(m.To()) {
+				if gem, ok := obj.(*sprites.Gem); ok {
+					e.Remove(gem)
+				}
+			}
+		}
+		r.ProcessMove(m)
+	}
+	return len(moves) > 0
+}
+
+func TestBoardMatchesEngine(t *testing.T) {
+	for name, grid := range syncGrids {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(rand.NewPCG(1, 2))
+			for walk := range 50 {
+				e, player := build(t, grid)
+				rs := rules.NewGameRulesSystem(e, rules.Config{})
+				b, ok := e.Board()
+				if !ok {
+					t.Fatal("grid not expressible as a board")
+				}
+				for step := range 60 {
+					dir := utils.Directions[r.IntN(len(utils.Directions))]
+					engineMoved := play(e, rs, player, dir)
+					boardMoved := b.MovePlayer(dir)
+					if engineMoved != boardMoved {
+						t.Fatalf("walk %d step %d %v: engine moved %v, board moved %v", walk, step, dir, engineMoved, boardMoved)
+					}
+					want, ok := e.Board()
+					if !ok {
+						t.Fatalf("walk %d step %d: engine state no longer expressible", walk, step)
+					}
+					if !b.Equal(want) {
+						t.Fatalf("walk %d step %d %v: board and engine differ", walk, step, dir)
+					}
+					if b.Hash() != want.Hash() {
+						t.Fatalf("walk %d step %d %v: hash kept as the board moved differs from the hash of the same board", walk, step, dir)
+					}
+					if b.Solved() != (rs.Flames() == 0) {
+						t.Fatalf("walk %d step %d: board solved %v, %d flames left", walk, step, b.Solved(), rs.Flames())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBoardDeclines(t *testing.T) {
+	for name, obj := range map[string]sprites.Sprite{
+		"stone":  sprites.NewStone(2, 1),
+		"portal": sprites.NewPortal('a', 2, 1),
+		"pot":    sprites.NewPot(2, 1),
+	} {
+		e := physics.NewPhysicsEngine(4, 3, []sprites.Sprite{sprites.NewPlayer(1, 1), obj})
+		if _, ok := e.Board(); ok {
+			t.Errorf("board made of a level with a %s", name)
+		}
+	}
+	e := physics.NewPhysicsEngine(4, 3, []sprites.Sprite{sprites.NewPlayer(1, 1), sprites.NewPlayer(2, 1)})
+	if _, ok := e.Board(); ok {
+		t.Error("board made of a level with two players")
+	}
+}
+
+func TestBoardPickupsStopIce(t *testing.T) {
+	e, _ := build(t, `
+#######
+#PIG F#
+#######`)
+	b, _ := e.Board()
+	if b.MovePlayer(utils.Right) {
+		t.Fatal("ice pushed over a gem")
+	}
+}
+
+func TestBoardBarriers(t *testing.T) {
+	e, _ := build(t, `
+#######
+#PH IH#
+#######`)
+	b, _ := e.Board()
+	if !b.MovePlayer(utils.Right) || !b.MovePlayer(utils.Right) {
+		t.Fatal("player stopped by a fake wall")
+	}
+	if b.MovePlayer(utils.Right) {
+		t.Fatal("ice pushed through a fake wall")
+	}
+}
@@ -0,0 +1,88 @@
+package physics
+
+import (
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Index returns the cell index of pos, as used by Bitset
+func (e *PhysicsEngine) Index(pos utils.Cell) int {
+	return pos.Y*e.width + pos.X
+}
+
+// DeadCells returns the cells from which an ice block can never reach a
+// flame, judging by the objects that never move. Ice may be stopped by
+// other blocks anywhere along a slide, so a cell counts as live when some
+// push sends ice over a flame or another live cell; whatever is left is
+// dead for sure. A conveyor pushes ice resting on it as a player would.
+// Cracked floor, holes, water, stones, bombs, doors, toggle, phase and
+// cracked walls count as floor, since holes get filled, water frozen
+// over, stones and bombs pushed out of the way and walls opened or blown
+// up, and so do one-way tiles, which only ever take moves away. Fake
+// walls stop ice, but the player walks through them and so may push from
+// one. Levels with portals, fans or gravity have no dead cells, as the
+// analysis does not model them.
+func (e *PhysicsEngine) DeadCells() Bitset {
+	cells := e.width * e.height
+	if e.gravity {
+		return newBitset(cells)
+	}
+	// stops holds the cells ice can't slide into, and walls those of them
+	// the player can't stand in either
+	stops := newBitset(cells)
+	walls := newBitset(cells)
+	flames := newBitset(cells)
+	live := newBitset(cells)
+	belts := make(map[int]utils.Direction)
+	for _, obj := range e.objects {
+		i := e.Index(obj.Position())
+		switch obj := obj.(type) {
+		case *sprites.Conveyor:
+			belts[i] = obj.Dir
+		case *sprites.FakeWall:
+			stops.Set(i)
+		case *sprites.Wall, *sprites.NPC, *sprites.Pot, *sprites.Lever:
+			stops.Set(i)
+			walls.Set(i)
+		case *sprites.Flame:
+			flames.Set(i)
+			live.Set(i)
+		case *sprites.Portal, *sprites.Fan:
+			return newBitset(cells)
+		}
+	}
+	standable := func(pos utils.Cell) bool {
+		return e.InBounds(pos) && !walls.Has(e.Index(pos)) && !flames.Has(e.Index(pos))
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := range cells {
+			pos := utils.Cell{X: i % e.width, Y: i / e.width}
+			if live.Has(i) || stops.Has(i) {
+				continue
+			}
+			for _, dir := range utils.Directions {
+				if belt, ok := belts[i]; (!ok || belt != dir) && !standable(pos.Step(dir.Opposite())) {
+					continue
+				}
+				for next := pos.Step(dir); e.InBounds(next) && !stops.Has(e.Index(next)); next = next.Step(dir) {
+					if live.Has(e.Index(next)) {
+						live.Set(i)
+						changed = true
+						break
+					}
+				}
+				if live.Has(i) {
+					break
+				}
+			}
+		}
+	}
+	dead := newBitset(cells)
+	for i := range cells {
+		if !live.Has(i) && !stops.Has(i) {
+			dead.Set(i)
+		}
+	}
+	return dead
+}
@@ -0,0 +1,88 @@
+package physics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+func TestDeadCells(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+		// dead marks the cells DeadCells should return with an x
+		dead string
+	}{
+		{
+			name: "corners and the walls along them",
+			grid: `
+#####
+#...#
+#..F#
+#####`,
+			dead: `
+.....
+.xxx.
+.x...
+.....`,
+		},
+		{
+			name: "edges of an open grid",
+			grid: `
+.....
+..F..
+.....`,
+			dead: `
+xxxxx
+x...x
+xxxxx`,
+		},
+		{
+			name: "a wall across the way to the flame",
+			grid: `
+########
+#..#..F#
+########`,
+			dead: `
+........
+.xx.x...
+........`,
+		},
+		{
+			name: "ice pushed from a fake wall",
+			grid: "#HI..F#",
+			dead: ".......",
+		},
+		{
+			name: "fake walls stop ice",
+			grid: "F.H...",
+			dead: "...xxx",
+		},
+		{
+			name: "portals leave nothing dead",
+			grid: `
+1...1
+..F..
+.....`,
+			dead: `
+.....
+.....
+.....`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := build(t, tt.grid)
+			dead := e.DeadCells()
+			for y, row := range strings.Split(strings.TrimSpace(tt.dead), "\n") {
+				for x, ch := range row {
+					pos := utils.Cell{X: x, Y: y}
+					if got := dead.Has(e.Index(pos)); got != (ch == 'x') {
+						t.Errorf("cell %v dead %v, want %v", pos, got, !got)
+					}
+				}
+			}
+		})
+	}
+}
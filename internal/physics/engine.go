@@ -0,0 +1,607 @@
+package physics
+
+import (
+	"slices"
+
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// PhysicsEngine resolves movement of sprites on a level grid
+type PhysicsEngine struct {
+	width    int
+	height   int
+	objects  []sprites.Sprite
+	portals  *PortalSystem
+	gravity  bool
+	chains   bool
+	slippery bool
+	momentum Momentum
+	// tugged is the player who has already tried once to step off sticky
+	// floor, and gets off on the next try
+	tugged sprites.Sprite
+}
+
+// Move records an object's displacement: the cells it passed through in
+// order, ending where it came to rest, so renderers can animate it. Going
+// through a portal adds its twin's cell right after the entry, so two
+// consecutive cells are not always adjacent.
+type Move struct {
+	Object sprites.Sprite
+	From   utils.Cell
+	Path   []utils.Cell
+}
+
+// Moved reports whether the object left its cell
+func (m Move) Moved() bool {
+	return len(m.Path) > 0
+}
+
+// To returns the cell the object came to rest in
+func (m Move) To() utils.Cell {
+	if len(m.Path) == 0 {
+		return m.From
+	}
+	return m.Path[len(m.Path)-1]
+}
+
+// NewPhysicsEngine creates an engine for a width x height grid holding objects
+func NewPhysicsEngine(width, height int, objects []sprites.Sprite) *PhysicsEngine {
+	return &PhysicsEngine{
+		width:   width,
+		height:  height,
+		objects: objects,
+	}
+}
+
+// SetPortals links the level's portals so objects teleport through them
+func (e *PhysicsEngine) SetPortals(portals *PortalSystem) {
+	e.portals = portals
+}
+
+// SetGravity turns on side-view physics: the player and ice fall until
+// something holds them up, and the player can only walk sideways
+func (e *PhysicsEngine) SetGravity(on bool) {
+	e.gravity = on
+}
+
+// SetChains makes ice running into another block of ice hand its push on,
+// so the struck block slides off while the first one stops
+func (e *PhysicsEngine) SetChains(on bool) {
+	e.chains = on
+}
+
+// SetSlippery makes frozen ground slippery: the player slides across it
+// like ice until stopped or back on solid footing
+func (e *PhysicsEngine) SetSlippery(on bool) {
+	e.slippery = on
+}
+
+// SetMomentum sets what sliding blocks do on coming out of a portal
+func (e *PhysicsEngine) SetMomentum(m Momentum) {
+	e.momentum = m
+}
+
+// Size returns the grid dimensions
+func (e *PhysicsEngine) Size() (width, height int) {
+	return e.width, e.height
+}
+
+// Objects returns every object on the grid
+func (e *PhysicsEngine) Objects() []sprites.Sprite {
+	return e.objects
+}
+
+// ObjectsAt returns the objects occupying pos, including wide objects
+// covering it from a neighboring cell
+func (e *PhysicsEngine) ObjectsAt(pos utils.Cell) []sprites.Sprite {
+	var res []sprites.Sprite
+	for _, obj := range e.objects {
+		if sprites.Covers(obj, pos) {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+// InBounds reports whether pos lies on the grid
+func (e *PhysicsEngine) InBounds(pos utils.Cell) bool {
+	return pos.X >= 0 && pos.X < e.width && pos.Y >= 0 && pos.Y < e.height
+}
+
+// MoveObject pushes obj one step in dir. Ice keeps sliding cell by cell
+// until the next cell is blocked or off the grid, or it runs into a flame,
+// a hot pot, a hole or sticky floor, while any other object moves a single
+// cell. An object entering a portal comes out of its twin, where sliding
+// ice keeps going, turns back or stops as the engine's momentum says; wide
+// objects don't fit through portals. Sliding ice crossing the draft of a
+// fan is blown one cell aside and slides on. Cracked floor the object
+// crossed gives way behind it. The returned move has an empty path when
+// obj could not move at all.
+//
+// Paths follow the object's position, its top left cell; a wide object
+// moves only if every cell it covers can.
+func (e *PhysicsEngine) MoveObject(obj sprites.Sprite, dir utils.Direction) Move {
+	move := Move{Object: obj, From: obj.Position()}
+	pos := obj.Position()
+	used := make(map[*sprites.Portal]bool)
+	blown := make(map[*sprites.Fan]bool)
+	for {
+		if !e.canStep(obj, pos, dir) {
+			break
+		}
+		pos = pos.Step(dir)
+		move.Path = append(move.Path, pos)
+		exit, teleported := e.teleport(obj, pos, dir, used)
+		if teleported {
+			pos = exit
+			move.Path = append(move.Path, pos)
+		}
+		if !slides(obj) && !e.skids(obj, pos) || e.absorbs(obj, pos) || e.sticky(obj, pos) {
+			break
+		}
+		if teleported {
+			if e.momentum == MomentumStop {
+				break
+			}
+			if e.momentum == MomentumReverse {
+				dir = dir.Opposite()
+			}
+		}
+		if gust, ok := e.deflect(obj, pos, dir, blown); ok {
+			pos = gust
+			move.Path = append(move.Path, pos)
+			if e.absorbs(obj, pos) || e.sticky(obj, pos) {
+				break
+			}
+		}
+		if e.gravity && dir != utils.Down && !e.supported(obj, pos) {
+			// ice sliding off a ledge drops instead of flying on
+			break
+		}
+	}
+	if move.Moved() {
+		obj.SetPosition(pos)
+		e.crumble(obj, append([]utils.Cell{move.From}, move.Path[:len(move.Path)-1]...))
+	}
+	return move
+}
+
+// canStep reports whether obj at pos may take one step in dir: every cell
+// it covers must be free to leave and the cells ahead free to enter
+func (e *PhysicsEngine) canStep(obj sprites.Sprite, pos utils.Cell, dir utils.Direction) bool {
+	for _, cell := range sprites.Cells(obj, pos) {
+		if !e.canLeave(cell, dir) || !e.isPositionValid(obj, cell.Step(dir), dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// crumble breaks the cracked floor in the cells obj has just crossed from
+// the given positions, leaving holes behind; cells it still covers hold
+func (e *PhysicsEngine) crumble(obj sprites.Sprite, from []utils.Cell) {
+	var cells []utils.Cell
+	for _, pos := range from {
+		for _, cell := range sprites.Cells(obj, pos) {
+			if !sprites.Covers(obj, cell) && !slices.Contains(cells, cell) {
+				cells = append(cells, cell)
+			}
+		}
+	}
+	for _, pos := range cells {
+		for _, obj := range e.ObjectsAt(pos) {
+			if _, ok := obj.(*sprites.CrackedFloor); ok {
+				e.Remove(obj)
+				e.Add(sprites.NewHole(pos.X, pos.Y))
+			}
+		}
+	}
+}
+
+// MovePlayer steps player one cell in dir. Walking into a pushable block
+// pushes it instead, leaving the player in place: ice slides on, stones
+// move a single cell. Stepping off sticky floor takes two tries, the
+// first of which still takes a turn but leaves the player's move with an
+// empty path. With gravity on, the
+// player only walks sideways and whatever lost its support falls.
+func (e *PhysicsEngine) MovePlayer(player sprites.Sprite, dir utils.Direction) []Move {
+	if e.gravity && !dir.Horizontal() {
+		return nil
+	}
+	moves := e.movePlayer(player, dir)
+	if len(moves) > 0 {
+		moves = merge(moves, e.convey())
+	}
+	if e.gravity && len(moves) > 0 {
+		moves = merge(moves, e.Settle())
+	}
+	return moves
+}
+
+// convey lets every conveyor push whatever rests on it one cell along the
+// belt. Ice pushed off a belt slides on as if the player had pushed it.
+// Each object is carried at most once, so belts facing each other cannot
+// keep it spinning.
+func (e *PhysicsEngine) convey() []Move {
+	var moves []Move
+	carried := make(map[sprites.Sprite]bool)
+	for _, obj := range slices.Clone(e.objects) {
+		conveyor, ok := obj.(*sprites.Conveyor)
+		if !ok {
+			continue
+		}
+		for _, other := range e.ObjectsAt(conveyor.Position()) {
+			if carried[other] || !falls(other) {
+				continue
+			}
+			carried[other] = true
+			if m := e.MoveObject(other, conveyor.Dir); m.Moved() {
+				moves = merge(moves, []Move{m})
+			}
+		}
+	}
+	return moves
+}
+
+func (e *PhysicsEngine) movePlayer(player sprites.Sprite, dir utils.Direction) []Move {
+	target := player.Position().Step(dir)
+	for _, obj := range e.ObjectsAt(target) {
+		if pushable(obj) {
+			return e.push(obj, dir)
+		}
+	}
+	if e.sticky(player, player.Position()) && e.tugged != player && e.canStep(player, player.Position(), dir) {
+		// the first try only pulls the player's feet loose
+		e.tugged = player
+		return []Move{{Object: player, From: player.Position()}}
+	}
+	if m := e.MoveObject(player, dir); m.Moved() {
+		e.tugged = nil
+		return []Move{m}
+	}
+	return nil
+}
+
+// push moves obj in dir. With chains on, ice that ends up against another
+// block of ice hands the push on to it, and so on down the line; each
+// block is pushed at most once, so portals cannot loop a chain.
+func (e *PhysicsEngine) push(obj sprites.Sprite, dir utils.Direction) []Move {
+	var moves []Move
+	pushed := make(map[sprites.Sprite]bool)
+	for obj != nil && !pushed[obj] {
+		pushed[obj] = true
+		if m := e.MoveObject(obj, dir); m.Moved() {
+			moves = append(moves, m)
+		}
+		if !e.chains || !slides(obj) || e.absorbs(obj, obj.Position()) {
+			break
+		}
+		obj = e.struck(obj, dir)
+	}
+	return moves
+}
+
+// struck returns the ice just ahead of obj in dir that a chain can pass a
+// push on to
+func (e *PhysicsEngine) struck(obj sprites.Sprite, dir utils.Direction) sprites.Sprite {
+	for _, cell := range sprites.Cells(obj, obj.Position()) {
+		for _, other := range e.ObjectsAt(cell.Step(dir)) {
+			if other != obj && isIce(other) {
+				return other
+			}
+		}
+	}
+	return nil
+}
+
+// Settle drops every unsupported player and ice block, lowest first, until
+// all of them rest on something. It does nothing without gravity.
+func (e *PhysicsEngine) Settle() []Move {
+	if !e.gravity {
+		return nil
+	}
+	var moves []Move
+	for changed := true; changed; {
+		changed = false
+		falling := slices.Clone(e.objects)
+		slices.SortStableFunc(falling, func(a, b sprites.Sprite) int {
+			return b.Position().Y - a.Position().Y
+		})
+		for _, obj := range falling {
+			if !falls(obj) || !e.onGrid(obj) {
+				continue
+			}
+			for !e.supported(obj, obj.Position()) && !e.absorbs(obj, obj.Position()) {
+				m := e.MoveObject(obj, utils.Down)
+				if !m.Moved() {
+					break
+				}
+				moves = merge(moves, []Move{m})
+				changed = true
+			}
+		}
+	}
+	return moves
+}
+
+// supported reports whether obj at pos rests on the bottom edge or on
+// something it cannot fall through, under any of the cells it covers
+func (e *PhysicsEngine) supported(obj sprites.Sprite, pos utils.Cell) bool {
+	return !e.canStep(obj, pos, utils.Down)
+}
+
+func (e *PhysicsEngine) onGrid(obj sprites.Sprite) bool {
+	return slices.Contains(e.objects, obj)
+}
+
+// merge appends the moves in more to moves, extending the path of an
+// object that already moved rather than adding a second move for it
+func merge(moves, more []Move) []Move {
+	for _, m := range more {
+		i := slices.IndexFunc(moves, func(prev Move) bool { return prev.Object == m.Object })
+		if i < 0 {
+			moves = append(moves, m)
+			continue
+		}
+		moves[i].Path = append(moves[i].Path, m.Path...)
+	}
+	return moves
+}
+
+// Place sets obj down at pos, as long as every cell it would cover is on
+// the grid and free for it, and reports whether it did
+func (e *PhysicsEngine) Place(obj sprites.Sprite, pos utils.Cell) bool {
+	for _, cell := range sprites.Cells(obj, pos) {
+		if !e.InBounds(cell) {
+			return false
+		}
+		for _, other := range e.ObjectsAt(cell) {
+			if other != obj && blocks(other, obj) {
+				return false
+			}
+		}
+	}
+	obj.SetPosition(pos)
+	return true
+}
+
+// Add places obj on the grid
+func (e *PhysicsEngine) Add(obj sprites.Sprite) {
+	e.objects = append(e.objects, obj)
+}
+
+// Remove takes obj off the grid
+func (e *PhysicsEngine) Remove(obj sprites.Sprite) {
+	for i, other := range e.objects {
+		if other == obj {
+			e.objects = append(e.objects[:i], e.objects[i+1:]...)
+			return
+		}
+	}
+}
+
+// isPositionValid reports whether obj may enter pos moving in dir
+func (e *PhysicsEngine) isPositionValid(obj sprites.Sprite, pos utils.Cell, dir utils.Direction) bool {
+	if !e.InBounds(pos) {
+		return false
+	}
+	for _, other := range e.ObjectsAt(pos) {
+		if oneWay, ok := other.(*sprites.OneWay); ok && oneWay.Dir != dir {
+			return false
+		}
+		if other != obj && blocks(other, obj) {
+			return false
+		}
+	}
+	return true
+}
+
+// canLeave reports whether an object in pos may move out of it in dir,
+// which one-way tiles only allow along their arrow
+func (e *PhysicsEngine) canLeave(pos utils.Cell, dir utils.Direction) bool {
+	for _, other := range e.ObjectsAt(pos) {
+		if oneWay, ok := other.(*sprites.OneWay); ok && oneWay.Dir != dir {
+			return false
+		}
+	}
+	return true
+}
+
+// teleport returns where obj comes out after entering a portal at pos.
+// Each portal is used at most once per move, so portals facing each other
+// cannot trap sliding ice, and an occupied exit leaves obj on the entry.
+// Wide objects don't fit through portals.
+func (e *PhysicsEngine) teleport(obj sprites.Sprite, pos utils.Cell, dir utils.Direction, used map[*sprites.Portal]bool) (utils.Cell, bool) {
+	if _, wide := obj.(sprites.Wide); wide || e.portals == nil {
+		return pos, false
+	}
+	for _, other := range e.ObjectsAt(pos) {
+		portal, ok := other.(*sprites.Portal)
+		if !ok || used[portal] {
+			continue
+		}
+		twin := e.portals.Twin(portal)
+		if twin == nil {
+			continue
+		}
+		used[portal], used[twin] = true, true
+		if !e.isPositionValid(obj, twin.Position(), dir) {
+			return pos, false
+		}
+		return twin.Position(), true
+	}
+	return pos, false
+}
+
+// deflect returns where sliding obj ends up when the draft of a fan
+// blowing across its path at pos pushes it one cell aside. Each fan
+// deflects obj at most once per move, so opposing fans cannot trap it.
+func (e *PhysicsEngine) deflect(obj sprites.Sprite, pos utils.Cell, dir utils.Direction, blown map[*sprites.Fan]bool) (utils.Cell, bool) {
+	for _, other := range e.objects {
+		fan, ok := other.(*sprites.Fan)
+		if !ok || blown[fan] || fan.Dir.Horizontal() == dir.Horizontal() || !e.inDraft(fan, obj, pos) {
+			continue
+		}
+		blown[fan] = true
+		if !e.canStep(obj, pos, fan.Dir) {
+			continue
+		}
+		return pos.Step(fan.Dir), true
+	}
+	return pos, false
+}
+
+// inDraft reports whether obj at pos covers a cell fan blows through. The
+// draft runs from the fan in its direction until something solid shelters
+// the cells beyond.
+func (e *PhysicsEngine) inDraft(fan *sprites.Fan, obj sprites.Sprite, pos utils.Cell) bool {
+	cells := sprites.Cells(obj, pos)
+	for cell := fan.Position().Step(fan.Dir); e.InBounds(cell); cell = cell.Step(fan.Dir) {
+		if slices.Contains(cells, cell) {
+			return true
+		}
+		if slices.ContainsFunc(e.ObjectsAt(cell), shelters) {
+			return false
+		}
+	}
+	return false
+}
+
+// sticky reports whether obj at pos covers sticky floor
+func (e *PhysicsEngine) sticky(obj sprites.Sprite, pos utils.Cell) bool {
+	for _, cell := range sprites.Cells(obj, pos) {
+		for _, other := range e.ObjectsAt(cell) {
+			if _, ok := other.(*sprites.StickyFloor); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skids reports whether obj is a player sliding on over slippery frozen
+// ground at pos
+func (e *PhysicsEngine) skids(obj sprites.Sprite, pos utils.Cell) bool {
+	if _, ok := obj.(*sprites.Player); !ok || !e.slippery {
+		return false
+	}
+	for _, other := range e.ObjectsAt(pos) {
+		if _, ok := other.(*sprites.FrozenGround); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// absorbs reports whether obj comes to rest at pos: ice to put out a flame
+// or melt on a hot pot under any of its cells, and anything that falls to
+// drop into a hole or water
+func (e *PhysicsEngine) absorbs(obj sprites.Sprite, pos utils.Cell) bool {
+	for _, cell := range sprites.Cells(obj, pos) {
+		for _, other := range e.ObjectsAt(cell) {
+			switch other := other.(type) {
+			case *sprites.Hole, *sprites.Water:
+				if falls(obj) {
+					return true
+				}
+			case *sprites.Flame:
+				if isIce(obj) {
+					return true
+				}
+			case *sprites.Pot:
+				if isIce(obj) && other.Hot {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isIce reports whether obj is a block of ice, plain or fused
+func isIce(obj sprites.Sprite) bool {
+	switch obj.(type) {
+	case *sprites.Ice, *sprites.BigIce:
+		return true
+	default:
+		return false
+	}
+}
+
+// pushable reports whether the player can push obj
+func pushable(obj sprites.Sprite) bool {
+	switch obj.(type) {
+	case *sprites.Ice, *sprites.BigIce, *sprites.Stone, *sprites.Bomb:
+		return true
+	default:
+		return false
+	}
+}
+
+// falls reports whether gravity pulls obj down, which is also what belts
+// can carry
+func falls(obj sprites.Sprite) bool {
+	switch obj.(type) {
+	case *sprites.Ice, *sprites.BigIce, *sprites.Stone, *sprites.Bomb, *sprites.Player:
+		return true
+	default:
+		return false
+	}
+}
+
+// shelters reports whether obj stops the draft of a fan
+func shelters(obj sprites.Sprite) bool {
+	switch obj := obj.(type) {
+	case *sprites.Wall, *sprites.CrackedWall, *sprites.NPC, *sprites.Pot, *sprites.FakeWall, *sprites.Fan, *sprites.Lever:
+		return true
+	case *sprites.PhaseWall:
+		return obj.Solid
+	case *sprites.Door:
+		return !obj.Open
+	case *sprites.ToggleWall:
+		return !obj.Open
+	default:
+		return false
+	}
+}
+
+// slides reports whether obj keeps moving after a push
+func slides(obj sprites.Sprite) bool {
+	return isIce(obj)
+}
+
+// blocks reports whether other stops mover from entering its cell
+func blocks(other, mover sprites.Sprite) bool {
+	_, isPlayer := mover.(*sprites.Player)
+	isIce := isIce(mover)
+	_, isEnemy := mover.(*sprites.Enemy)
+	_, isBomb := mover.(*sprites.Bomb)
+	switch other := other.(type) {
+	case *sprites.Wall, *sprites.CrackedWall, *sprites.Stone, *sprites.Bomb, *sprites.NPC, *sprites.Ice, *sprites.BigIce, *sprites.Fan, *sprites.Lever:
+		return true
+	case *sprites.PhaseWall:
+		return other.Solid
+	case *sprites.Player:
+		// enemies walk right into the player to catch them
+		return !isEnemy
+	case *sprites.Enemy:
+		// the player walks into an enemy's clutches, ice crushes it
+		return !isPlayer && !isIce
+	case *sprites.Hole, *sprites.Water:
+		return isEnemy
+	case *sprites.Pot:
+		return !isIce || !other.Hot
+	case *sprites.FakeWall, *sprites.Gem, *sprites.Coin, *sprites.Key:
+		return !isPlayer
+	case *sprites.Door:
+		return !other.Open
+	case *sprites.ToggleWall:
+		return !other.Open
+	case *sprites.Flame:
+		// bombs go off in the flame
+		return !isIce && !isBomb
+	default:
+		return false
+	}
+}
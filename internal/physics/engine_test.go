@@ -0,0 +1,187 @@
+package physics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// build lays out grid as the level loader does, for the characters the
+// tests use. Digits are portals, linked in pairs by digit. Blank cells are
+// spaces or dots.
+func build(t testing.TB, grid string) (*physics.PhysicsEngine, *sprites.Player) {
+	t.Helper()
+	rows := strings.Split(strings.TrimSpace(grid), "\n")
+	var objects []sprites.Sprite
+	var player *sprites.Player
+	portals := make(map[rune][]*sprites.Portal)
+	for y, row := range rows {
+		for x, ch := range row {
+			switch ch {
+			case '#':
+				objects = append(objects, sprites.NewWall(x, y))
+			case 'I':
+				objects = append(objects, sprites.NewIce(x, y))
+			case 'S':
+				objects = append(objects, sprites.NewStone(x, y))
+			case 'F':
+				objects = append(objects, sprites.NewFlame(x, y))
+			case 'G':
+				objects = append(objects, sprites.NewGem(x, y))
+			case 'C':
+				objects = append(objects, sprites.NewCoin(x, y))
+			case 'K':
+				objects = append(objects, sprites.NewKey(x, y, sprites.KeyRed))
+			case 'H':
+				objects = append(objects, sprites.NewFakeWall(x, y))
+			case 'P':
+				player = sprites.NewPlayer(x, y)
+				objects = append(objects, player)
+			case '1', '2', '3':
+				portal := sprites.NewPortal(ch, x, y)
+				portals[ch] = append(portals[ch], portal)
+				objects = append(objects, portal)
+			case ' ', '.':
+			default:
+				t.Fatalf("unexpected %q in grid", ch)
+			}
+		}
+	}
+	e := physics.NewPhysicsEngine(len(rows[0]), len(rows), objects)
+	e.SetPortals(physics.NewPortalSystem(portals))
+	return e, player
+}
+
+// render draws e back in the characters build reads, for objects placed
+// with build's characters
+func render(e *physics.PhysicsEngine) string {
+	width, height := e.Size()
+	rows := make([][]rune, height)
+	for y := range rows {
+		rows[y] = []rune(strings.Repeat(".", width))
+	}
+	for _, obj := range e.Objects() {
+		pos := obj.Position()
+		switch obj := obj.(type) {
+		case *sprites.Wall:
+			rows[pos.Y][pos.X] = '#'
+		case *sprites.Ice:
+			rows[pos.Y][pos.X] = 'I'
+		case *sprites.Stone:
+			rows[pos.Y][pos.X] = 'S'
+		case *sprites.Flame:
+			rows[pos.Y][pos.X] = 'F'
+		case *sprites.Gem:
+			rows[pos.Y][pos.X] = 'G'
+		case *sprites.FakeWall:
+			rows[pos.Y][pos.X] = 'H'
+		case *sprites.Player:
+			rows[pos.Y][pos.X] = 'P'
+		case *sprites.Portal:
+			rows[pos.Y][pos.X] = obj.ID
+		}
+	}
+	lines := make([]string, height)
+	for y, row := range rows {
+		lines[y] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ice returns the first ice block on e
+func ice(t testing.TB, e *physics.PhysicsEngine) *sprites.Ice {
+	t.Helper()
+	for _, obj := range e.Objects() {
+		if ice, ok := obj.(*sprites.Ice); ok {
+			return ice
+		}
+	}
+	t.Fatal("no ice in grid")
+	return nil
+}
+
+func TestMoveObject(t *testing.T) {
+	tests := []struct {
+		name  string
+		grid  string
+		dir   utils.Direction
+		want  utils.Cell
+		cells int
+	}{
+		{"ice slides to a wall", "#I...#", utils.Right, utils.Cell{X: 4}, 3},
+		{"ice slides to the edge", "I...", utils.Right, utils.Cell{X: 3}, 3},
+		{"ice against a wall stays", "#I..", utils.Left, utils.Cell{X: 1}, 0},
+		{"ice stops at ice", "I..I", utils.Right, utils.Cell{X: 2}, 2},
+		{"ice stops at a stone", "I..S", utils.Right, utils.Cell{X: 2}, 2},
+		{"ice stops at a fake wall", "I..H", utils.Right, utils.Cell{X: 2}, 2},
+		{"ice stops at a gem", "I..G", utils.Right, utils.Cell{X: 2}, 2},
+		{"ice stops in a flame", "I.F.#", utils.Right, utils.Cell{X: 2}, 2},
+		{"ice slides down", "I\n.\n.\n#", utils.Down, utils.Cell{Y: 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := build(t, tt.grid)
+			obj := ice(t, e)
+			move := e.MoveObject(obj, tt.dir)
+			if move.To() != tt.want || len(move.Path) != tt.cells {
+				t.Errorf("ice ended at %v after %d cells, want %v after %d", move.To(), len(move.Path), tt.want, tt.cells)
+			}
+			if obj.Position() != move.To() {
+				t.Errorf("ice stands at %v, move ended at %v", obj.Position(), move.To())
+			}
+		})
+	}
+}
+
+func TestMoveObjectPlayer(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+		want utils.Cell
+	}{
+		{"steps a single cell", "P...", utils.Cell{X: 1}},
+		{"walks through a fake wall", "PH..", utils.Cell{X: 1}},
+		{"walks onto a gem", "PG..", utils.Cell{X: 1}},
+		{"is stopped by a wall", "P#..", utils.Cell{}},
+		{"is stopped by a flame", "PF..", utils.Cell{}},
+		{"is stopped by ice", "PI..", utils.Cell{}},
+		{"stays on the grid", "P", utils.Cell{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, player := build(t, tt.grid)
+			if move := e.MoveObject(player, utils.Right); move.To() != tt.want {
+				t.Errorf("player ended at %v, want %v", move.To(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPushChains(t *testing.T) {
+	tests := []struct {
+		name   string
+		grid   string
+		chains bool
+		want   string
+	}{
+		{"off: ice stops against ice", "PI.I..#", false, "P.II..#"},
+		{"on: the struck block slides off", "PI.I..#", true, "P.I..I#"},
+		{"on: a touching block passes the push", "PII..#", true, "PI..I#"},
+		{"on: down a line of blocks", "PIII.#", true, "PII.I#"},
+		{"on: a blocked line stays", "PII#", true, "PII#"},
+		{"on: the last block stopped short", "PI.I#", true, "P.II#"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, player := build(t, tt.grid)
+			e.SetChains(tt.chains)
+			e.MovePlayer(player, utils.Right)
+			if got := render(e); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package physics
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/sprites"
+)
+
+// PortalSystem links portals sharing an ID in pairs, so anything entering
+// one comes out of its twin
+type PortalSystem struct {
+	twins map[*sprites.Portal]*sprites.Portal
+}
+
+// NewPortalSystem pairs the portals of each ID in the order they were
+// parsed. A portal left without a twin never teleports.
+func NewPortalSystem(portals map[rune][]*sprites.Portal) *PortalSystem {
+	s := &PortalSystem{twins: make(map[*sprites.Portal]*sprites.Portal)}
+	for id, group := range portals {
+		if len(group)%2 != 0 {
+			log.Warn("portal without a twin", "id", string(id), "count", len(group))
+		}
+		for i := 0; i+1 < len(group); i += 2 {
+			s.twins[group[i]] = group[i+1]
+			s.twins[group[i+1]] = group[i]
+		}
+	}
+	return s
+}
+
+// Twin returns the portal linked to p, or nil when p has none
+func (s *PortalSystem) Twin(p *sprites.Portal) *sprites.Portal {
+	return s.twins[p]
+}
+
+// Momentum is what becomes of a sliding block coming out of a portal
+type Momentum int
+
+const (
+	// MomentumKeep slides on the way the block entered
+	MomentumKeep Momentum = iota
+	// MomentumReverse slides back the way the block came
+	MomentumReverse
+	// MomentumStop leaves the block resting on the exit portal
+	MomentumStop
+)
+
+var momentumNames = [...]string{
+	MomentumKeep:    "keep",
+	MomentumReverse: "reverse",
+	MomentumStop:    "stop",
+}
+
+func (m Momentum) String() string {
+	return momentumNames[m]
+}
+
+// ParseMomentum returns the momentum named s; empty means keep
+func ParseMomentum(s string) (Momentum, bool) {
+	if s == "" {
+		return MomentumKeep, true
+	}
+	for m, name := range momentumNames {
+		if s == name {
+			return Momentum(m), true
+		}
+	}
+	return MomentumKeep, false
+}
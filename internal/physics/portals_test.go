@@ -0,0 +1,71 @@
+package physics_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+func TestPortals(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+		// player moves the player instead of the ice, and blocked puts a
+		// stone on the last portal
+		player  bool
+		blocked bool
+		path    []utils.Cell
+	}{
+		{
+			name: "ice slides on out of the twin",
+			grid: "I1..1..#",
+			path: []utils.Cell{{X: 1}, {X: 4}, {X: 5}, {X: 6}},
+		},
+		{
+			name:   "player comes out of the twin",
+			grid:   "P1.1..",
+			player: true,
+			path:   []utils.Cell{{X: 1}, {X: 3}},
+		},
+		{
+			name:    "ice passes over a portal whose twin is blocked",
+			grid:    "I1..1.",
+			blocked: true,
+			path:    []utils.Cell{{X: 1}, {X: 2}, {X: 3}},
+		},
+		{
+			name: "portal without a twin",
+			grid: "I.1..#",
+			path: []utils.Cell{{X: 1}, {X: 2}, {X: 3}, {X: 4}},
+		},
+		{
+			name: "each portal once per move",
+			grid: "1I1#",
+			path: []utils.Cell{{X: 2}, {X: 0}, {X: 1}, {X: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, player := build(t, tt.grid)
+			if tt.blocked {
+				var exit sprites.Sprite
+				for _, obj := range e.Objects() {
+					if _, ok := obj.(*sprites.Portal); ok {
+						exit = obj
+					}
+				}
+				e.Add(sprites.NewStone(exit.Position().X, exit.Position().Y))
+			}
+			var obj sprites.Sprite = player
+			if !tt.player {
+				obj = ice(t, e)
+			}
+			move := e.MoveObject(obj, utils.Right)
+			if !slices.Equal(move.Path, tt.path) {
+				t.Errorf("path %v, want %v", move.Path, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package physics
+
+import (
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Snapshot records which objects are on the grid and where they stand
+type Snapshot struct {
+	objects   []sprites.Sprite
+	positions []utils.Cell
+}
+
+// Snapshot captures the current state so it can be restored later
+func (e *PhysicsEngine) Snapshot() Snapshot {
+	s := Snapshot{
+		objects:   append([]sprites.Sprite(nil), e.objects...),
+		positions: make([]utils.Cell, len(e.objects)),
+	}
+	for i, obj := range e.objects {
+		s.positions[i] = obj.Position()
+	}
+	return s
+}
+
+// Restore puts every object back where s recorded it, bringing back
+// removed objects and dropping ones added since
+func (e *PhysicsEngine) Restore(s Snapshot) {
+	e.objects = append(e.objects[:0], s.objects...)
+	for i, obj := range e.objects {
+		obj.SetPosition(s.positions[i])
+	}
+	e.tugged = nil
+}
@@ -0,0 +1,46 @@
+package physics
+
+import "math/rand/v2"
+
+// zobrist holds a random key for the player, a block, a flame and a pickup
+// in each cell, so a board's hash follows its moves with a few XORs. Keys
+// come from a fixed seed, so equal boards hash alike in every run and on
+// every machine.
+type zobrist struct {
+	player  []uint64
+	blocks  []uint64
+	flames  []uint64
+	pickups []uint64
+}
+
+func newZobrist(cells int) *zobrist {
+	r := rand.New(rand.NewPCG(uint64(cells), 0x1ce))
+	keys := func() []uint64 {
+		res := make([]uint64, cells)
+		for i := range res {
+			res[i] = r.Uint64()
+		}
+		return res
+	}
+	return &zobrist{player: keys(), blocks: keys(), flames: keys(), pickups: keys()}
+}
+
+// hash computes the hash of b from scratch
+func (z *zobrist) hash(b *Board) uint64 {
+	var h uint64
+	if b.Player >= 0 {
+		h ^= z.player[b.Player]
+	}
+	for i := range b.Width * b.Height {
+		if b.Blocks.Has(i) {
+			h ^= z.blocks[i]
+		}
+		if b.Flames.Has(i) {
+			h ^= z.flames[i]
+		}
+		if b.Pickups.Has(i) {
+			h ^= z.pickups[i]
+		}
+	}
+	return h
+}
@@ -0,0 +1,82 @@
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// versionKey holds the format version of a profile file; files without it
+// are at version 0
+const versionKey = "version"
+
+// ErrTooNew is returned for files saved by a newer version of the game
+var ErrTooNew = errors.New("saved by a newer version of the game")
+
+// Migration upgrades a decoded profile file by one version, from the
+// version of its index in a list of migrations to the next. Migrations
+// work on the raw document, so they can rename, move and drop keys the
+// current format no longer has.
+type Migration func(doc map[string]any) error
+
+// Migrate brings doc up to date by running every migration past its
+// version, and reports whether any ran. The latest version is the number
+// of migrations.
+func Migrate(doc map[string]any, migrations []Migration) (bool, error) {
+	version, err := docVersion(doc)
+	if err != nil {
+		return false, err
+	}
+	if version > len(migrations) {
+		return false, fmt.Errorf("%w: format version %d, this game supports %d", ErrTooNew, version, len(migrations))
+	}
+	for v := version; v < len(migrations); v++ {
+		if err := migrations[v](doc); err != nil {
+			return false, fmt.Errorf("migrating from version %d: %w", v, err)
+		}
+		doc[versionKey] = int64(v + 1)
+	}
+	return version < len(migrations), nil
+}
+
+// Decode reads TOML data into v after migrating it, and reports whether
+// it needed migrating
+func Decode(data []byte, migrations []Migration, v any) (bool, error) {
+	doc := make(map[string]any)
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	migrated, err := Migrate(doc, migrations)
+	if err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+		return false, err
+	}
+	return migrated, toml.Unmarshal(buf.Bytes(), v)
+}
+
+// DecodeFile reads the profile file name into v like Decode
+func DecodeFile(name string, migrations []Migration, v any) (bool, error) {
+	data, err := ReadFile(name)
+	if err != nil {
+		return false, err
+	}
+	return Decode(data, migrations, v)
+}
+
+// docVersion returns the format version doc was saved at
+func docVersion(doc map[string]any) (int, error) {
+	raw, ok := doc[versionKey]
+	if !ok {
+		return 0, nil
+	}
+	v, ok := raw.(int64)
+	if !ok || v < 0 {
+		return 0, fmt.Errorf("bad format version %v", raw)
+	}
+	return int(v), nil
+}
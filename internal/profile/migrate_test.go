@@ -0,0 +1,114 @@
+package profile
+
+import (
+	"errors"
+	"testing"
+)
+
+// renameMigrations move "old" to "new" at version 0, then double "new"
+var renameMigrations = []Migration{
+	func(doc map[string]any) error {
+		doc["new"] = doc["old"]
+		delete(doc, "old")
+		return nil
+	},
+	func(doc map[string]any) error {
+		n, _ := doc["new"].(int64)
+		doc["new"] = n * 2
+		return nil
+	},
+}
+
+type renamed struct {
+	Version int `toml:"version"`
+	New     int `toml:"new"`
+}
+
+func TestDecodeVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     renamed
+		migrated bool
+		err      error
+	}{
+		{"unversioned", "old = 3", renamed{Version: 2, New: 6}, true, nil},
+		{"version 1", "version = 1\nnew = 3", renamed{Version: 2, New: 6}, true, nil},
+		{"current", "version = 2\nnew = 3", renamed{Version: 2, New: 3}, false, nil},
+		{"too new", "version = 3\nnew = 3", renamed{}, false, ErrTooNew},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got renamed
+			migrated, err := Decode([]byte(tt.data), renameMigrations, &got)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Decode error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want || migrated != tt.migrated {
+				t.Errorf("Decode = %+v, migrated %v; want %+v, migrated %v", got, migrated, tt.want, tt.migrated)
+			}
+		})
+	}
+}
+
+func TestDecodeBadVersion(t *testing.T) {
+	for _, data := range []string{`version = "1"`, "version = -1"} {
+		var got renamed
+		if _, err := Decode([]byte(data), renameMigrations, &got); err == nil || errors.Is(err, ErrTooNew) {
+			t.Errorf("Decode(%q) error = %v, want a bad version", data, err)
+		}
+	}
+}
+
+func TestMigrationError(t *testing.T) {
+	broken := errors.New("broken")
+	migrations := []Migration{func(map[string]any) error { return broken }}
+	var got renamed
+	if _, err := Decode([]byte("old = 1"), migrations, &got); !errors.Is(err, broken) {
+		t.Errorf("Decode error = %v, want %v", err, broken)
+	}
+}
+
+// TestArrayOfTables pins down what migrations are handed for lists of
+// records: [[tables]] come as []map[string]any, inline arrays as []any
+func TestArrayOfTables(t *testing.T) {
+	for data, want := range map[string]string{
+		"[[levels]]\nid = \"a\"\n[[levels]]\nid = \"b\"": "tables",
+		"levels = [{id = \"a\"}, {id = \"b\"}]":          "inline",
+	} {
+		migrations := []Migration{func(doc map[string]any) error {
+			switch levels := doc["levels"].(type) {
+			case []map[string]any:
+				if want != "tables" || len(levels) != 2 {
+					t.Errorf("%q: got %d tables", data, len(levels))
+				}
+			case []any:
+				if want != "inline" || len(levels) != 2 {
+					t.Errorf("%q: got %d inline tables", data, len(levels))
+				}
+			default:
+				t.Errorf("%q: levels decoded as %T", data, levels)
+			}
+			return nil
+		}}
+		var v struct{}
+		if _, err := Decode([]byte(data), migrations, &v); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := WriteFile("renamed.toml", []byte("old = 5")); err != nil {
+		t.Fatal(err)
+	}
+	var got renamed
+	migrated, err := DecodeFile("renamed.toml", renameMigrations, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !migrated || got.New != 10 {
+		t.Errorf("DecodeFile = %+v, migrated %v", got, migrated)
+	}
+}
@@ -0,0 +1,288 @@
+// Package profile keeps the files that make up a player's profile, such as
+// their progress, and moves them between machines as a single archive.
+package profile
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// FormatVersion is the version of the archive format
+	FormatVersion = 1
+	// Backups is how many automatic backups are kept
+	Backups = 5
+
+	manifestName = "manifest.toml"
+	backupDir    = "backups"
+	backupLayout = "20060102-150405"
+	// tempSuffix ends files being written, which are not yet part of the
+	// profile
+	tempSuffix = ".tmp"
+)
+
+// ErrCorrupt is returned for archives that fail their integrity check
+var ErrCorrupt = errors.New("profile archive is corrupt")
+
+// Manifest describes an archive and the checksum of every file in it
+type Manifest struct {
+	Version int               `toml:"version"`
+	Created time.Time         `toml:"created"`
+	Files   map[string]string `toml:"files"`
+}
+
+// Dir returns the directory holding the profile
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "icer"), nil
+}
+
+// Path returns where the profile file name is kept
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// files reads every profile file, keyed by its slash separated path
+// relative to dir. Backups are not part of the profile.
+func files(dir string) (map[string][]byte, error) {
+	res := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == backupDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(rel, tempSuffix) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		res[rel] = data
+		return nil
+	})
+	return res, err
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Export writes the whole profile to w as a zip archive with a manifest
+func Export(w io.Writer) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	return export(dir, w)
+}
+
+func export(dir string, w io.Writer) error {
+	contents, err := files(dir)
+	if err != nil {
+		return err
+	}
+	manifest := Manifest{
+		Version: FormatVersion,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Files:   make(map[string]string),
+	}
+	for name, data := range contents {
+		manifest.Files[name] = checksum(data)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(manifest); err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	names := append([]string{manifestName}, slices.Sorted(maps.Keys(contents))...)
+	contents[manifestName] = buf.Bytes()
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(contents[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Import replaces the profile with the one in the archive r of the given
+// size. The archive is checked in full before anything is touched, and
+// the current profile is backed up first.
+func Import(r io.ReaderAt, size int64) error {
+	contents, err := read(r, size)
+	if err != nil {
+		return err
+	}
+	if _, err := Backup(); err != nil {
+		return fmt.Errorf("backing up before import: %w", err)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	return replace(dir, contents)
+}
+
+// read unpacks an archive and verifies it against its manifest
+func read(r io.ReaderAt, size int64) (map[string][]byte, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	contents := make(map[string][]byte)
+	for _, f := range zr.File {
+		if !fs.ValidPath(f.Name) || strings.HasPrefix(f.Name, backupDir+"/") {
+			return nil, fmt.Errorf("%w: unexpected file %q", ErrCorrupt, f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		contents[f.Name] = data
+	}
+	raw, ok := contents[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("%w: no manifest", ErrCorrupt)
+	}
+	delete(contents, manifestName)
+	var manifest Manifest
+	if err := toml.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("profile archive version %d is newer than this game supports (%d)", manifest.Version, FormatVersion)
+	}
+	if len(manifest.Files) != len(contents) {
+		return nil, fmt.Errorf("%w: manifest lists %d files, archive holds %d", ErrCorrupt, len(manifest.Files), len(contents))
+	}
+	for name, data := range contents {
+		if manifest.Files[name] != checksum(data) {
+			return nil, fmt.Errorf("%w: checksum mismatch for %s", ErrCorrupt, name)
+		}
+	}
+	return contents, nil
+}
+
+// replace swaps the profile files in dir for contents, keeping backups
+func replace(dir string, contents map[string][]byte) error {
+	old, err := files(dir)
+	if err != nil {
+		return err
+	}
+	for name := range old {
+		if _, ok := contents[name]; !ok {
+			if err := os.Remove(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+				return err
+			}
+		}
+	}
+	for name, data := range contents {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := writeAtomic(p, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backup saves the current profile as a new archive among the rolling
+// backups, dropping the oldest beyond Backups, and returns its path
+func Backup() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	backups := filepath.Join(dir, backupDir)
+	if err := os.MkdirAll(backups, 0o755); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := export(dir, &buf); err != nil {
+		return "", err
+	}
+	name := filepath.Join(backups, time.Now().Format(backupLayout)+".zip")
+	if err := writeAtomic(name, buf.Bytes()); err != nil {
+		return "", err
+	}
+	return name, prune(backups)
+}
+
+// prune removes the oldest backups beyond Backups. Backup names sort by
+// the time they were taken.
+func prune(backups string) error {
+	names, err := backupNames(backups)
+	if err != nil {
+		return err
+	}
+	for len(names) > Backups {
+		if err := os.Remove(filepath.Join(backups, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// backupNames lists the backups in the folder backups, oldest first
+func backupNames(backups string) ([]string, error) {
+	entries, err := os.ReadDir(backups)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && path.Ext(e.Name()) == ".zip" {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,155 @@
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sealPrefix starts the first line of a sealed file, followed by the
+// checksum of the rest. Being a TOML comment, it leaves the file readable
+// by hand.
+const sealPrefix = "# sha256 "
+
+// brokenSuffix is added to a damaged file set aside by Recover, so it can
+// still be looked at
+const brokenSuffix = ".broken"
+
+var (
+	// ErrDamaged is returned for profile files whose checksum doesn't match
+	ErrDamaged = errors.New("profile file is damaged")
+	// ErrNoBackup is returned when no backup holds a good copy of a file
+	ErrNoBackup = errors.New("no good backup found")
+)
+
+// WriteFile saves the profile file name sealed with a checksum. The data
+// goes to a temporary file first, which then replaces the old one in a
+// single rename, so a crash mid-write leaves the old file whole.
+func WriteFile(name string, data []byte) error {
+	p, err := Path(name)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(p, seal(data))
+}
+
+// ReadFile reads the profile file name, checking its checksum when it has
+// one. Files written by hand have none and are read as they are.
+func ReadFile(name string) ([]byte, error) {
+	p, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return unseal(data)
+}
+
+// Recover sets the damaged profile file name aside and puts back the copy
+// from the newest backup whose data valid accepts, returning when that
+// backup was taken
+func Recover(name string, valid func([]byte) error) (time.Time, error) {
+	p, err := Path(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := os.Rename(p, p+brokenSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, err
+	}
+	dir := filepath.Dir(p)
+	names, err := backupNames(filepath.Join(dir, backupDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, err
+	}
+	for _, backup := range slices.Backward(names) {
+		raw, err := os.ReadFile(filepath.Join(dir, backupDir, backup))
+		if err != nil {
+			continue
+		}
+		contents, err := read(bytes.NewReader(raw), int64(len(raw)))
+		if err != nil {
+			continue
+		}
+		data, ok := contents[filepath.ToSlash(name)]
+		if !ok {
+			continue
+		}
+		if body, err := unseal(data); err != nil || valid(body) != nil {
+			continue
+		}
+		taken, _ := time.ParseInLocation(backupLayout, strings.TrimSuffix(backup, ".zip"), time.Local)
+		return taken, writeAtomic(p, data)
+	}
+	return time.Time{}, ErrNoBackup
+}
+
+// Checkpoint backs the profile up unless a backup was already taken
+// today, returning the new backup's path or "" when none was needed
+func Checkpoint() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	names, err := backupNames(filepath.Join(dir, backupDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	today := time.Now().Format(backupLayout[:8])
+	if len(names) > 0 && strings.HasPrefix(names[len(names)-1], today) {
+		return "", nil
+	}
+	return Backup()
+}
+
+// seal puts the checksum of data in front of it
+func seal(data []byte) []byte {
+	return append([]byte(sealPrefix+checksum(data)+"\n"), data...)
+}
+
+// unseal strips the checksum from data after checking it, or returns
+// data as it is when it has none
+func unseal(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(sealPrefix)) {
+		return data, nil
+	}
+	sum, body, ok := bytes.Cut(data[len(sealPrefix):], []byte("\n"))
+	if !ok || string(bytes.TrimSpace(sum)) != checksum(body) {
+		return nil, ErrDamaged
+	}
+	return body, nil
+}
+
+// writeAtomic replaces the file at p with data through a temporary file
+// in the same folder, synced before the rename
+func writeAtomic(p string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), p); err != nil {
+		return fmt.Errorf("replacing %s: %w", filepath.Base(p), err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/charmbracelet/log"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/text/language"
+)
+
+// fallbackFonts are system fonts looked for on each platform, in the
+// order they are tried: CJK first, then fonts covering many other scripts,
+// such as Arabic and Hebrew, then emoji. The first of each group found is
+// used. Emoji fonts made only of color bitmaps can't be drawn, so only
+// ones with outlines are listed.
+var fallbackFonts = map[string][][]string{
+	"linux": {
+		{
+			"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+			"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",
+			"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc",
+			"/usr/share/fonts/truetype/wqy/wqy-microhei.ttc",
+			"/usr/share/fonts/wenquanyi/wqy-microhei/wqy-microhei.ttc",
+		},
+		{
+			"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+			"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+			"/usr/share/fonts/TTF/DejaVuSans.ttf",
+		},
+		{
+			"/usr/share/fonts/truetype/noto/NotoEmoji-Regular.ttf",
+			"/usr/share/fonts/noto/NotoEmoji-Regular.ttf",
+		},
+	},
+	"windows": {
+		{`C:\Windows\Fonts\msyh.ttc`, `C:\Windows\Fonts\YuGothR.ttc`, `C:\Windows\Fonts\malgun.ttf`},
+		{`C:\Windows\Fonts\arial.ttf`},
+		{`C:\Windows\Fonts\seguiemj.ttf`},
+	},
+	"darwin": {
+		{"/System/Library/Fonts/PingFang.ttc", "/System/Library/Fonts/Hiragino Sans GB.ttc"},
+		{"/System/Library/Fonts/Supplemental/Arial Unicode.ttf"},
+		{"/System/Library/Fonts/Apple Symbols.ttf"},
+	},
+}
+
+// FontSet is a chain of fonts: each character is drawn with the first
+// font in the chain that has it, so text in any script the chain covers
+// shows up instead of as empty boxes
+type FontSet struct {
+	sources []*text.GoTextFaceSource
+}
+
+// NewFontSet returns the bundled font alone
+func NewFontSet() *FontSet {
+	s, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		panic(err)
+	}
+	return &FontSet{sources: []*text.GoTextFaceSource{s}}
+}
+
+// LoadFontSet returns the chain of the font file user, when given, the
+// bundled font, and whichever fallback fonts the system has. A user font
+// that can't be loaded is left out with an error logged.
+func LoadFontSet(user string) *FontSet {
+	set := NewFontSet()
+	if user != "" {
+		if s, err := loadFont(user); err != nil {
+			log.Error("cannot load font", "path", user, "err", err)
+		} else {
+			set.sources = append([]*text.GoTextFaceSource{s}, set.sources...)
+		}
+	}
+	for _, group := range fallbackFonts[runtime.GOOS] {
+		for _, path := range group {
+			if s, err := loadFont(path); err == nil {
+				set.sources = append(set.sources, s)
+				log.Debug("fallback font loaded", "path", path)
+				break
+			}
+		}
+	}
+	return set
+}
+
+// Face returns a face of the chain at size pixels, laid out in dir and
+// shaped for lang
+func (s *FontSet) Face(size float64, dir text.Direction, lang language.Tag) text.Face {
+	faces := make([]text.Face, len(s.sources))
+	for i, src := range s.sources {
+		faces[i] = &text.GoTextFace{Source: src, Size: size, Direction: dir, Language: lang}
+	}
+	if len(faces) == 1 {
+		return faces[0]
+	}
+	// the faces share a direction, the only thing that can make this fail
+	face, _ := text.NewMultiFace(faces...)
+	return face
+}
+
+// loadFont reads a TrueType or OpenType font, or the first font of a
+// collection
+func loadFont(path string) (*text.GoTextFaceSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if s, err := text.NewGoTextFaceSource(bytes.NewReader(data)); err == nil {
+		return s, nil
+	}
+	sources, err := text.NewGoTextFaceSourcesFromCollection(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%s holds no fonts", path)
+	}
+	return sources[0], nil
+}
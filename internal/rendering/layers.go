@@ -0,0 +1,24 @@
+package rendering
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/zrcoder/icer/internal/sprites"
+)
+
+// Layer draws over every board, such as a mod's overlays. Boards are drawn
+// one SpriteWidth x SpriteHeight cell per grid cell, before the view turns
+// or mirrors them, so layers line up with the objects whatever the view.
+type Layer interface {
+	// DrawLayer draws on board, given the objects on it, after the objects
+	// and beneath the focus ring, blasts and hints
+	DrawLayer(board *ebiten.Image, objects []sprites.Sprite)
+}
+
+var layers []Layer
+
+// RegisterLayer adds a layer drawn on every board, over the layers
+// registered before it. It must run before the game starts, typically from
+// an init function.
+func RegisterLayer(l Layer) {
+	layers = append(layers, l)
+}
@@ -0,0 +1,375 @@
+package rendering
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// ticksPerCell is how long an animated object takes to cross one cell
+	ticksPerCell = 4
+	// blastTicks is how long the flash of an explosion lasts
+	blastTicks = 18
+)
+
+// DefaultBackground is the board's color unless changed with SetBackground
+var DefaultBackground color.Color = colornames.Midnightblue
+
+// GameRenderer draws the level being played, centered on the screen, and
+// animates the moves reported by the physics engine
+type GameRenderer struct {
+	engine     *physics.PhysicsEngine
+	animations map[sprites.Sprite]*animation
+	tweens     utils.Tweens
+	board      *ebiten.Image
+	scratch    *ebiten.Image
+	// focus is the object ringed to stand out, if any
+	focus  sprites.Sprite
+	blasts []*blast
+	view   View
+	// hint marks the block to push next, and which way, if any
+	hint *hint
+	// plan is the numbered arrows of a whole solution, labeled with
+	// planFace, if any
+	plan     []Arrow
+	planFace text.Face
+	// background fills the board under its tiles
+	background color.Color
+}
+
+// hint points out a push: the block at pos, pushed in dir
+type hint struct {
+	pos utils.Cell
+	dir utils.Direction
+}
+
+// Arrow is a push of a plan shown over the board: the block at From
+// sliding to To
+type Arrow struct {
+	From, To utils.Cell
+	// Flame marks a push that puts out the flame at To
+	Flame bool
+}
+
+// blast is the flash of a bomb going off, growing out of its cell as it
+// fades
+type blast struct {
+	pos   utils.Cell
+	tween *utils.Tween
+	// grown runs from 0 to 1 over the blast
+	grown float64
+}
+
+// animation walks an object along a move's path
+type animation struct {
+	move physics.Move
+	// travelled is how many cells of the path the object has covered
+	travelled float64
+	tween     *utils.Tween
+	// ambient animations, such as enemies roaming, don't keep the player
+	// waiting
+	ambient bool
+}
+
+// NewGameRenderer creates a renderer for the level simulated by engine
+func NewGameRenderer(engine *physics.PhysicsEngine) *GameRenderer {
+	return &GameRenderer{
+		engine:     engine,
+		animations: make(map[sprites.Sprite]*animation),
+		background: DefaultBackground,
+	}
+}
+
+// Animate plays move instead of drawing its object at rest
+func (r *GameRenderer) Animate(move physics.Move) {
+	r.animate(move, false)
+}
+
+// Glide plays move like Animate, without making the renderer busy
+func (r *GameRenderer) Glide(move physics.Move) {
+	r.animate(move, true)
+}
+
+func (r *GameRenderer) animate(move physics.Move, ambient bool) {
+	if !move.Moved() {
+		return
+	}
+	if old, ok := r.animations[move.Object]; ok {
+		old.tween.Cancel()
+	}
+	a := &animation{move: move, ambient: ambient}
+	cells := len(move.Path)
+	a.tween = r.tweens.Add(utils.TweenFloat(&a.travelled, float64(cells), cells*ticksPerCell, utils.Linear)).
+		OnComplete(func() { delete(r.animations, move.Object) })
+	r.animations[move.Object] = a
+}
+
+// Explode plays the blast of a bomb going off at pos. The renderer stays
+// busy until it has faded.
+func (r *GameRenderer) Explode(pos utils.Cell) {
+	b := &blast{pos: pos}
+	b.tween = r.tweens.Add(utils.TweenFloat(&b.grown, 1, blastTicks, utils.EaseOutQuad)).
+		OnComplete(func() { r.blasts = slices.DeleteFunc(r.blasts, func(o *blast) bool { return o == b }) })
+	r.blasts = append(r.blasts, b)
+}
+
+// SetView changes how the board is shown on screen
+func (r *GameRenderer) SetView(v View) {
+	r.view = v
+}
+
+// SetBackground changes the color filling the board under its tiles
+func (r *GameRenderer) SetBackground(c color.Color) {
+	r.background = c
+}
+
+// SetFocus rings obj, such as the character under control when there are
+// several, or clears the ring for nil
+func (r *GameRenderer) SetFocus(obj sprites.Sprite) {
+	r.focus = obj
+}
+
+// Busy reports whether an animation other than an ambient one is still
+// playing
+func (r *GameRenderer) Busy() bool {
+	if len(r.blasts) > 0 {
+		return true
+	}
+	for _, a := range r.animations {
+		if !a.ambient {
+			return true
+		}
+	}
+	return false
+}
+
+// Update advances the animations by one tick
+func (r *GameRenderer) Update() {
+	r.tweens.Update()
+}
+
+// Draw renders the board, the floor tiles on it, every object and then the
+// registered layers
+func (r *GameRenderer) Draw(screen *ebiten.Image) {
+	w, h := r.engine.Size()
+	if r.board == nil {
+		r.board = ebiten.NewImage(w*sprites.SpriteWidth, h*sprites.SpriteHeight)
+		r.scratch = ebiten.NewImage(w*sprites.SpriteWidth, h*sprites.SpriteHeight)
+	}
+	r.board.Fill(r.background)
+	for _, obj := range r.engine.Objects() {
+		if _, ok := obj.(sprites.Tile); ok {
+			obj.Draw(r.board)
+		}
+	}
+	for _, obj := range r.engine.Objects() {
+		if _, ok := obj.(sprites.Tile); ok {
+			continue
+		}
+		a, ok := r.animations[obj]
+		if !ok {
+			obj.Draw(r.board)
+			continue
+		}
+		offset := a.offset()
+		r.scratch.Clear()
+		obj.Draw(r.scratch)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(offset.X, offset.Y)
+		r.board.DrawImage(r.scratch, op)
+	}
+	for _, l := range layers {
+		l.DrawLayer(r.board, r.engine.Objects())
+	}
+	if r.focus != nil {
+		r.drawFocus()
+	}
+	for _, b := range r.blasts {
+		b.draw(r.board)
+	}
+	if r.hint != nil {
+		r.hint.draw(r.board)
+	}
+	for _, a := range r.plan {
+		clr := colornames.Cyan
+		if a.Flame {
+			clr = colornames.Orange
+		}
+		from, to := a.From.Center(), a.To.Center()
+		drawArrow(r.board, float32(from.X), float32(from.Y), float32(to.X), float32(to.Y), clr)
+	}
+	origin := r.Origin(screen.Bounds())
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM = r.geoM()
+	op.GeoM.Translate(origin.X, origin.Y)
+	screen.DrawImage(r.board, op)
+	r.drawPlanLabels(screen)
+}
+
+// drawPlanLabels numbers the arrows of the plan at the blocks they start
+// from. They are drawn on the screen rather than the board, so they read
+// the right way up however the view turns the board.
+func (r *GameRenderer) drawPlanLabels(screen *ebiten.Image) {
+	var cells []utils.Cell
+	labels := make(map[utils.Cell]string)
+	for i, a := range r.plan {
+		if _, ok := labels[a.From]; ok {
+			labels[a.From] += ","
+		} else {
+			cells = append(cells, a.From)
+		}
+		labels[a.From] += strconv.Itoa(i + 1)
+	}
+	for _, c := range cells {
+		p := r.CenterOf(screen.Bounds(), c)
+		DrawText(screen, labels[c], r.planFace, p.X, p.Y-r.planFace.Metrics().HAscent/2, TextStyle{
+			Align:   text.AlignCenter,
+			Outline: colornames.Black,
+		})
+	}
+}
+
+// drawFocus rings the focused object where it is drawn, following it
+// through its animation
+func (r *GameRenderer) drawFocus() {
+	pos := r.focus.Position().Pixel()
+	if a, ok := r.animations[r.focus]; ok {
+		pos = pos.Add(a.offset())
+	}
+	vector.StrokeRect(r.board, float32(pos.X)+1, float32(pos.Y)+1,
+		sprites.SpriteWidth-2, sprites.SpriteHeight-2, 2, colornames.Gold, false)
+}
+
+func (b *blast) draw(board *ebiten.Image) {
+	c := b.pos.Center()
+	fade := float32(1 - b.grown)
+	clr := color.NRGBA{R: 255, G: uint8(200 - 120*b.grown), B: 40, A: uint8(230 * fade)}
+	vector.DrawFilledCircle(board, float32(c.X), float32(c.Y), float32(sprites.SpriteWidth*(0.5+b.grown)), clr, false)
+}
+
+// ShowHint marks the block at pos with an arrow pointing the way to push
+// it, until ClearHint
+func (r *GameRenderer) ShowHint(pos utils.Cell, dir utils.Direction) {
+	r.plan = nil
+	r.hint = &hint{pos: pos, dir: dir}
+}
+
+// ShowPlan draws arrows over the board, numbered in order with labels in
+// face, until ClearHint
+func (r *GameRenderer) ShowPlan(arrows []Arrow, face text.Face) {
+	r.hint = nil
+	r.plan = arrows
+	r.planFace = face
+}
+
+// ClearHint removes the mark left by ShowHint or the plan left by ShowPlan
+func (r *GameRenderer) ClearHint() {
+	r.hint = nil
+	r.plan = nil
+}
+
+func (h *hint) draw(board *ebiten.Image) {
+	p := h.pos.Pixel()
+	vector.StrokeRect(board, float32(p.X)+1, float32(p.Y)+1,
+		sprites.SpriteWidth-2, sprites.SpriteHeight-2, 3, colornames.Cyan, false)
+	c := h.pos.Center()
+	v := h.dir.Vector()
+	dx, dy := float32(v.X), float32(v.Y)
+	// the arrow starts at the block's far edge and points past it
+	x0, y0 := float32(c.X)+dx*sprites.SpriteWidth*0.3, float32(c.Y)+dy*sprites.SpriteHeight*0.3
+	drawArrow(board, x0, y0, x0+dx*sprites.SpriteWidth*0.6, y0+dy*sprites.SpriteHeight*0.6, colornames.Cyan)
+}
+
+// drawArrow draws a straight arrow from x0, y0 pointing at x1, y1, which
+// lie on the same row or column
+func drawArrow(board *ebiten.Image, x0, y0, x1, y1 float32, clr color.Color) {
+	vector.StrokeLine(board, x0, y0, x1, y1, 3, clr, false)
+	dx, dy := sign(x1-x0), sign(y1-y0)
+	head := float32(sprites.SpriteWidth) * 0.2
+	vector.StrokeLine(board, x1, y1, x1-dx*head-dy*head, y1-dy*head+dx*head, 3, clr, false)
+	vector.StrokeLine(board, x1, y1, x1-dx*head+dy*head, y1-dy*head-dx*head, 3, clr, false)
+}
+
+func sign(x float32) float32 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// geoM maps board pixels to the view
+func (r *GameRenderer) geoM() ebiten.GeoM {
+	w, h := r.engine.Size()
+	return r.view.geoM(float64(w*sprites.SpriteWidth), float64(h*sprites.SpriteHeight))
+}
+
+// Origin returns the pixel of the board's top left corner, as viewed, on
+// a screen with the given bounds
+func (r *GameRenderer) Origin(screen image.Rectangle) utils.Pixel {
+	w, h := r.engine.Size()
+	w, h = r.view.size(w*sprites.SpriteWidth, h*sprites.SpriteHeight)
+	return utils.Pixel{
+		X: float64((screen.Dx() - w) / 2),
+		Y: float64((screen.Dy() - h) / 2),
+	}
+}
+
+// CellAt returns the grid cell under a pixel of a screen with the given
+// bounds
+func (r *GameRenderer) CellAt(screen image.Rectangle, p utils.Pixel) utils.Cell {
+	origin := r.Origin(screen)
+	m := r.geoM()
+	m.Invert()
+	x, y := m.Apply(p.X-origin.X, p.Y-origin.Y)
+	return utils.Pixel{X: x, Y: y}.Cell()
+}
+
+// CenterOf returns the pixel at the middle of cell pos, as viewed, on a
+// screen with the given bounds. It may lie off the screen when the board
+// is larger.
+func (r *GameRenderer) CenterOf(screen image.Rectangle, pos utils.Cell) utils.Pixel {
+	origin := r.Origin(screen)
+	c := pos.Center()
+	m := r.geoM()
+	x, y := m.Apply(c.X, c.Y)
+	return utils.Pixel{X: origin.X + x, Y: origin.Y + y}
+}
+
+// offset returns the pixel distance from the object's resting cell to
+// where the animation currently shows it
+func (a *animation) offset() utils.Pixel {
+	path := append([]utils.Cell{a.move.From}, a.move.Path...)
+	step := min(int(a.travelled), len(path)-2)
+	frac := a.travelled - float64(step)
+	from, to := path[step], path[step+1]
+	if abs(to.X-from.X)+abs(to.Y-from.Y) > 1 {
+		// a portal jump: stay on the entry until the step is over
+		frac = 0
+	}
+	end := path[len(path)-1]
+	a0, b0, e0 := from.Pixel(), to.Pixel(), end.Pixel()
+	return utils.Pixel{
+		X: a0.X + (b0.X-a0.X)*frac - e0.X,
+		Y: a0.Y + (b0.Y-a0.Y)*frac - e0.Y,
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package rendering
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// TextStyle says how DrawText lays out and decorates a string
+type TextStyle struct {
+	Color color.Color
+	// Align places the lines to the right of x, centered on it or ending at it
+	Align text.Align
+	// Width wraps lines at word boundaries to fit; 0 leaves them as they are
+	Width float64
+	// LineSpacing is the distance between baselines; 0 uses the face's
+	// line height
+	LineSpacing float64
+	// Shadow, when set, is drawn below and to the right of the text
+	Shadow color.Color
+	// Outline, when set, is drawn around every glyph
+	Outline color.Color
+}
+
+const (
+	shadowOffset = 2
+	outlineWidth = 1.5
+)
+
+var outlineOffsets = [][2]float64{
+	{-1, -1}, {0, -1}, {1, -1},
+	{-1, 0}, {1, 0},
+	{-1, 1}, {0, 1}, {1, 1},
+}
+
+// DrawText draws s with its top edge at y, aligned on x. The shadow and
+// outline keep it readable over any background.
+func DrawText(dst *ebiten.Image, s string, face text.Face, x, y float64, style TextStyle) {
+	if style.Width > 0 {
+		s = Wrap(s, face, style.Width)
+	}
+	spacing := style.LineSpacing
+	if spacing == 0 {
+		m := face.Metrics()
+		spacing = m.HAscent + m.HDescent + m.HLineGap
+	}
+	draw := func(dx, dy float64, c color.Color) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(x+dx, y+dy)
+		op.PrimaryAlign = style.Align
+		op.LineSpacing = spacing
+		op.ColorScale.ScaleWithColor(c)
+		text.Draw(dst, s, face, op)
+	}
+	if style.Shadow != nil {
+		draw(shadowOffset, shadowOffset, style.Shadow)
+	}
+	if style.Outline != nil {
+		for _, o := range outlineOffsets {
+			draw(o[0]*outlineWidth, o[1]*outlineWidth, style.Outline)
+		}
+	}
+	c := style.Color
+	if c == nil {
+		c = color.White
+	}
+	draw(0, 0, c)
+}
+
+// Wrap breaks s into lines no wider than width, at spaces. Existing line
+// breaks are kept, and a word wider than width gets a line of its own.
+func Wrap(s string, face text.Face, width float64) string {
+	var sb strings.Builder
+	for i, para := range strings.Split(s, "\n") {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		line := ""
+		for _, word := range strings.Fields(para) {
+			switch {
+			case line == "":
+				line = word
+			case measure(line+" "+word, face) <= width:
+				line += " " + word
+			default:
+				sb.WriteString(line)
+				sb.WriteByte('\n')
+				line = word
+			}
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+func measure(s string, face text.Face) float64 {
+	w, _ := text.Measure(s, face, 0)
+	return w
+}
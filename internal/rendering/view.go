@@ -0,0 +1,71 @@
+package rendering
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// View is how the board is shown on screen: mirrored left to right, then
+// turned clockwise a quarter at a time. It only changes the picture, so
+// input must be mapped back with Unapply before it reaches the level.
+type View struct {
+	Mirror bool
+	Turns  int
+}
+
+// Apply returns the screen direction that d in the level is shown as
+func (v View) Apply(d utils.Direction) utils.Direction {
+	if v.Mirror {
+		d = d.Mirror()
+	}
+	for range v.Turns % 4 {
+		d = d.Clockwise()
+	}
+	return d
+}
+
+// Unapply returns the direction in the level that d on screen stands for
+func (v View) Unapply(d utils.Direction) utils.Direction {
+	for range v.Turns % 4 {
+		d = d.CounterClockwise()
+	}
+	if v.Mirror {
+		d = d.Mirror()
+	}
+	return d
+}
+
+func (v View) String() string {
+	s := fmt.Sprintf("rotated %d°", v.Turns%4*90)
+	if v.Mirror {
+		s = "mirrored, " + s
+	}
+	return s
+}
+
+// geoM maps pixels of a w x h board to the view, keeping its top left
+// corner at the origin
+func (v View) geoM(w, h float64) ebiten.GeoM {
+	var m ebiten.GeoM
+	if v.Mirror {
+		m.Scale(-1, 1)
+		m.Translate(w, 0)
+	}
+	for range v.Turns % 4 {
+		m.Rotate(math.Pi / 2)
+		m.Translate(h, 0)
+		w, h = h, w
+	}
+	return m
+}
+
+// size returns the size of a w x h board once viewed
+func (v View) size(w, h int) (int, int) {
+	if v.Turns%2 == 1 {
+		return h, w
+	}
+	return w, h
+}
@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// World is what hooks see of the level being played: the objects on its
+// grid, which they may take off or add to
+type World interface {
+	Objects() []sprites.Sprite
+	ObjectsAt(pos utils.Cell) []sprites.Sprite
+	Add(obj sprites.Sprite)
+	Remove(obj sprites.Sprite)
+}
+
+// Hook extends the rules of every level, such as for a mod's sprites
+type Hook interface {
+	// Moved is called for every move that went anywhere, after the
+	// built-in rules have been applied to it
+	Moved(w World, move physics.Move)
+	// Tick is called on every update of the level, after the built-in
+	// rules that run on time
+	Tick(w World)
+}
+
+var hooks []Hook
+
+// RegisterHook adds a hook to the rules of every level. It must run before
+// the game starts, typically from an init function.
+func RegisterHook(h Hook) {
+	hooks = append(hooks, h)
+}
+
+// Hooked reports whether any hook has been registered, changing the rules
+// beyond the built-in ones
+func Hooked() bool {
+	return len(hooks) > 0
+}
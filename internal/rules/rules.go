@@ -0,0 +1,749 @@
+package rules
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// SpreadMoves is how many moves pass between flames spreading, in
+// sections where they do
+const SpreadMoves = 8
+
+// PotHeatTicks is how long a pot must sit next to a flame to become hot;
+// away from flames it cools down at the same rate
+const PotHeatTicks = 90
+
+// Config holds the per-level settings of the rules
+type Config struct {
+	// Refreeze is how many ticks ice melted on a pot takes to freeze again
+	// once the pot has cooled down; 0 means never
+	Refreeze int
+	// Par is the number of moves an expert needs; 0 means unrated
+	Par int
+	// TimeLimit is how many ticks the player has to clear the level; 0
+	// means untimed
+	TimeLimit int
+	// MaxMoves is how many moves the player may take to clear the level;
+	// 0 means unlimited
+	MaxMoves int
+	// Switches lists the toggle walls each pressure plate switches
+	Switches map[*sprites.Plate][]*sprites.ToggleWall
+	// Gates lists the toggle walls each level flag switches, and Flag
+	// reports whether a flag is raised
+	Gates map[string][]*sprites.ToggleWall
+	Flag  func(name string) bool
+	// Merge fuses ice pushed up against another block of ice into a
+	// single 1x2 block
+	Merge bool
+	// Spread lights a new flame beside every burning one each SpreadMoves
+	// moves
+	Spread bool
+}
+
+// GameRulesSystem applies the puzzle rules to the moves resolved by the
+// physics engine and tracks how far the level is from being solved
+type GameRulesSystem struct {
+	engine  *physics.PhysicsEngine
+	config  Config
+	flames  int
+	ices    int
+	moves   int
+	heat    map[*sprites.Pot]int
+	puddles []*puddle
+	dead    physics.Bitset
+	fallen  bool
+	clock   utils.Timer
+	keys    map[sprites.KeyColor]bool
+	doors   []*sprites.Door
+	// coins counts the coins collected out of all placed in the level
+	coins, allCoins int
+	// explosions are where bombs went off, waiting to be shown
+	explosions []utils.Cell
+	// doused are where flames were put out, waiting to be heard
+	doused []utils.Cell
+	// phase is the position every lever in the level shares, which
+	// decides whether phase walls are solid
+	phase  bool
+	levers []*sprites.Lever
+	phases []*sprites.PhaseWall
+}
+
+// puddle is ice melted on a pot, waiting to freeze again
+type puddle struct {
+	pot    *sprites.Pot
+	freeze utils.Timer
+}
+
+// NewGameRulesSystem creates a rules system for the level simulated by engine
+func NewGameRulesSystem(engine *physics.PhysicsEngine, config Config) *GameRulesSystem {
+	r := &GameRulesSystem{
+		engine: engine,
+		config: config,
+		heat:   make(map[*sprites.Pot]int),
+		clock:  utils.NewTimer(config.TimeLimit),
+		keys:   make(map[sprites.KeyColor]bool),
+	}
+	for _, obj := range engine.Objects() {
+		switch obj := obj.(type) {
+		case *sprites.Flame:
+			r.flames++
+		case *sprites.Ice, *sprites.BigIce:
+			r.ices++
+		case *sprites.Pot:
+			r.heat[obj] = 0
+		case *sprites.Door:
+			r.doors = append(r.doors, obj)
+		case *sprites.Coin:
+			r.allCoins++
+		case *sprites.Lever:
+			r.levers = append(r.levers, obj)
+		case *sprites.PhaseWall:
+			r.phases = append(r.phases, obj)
+		}
+	}
+	r.dead = engine.DeadCells()
+	return r
+}
+
+// Flames returns the number of flames still burning
+func (r *GameRulesSystem) Flames() int {
+	return r.flames
+}
+
+// Ices returns the number of ice blocks left
+func (r *GameRulesSystem) Ices() int {
+	return r.ices
+}
+
+// CountMove records a move made by the player, letting the flames spread
+// where they do
+func (r *GameRulesSystem) CountMove() {
+	r.moves++
+	if r.config.Spread && r.moves%SpreadMoves == 0 {
+		r.spread()
+	}
+}
+
+// spread lights a flame in the first empty cell beside each burning one
+func (r *GameRulesSystem) spread() {
+	var flames []*sprites.Flame
+	for _, obj := range r.engine.Objects() {
+		if flame, ok := obj.(*sprites.Flame); ok {
+			flames = append(flames, flame)
+		}
+	}
+	for _, flame := range flames {
+		for _, dir := range utils.Directions {
+			pos := flame.Position().Step(dir)
+			if r.engine.InBounds(pos) && len(r.engine.ObjectsAt(pos)) == 0 {
+				r.engine.Add(sprites.NewFlame(pos.X, pos.Y))
+				r.flames++
+				log.Debug("flame spread", "pos", pos, "flames", r.flames)
+				break
+			}
+		}
+	}
+}
+
+// MovesTaken returns the number of moves the player has made
+func (r *GameRulesSystem) MovesTaken() int {
+	return r.moves
+}
+
+// Par returns the number of moves an expert needs, or 0 if unrated
+func (r *GameRulesSystem) Par() int {
+	return r.config.Par
+}
+
+// TimeLeft returns the ticks left to clear the level, and false if the
+// level is untimed
+func (r *GameRulesSystem) TimeLeft() (int, bool) {
+	return r.clock.Left(), r.config.TimeLimit > 0
+}
+
+// MovesLeft returns how many moves the player may still take, and false
+// if the level has no move budget
+func (r *GameRulesSystem) MovesLeft() (int, bool) {
+	return max(r.config.MaxMoves-r.moves, 0), r.config.MaxMoves > 0
+}
+
+// OutOfMoves reports whether the move budget is spent with flames still
+// burning
+func (r *GameRulesSystem) OutOfMoves() bool {
+	return r.config.MaxMoves > 0 && r.moves >= r.config.MaxMoves && r.flames > 0
+}
+
+// TimedOut reports whether a timed level has run out of time
+func (r *GameRulesSystem) TimedOut() bool {
+	return r.config.TimeLimit > 0 && r.clock.Done()
+}
+
+// Update advances the rules that run on time: the level clock, pressure
+// plates, phase walls waiting to turn solid, pots heating up or cooling
+// down, melted ice freezing again and the registered hooks
+func (r *GameRulesSystem) Update() {
+	if r.clock.Update() {
+		log.Debug("time ran out")
+	}
+	r.updateSwitches()
+	r.updatePhase()
+	for pot, heat := range r.heat {
+		if r.nextToFlame(pot.Position()) {
+			heat = min(heat+1, PotHeatTicks)
+		} else {
+			heat = max(heat-1, 0)
+		}
+		r.heat[pot] = heat
+		switch {
+		case heat == PotHeatTicks && !pot.Hot:
+			pot.Hot = true
+			log.Debug("pot heated", "pos", pot.Position())
+			for _, obj := range r.engine.ObjectsAt(pot.Position()) {
+				if ice, ok := obj.(*sprites.Ice); ok {
+					r.melt(ice, pot)
+				}
+			}
+		case heat == 0 && pot.Hot:
+			pot.Hot = false
+			log.Debug("pot cooled", "pos", pot.Position())
+		}
+	}
+	r.updatePuddles()
+	for _, h := range hooks {
+		h.Tick(r.engine)
+	}
+}
+
+// updateSwitches switches over every toggle wall linked to a pressure plate
+// something rests on or to a raised flag, and switches the others back. A
+// wall never closes on an object standing in it; it waits for the cell to
+// clear.
+func (r *GameRulesSystem) updateSwitches() {
+	var walls []*sprites.ToggleWall
+	pressed := make(map[*sprites.ToggleWall]bool)
+	press := func(linked []*sprites.ToggleWall, on bool) {
+		walls = append(walls, linked...)
+		for _, wall := range linked {
+			pressed[wall] = pressed[wall] || on
+		}
+	}
+	for plate, linked := range r.config.Switches {
+		press(linked, r.pressed(plate))
+	}
+	for flag, linked := range r.config.Gates {
+		press(linked, r.config.Flag != nil && r.config.Flag(flag))
+	}
+	for _, wall := range walls {
+		open := pressed[wall] != wall.Inverted
+		if open == wall.Open || !open && len(r.engine.ObjectsAt(wall.Position())) > 1 {
+			continue
+		}
+		wall.Open = open
+		log.Debug("toggle wall switched", "pos", wall.Position(), "open", open)
+	}
+}
+
+// FlipLevers switches every lever in the level, and with them the phase
+// walls
+func (r *GameRulesSystem) FlipLevers() {
+	r.phase = !r.phase
+	for _, lever := range r.levers {
+		lever.On = r.phase
+	}
+	log.Debug("levers flipped", "on", r.phase)
+	r.updatePhase()
+}
+
+// updatePhase makes the phase walls match the levers. A wall that should
+// turn solid waits until nothing stands in it.
+func (r *GameRulesSystem) updatePhase() {
+	for _, wall := range r.phases {
+		solid := r.phase == wall.Alt
+		if solid == wall.Solid || solid && len(r.engine.ObjectsAt(wall.Position())) > 1 {
+			continue
+		}
+		wall.Solid = solid
+		log.Debug("phase wall switched", "pos", wall.Position(), "solid", solid)
+	}
+}
+
+// pressed reports whether anything rests on plate
+func (r *GameRulesSystem) pressed(plate *sprites.Plate) bool {
+	for _, obj := range r.engine.ObjectsAt(plate.Position()) {
+		switch obj.(type) {
+		case *sprites.Player, *sprites.Ice, *sprites.BigIce, *sprites.Stone, *sprites.Bomb:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *GameRulesSystem) updatePuddles() {
+	remaining := r.puddles[:0]
+	for _, p := range r.puddles {
+		if p.pot.Hot {
+			p.freeze.Reset()
+		} else {
+			p.freeze.Update()
+		}
+		if !p.freeze.Done() {
+			remaining = append(remaining, p)
+			continue
+		}
+		pos := p.pot.Position()
+		r.engine.Add(sprites.NewIce(pos.X, pos.Y))
+		r.ices++
+		log.Debug("ice refrozen", "pos", pos)
+	}
+	r.puddles = remaining
+}
+
+// ProcessMove applies the rules triggered by an object coming to rest,
+// then hands the move to the registered hooks
+func (r *GameRulesSystem) ProcessMove(move physics.Move) {
+	r.processMove(move)
+	if move.Moved() {
+		for _, h := range hooks {
+			h.Moved(r.engine, move)
+		}
+	}
+}
+
+// processMove applies the built-in rules triggered by an object coming to
+// rest
+func (r *GameRulesSystem) processMove(move physics.Move) {
+	if !move.Moved() {
+		return
+	}
+	if _, ok := move.Object.(*sprites.Player); ok {
+		for _, obj := range r.engine.ObjectsAt(move.To()) {
+			switch obj := obj.(type) {
+			case *sprites.Hole:
+				r.fallen = true
+				log.Debug("player fell", "pos", move.To())
+			case *sprites.Water:
+				r.fallen = true
+				log.Debug("player drowned", "pos", move.To())
+			case *sprites.Key:
+				r.engine.Remove(obj)
+				r.keys[obj.Color] = true
+				log.Debug("key collected", "color", obj.Color)
+			case *sprites.Coin:
+				r.engine.Remove(obj)
+				r.coins++
+				log.Debug("coin collected", "coins", r.coins, "of", r.allCoins)
+			}
+		}
+		return
+	}
+	switch move.Object.(type) {
+	case *sprites.Stone, *sprites.Bomb:
+		for _, obj := range r.engine.ObjectsAt(move.To()) {
+			switch obj := obj.(type) {
+			case *sprites.Hole:
+				r.fill(move.Object, obj)
+			case *sprites.Water:
+				r.engine.Remove(move.Object)
+				r.dead = r.engine.DeadCells()
+				log.Debug("block sank", "pos", move.To())
+			case *sprites.Flame:
+				if bomb, ok := move.Object.(*sprites.Bomb); ok {
+					r.detonate(bomb)
+				}
+			}
+		}
+		return
+	}
+	if big, ok := move.Object.(*sprites.BigIce); ok {
+		for _, pos := range move.Path {
+			r.crush(sprites.Cells(big, pos))
+		}
+		r.landBig(big)
+		return
+	}
+	ice, ok := move.Object.(*sprites.Ice)
+	if !ok {
+		return
+	}
+	r.crush(move.Path)
+	for _, obj := range r.engine.ObjectsAt(move.To()) {
+		switch obj := obj.(type) {
+		case *sprites.Flame:
+			r.ProcessIceFlameCollision(ice, obj)
+			return
+		case *sprites.Pot:
+			if obj.Hot {
+				r.melt(ice, obj)
+				return
+			}
+		case *sprites.Hole:
+			r.fill(ice, obj)
+			return
+		case *sprites.Water:
+			r.engine.Remove(ice)
+			r.ices--
+			r.freeze(obj)
+			return
+		}
+	}
+	r.fuse(ice, move)
+}
+
+// landBig puts out every flame under a fused block that came to rest on
+// one, melts it on a hot pot, drops it into a hole or freezes the water
+// under it; in each case the block is used up
+func (r *GameRulesSystem) landBig(big *sprites.BigIce) {
+	used := false
+	for _, cell := range sprites.Cells(big, big.Position()) {
+		for _, obj := range r.engine.ObjectsAt(cell) {
+			switch obj := obj.(type) {
+			case *sprites.Flame:
+				r.engine.Remove(obj)
+				r.flames--
+				r.doused = append(r.doused, obj.Position())
+				used = true
+				log.Debug("flame extinguished", "pos", cell, "flames", r.flames)
+			case *sprites.Pot:
+				used = used || obj.Hot
+			case *sprites.Hole:
+				r.engine.Remove(obj)
+				used = true
+			case *sprites.Water:
+				r.freeze(obj)
+				used = true
+			}
+		}
+	}
+	if !used {
+		return
+	}
+	r.engine.Remove(big)
+	r.ices--
+	r.dead = r.engine.DeadCells()
+}
+
+// freeze turns water into frozen ground that can be walked on
+func (r *GameRulesSystem) freeze(water *sprites.Water) {
+	pos := water.Position()
+	r.engine.Remove(water)
+	r.engine.Add(sprites.NewFrozenGround(pos.X, pos.Y))
+	r.dead = r.engine.DeadCells()
+	log.Debug("water frozen", "pos", pos, "ices", r.ices)
+}
+
+// fuse joins ice that slid up against another block of ice with it into
+// one 1x2 block lying along the push, if the level merges ice
+func (r *GameRulesSystem) fuse(ice *sprites.Ice, move physics.Move) {
+	if !r.config.Merge {
+		return
+	}
+	last := move.From
+	if len(move.Path) > 1 {
+		last = move.Path[len(move.Path)-2]
+	}
+	to := move.To()
+	dir, ok := utils.DirectionOf(utils.Vector{X: to.X - last.X, Y: to.Y - last.Y})
+	if !ok {
+		// came out of a portal
+		return
+	}
+	var other *sprites.Ice
+	for _, obj := range r.engine.ObjectsAt(to.Step(dir)) {
+		if block, ok := obj.(*sprites.Ice); ok {
+			other = block
+		}
+	}
+	if other == nil {
+		return
+	}
+	pos := to
+	if p := other.Position(); p.X < pos.X || p.Y < pos.Y {
+		pos = p
+	}
+	r.engine.Remove(ice)
+	r.engine.Remove(other)
+	r.engine.Add(sprites.NewBigIce(pos.X, pos.Y, !dir.Horizontal()))
+	r.ices--
+	r.dead = r.engine.DeadCells()
+	log.Debug("ice fused", "pos", pos, "vertical", !dir.Horizontal())
+}
+
+// ProcessIceFlameCollision puts out flame with ice, taking both off the grid
+func (r *GameRulesSystem) ProcessIceFlameCollision(ice *sprites.Ice, flame *sprites.Flame) {
+	r.engine.Remove(ice)
+	r.engine.Remove(flame)
+	r.ices--
+	r.flames--
+	r.doused = append(r.doused, flame.Position())
+	r.dead = r.engine.DeadCells()
+	log.Debug("flame extinguished", "pos", flame.Position(), "flames", r.flames)
+}
+
+// fill drops a block of ice or stone into a hole, taking both off the grid
+// so the cell can be crossed again
+func (r *GameRulesSystem) fill(block sprites.Sprite, hole *sprites.Hole) {
+	r.engine.Remove(block)
+	r.engine.Remove(hole)
+	if _, ok := block.(*sprites.Ice); ok {
+		r.ices--
+	}
+	r.dead = r.engine.DeadCells()
+	log.Debug("hole filled", "pos", hole.Position(), "ices", r.ices)
+}
+
+// detonate sets off bomb, destroying the cracked walls in the eight cells
+// around it. The flame that lit it keeps burning.
+func (r *GameRulesSystem) detonate(bomb *sprites.Bomb) {
+	pos := bomb.Position()
+	r.engine.Remove(bomb)
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			for _, obj := range r.engine.ObjectsAt(pos.Add(utils.Vector{X: dx, Y: dy})) {
+				if wall, ok := obj.(*sprites.CrackedWall); ok {
+					r.engine.Remove(wall)
+					log.Debug("wall blown up", "pos", wall.Position())
+				}
+			}
+		}
+	}
+	r.explosions = append(r.explosions, pos)
+	r.dead = r.engine.DeadCells()
+	log.Debug("bomb went off", "pos", pos)
+}
+
+// Explosions returns where bombs have gone off since the last call, for
+// the renderer to show the blasts
+func (r *GameRulesSystem) Explosions() []utils.Cell {
+	res := r.explosions
+	r.explosions = nil
+	return res
+}
+
+// Doused returns where flames have been put out since the last call
+func (r *GameRulesSystem) Doused() []utils.Cell {
+	res := r.doused
+	r.doused = nil
+	return res
+}
+
+// crush takes the enemies in the cells sliding ice ran over off the grid
+func (r *GameRulesSystem) crush(cells []utils.Cell) {
+	for _, pos := range cells {
+		for _, obj := range r.engine.ObjectsAt(pos) {
+			if enemy, ok := obj.(*sprites.Enemy); ok {
+				r.engine.Remove(enemy)
+				log.Debug("enemy crushed", "pos", pos)
+			}
+		}
+	}
+}
+
+// Caught reports whether an enemy shares a cell with the player
+func (r *GameRulesSystem) Caught() bool {
+	for _, obj := range r.engine.Objects() {
+		if _, ok := obj.(*sprites.Player); !ok {
+			continue
+		}
+		for _, other := range r.engine.ObjectsAt(obj.Position()) {
+			if _, ok := other.(*sprites.Enemy); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Keys returns the colors of the keys collected so far
+func (r *GameRulesSystem) Keys() []sprites.KeyColor {
+	var res []sprites.KeyColor
+	for c := sprites.KeyRed; c <= sprites.KeyYellow; c++ {
+		if r.keys[c] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Coins returns the number of coins collected and placed in the level
+func (r *GameRulesSystem) Coins() (collected, total int) {
+	return r.coins, r.allCoins
+}
+
+// AllCoins reports whether the level has coins and every one of them has
+// been collected
+func (r *GameRulesSystem) AllCoins() bool {
+	return r.allCoins > 0 && r.coins == r.allCoins
+}
+
+// Unlock opens door if a key of its color has been collected, and reports
+// whether the door is open
+func (r *GameRulesSystem) Unlock(door *sprites.Door) bool {
+	if !door.Open && r.keys[door.Color] {
+		door.Open = true
+		log.Debug("door opened", "pos", door.Position(), "color", door.Color)
+	}
+	return door.Open
+}
+
+// Fallen reports whether the player has fallen into a hole or water
+func (r *GameRulesSystem) Fallen() bool {
+	return r.fallen
+}
+
+// IsDead reports whether ice resting at pos can never put out a flame
+func (r *GameRulesSystem) IsDead(pos utils.Cell) bool {
+	// spreading flames may yet reach any cell
+	return r.flames > 0 && !r.config.Spread && r.engine.InBounds(pos) && r.dead.Has(r.engine.Index(pos))
+}
+
+// Stranded reports whether obj is an ice block still on the grid, resting
+// where it can never put out a flame
+func (r *GameRulesSystem) Stranded(obj sprites.Sprite) bool {
+	if _, ok := obj.(*sprites.Ice); !ok {
+		return false
+	}
+	for _, other := range r.engine.ObjectsAt(obj.Position()) {
+		if other == obj {
+			return r.IsDead(obj.Position())
+		}
+	}
+	return false
+}
+
+// melt takes ice off the grid on a hot pot, leaving a puddle to refreeze
+func (r *GameRulesSystem) melt(ice *sprites.Ice, pot *sprites.Pot) {
+	r.engine.Remove(ice)
+	r.ices--
+	if r.config.Refreeze > 0 {
+		r.puddles = append(r.puddles, &puddle{pot: pot, freeze: utils.NewTimer(r.config.Refreeze)})
+	}
+	log.Debug("ice melted", "pos", pot.Position(), "ices", r.ices)
+}
+
+// Snapshot records the counters and pot states of the rules system. The
+// level clock is left out, so undoing a move does not give time back.
+type Snapshot struct {
+	flames  int
+	ices    int
+	moves   int
+	heat    map[*sprites.Pot]int
+	hot     map[*sprites.Pot]bool
+	puddles []puddle
+	dead    physics.Bitset
+	fallen  bool
+	keys    map[sprites.KeyColor]bool
+	coins   int
+	open    map[*sprites.Door]bool
+	phase   bool
+	solid   map[*sprites.PhaseWall]bool
+}
+
+// Snapshot captures the current state so it can be restored later
+func (r *GameRulesSystem) Snapshot() Snapshot {
+	s := Snapshot{
+		flames: r.flames,
+		ices:   r.ices,
+		moves:  r.moves,
+		dead:   r.dead,
+		fallen: r.fallen,
+		coins:  r.coins,
+		heat:   make(map[*sprites.Pot]int, len(r.heat)),
+		hot:    make(map[*sprites.Pot]bool, len(r.heat)),
+		keys:   make(map[sprites.KeyColor]bool, len(r.keys)),
+		open:   make(map[*sprites.Door]bool, len(r.doors)),
+		phase:  r.phase,
+		solid:  make(map[*sprites.PhaseWall]bool, len(r.phases)),
+	}
+	for _, wall := range r.phases {
+		s.solid[wall] = wall.Solid
+	}
+	for c, held := range r.keys {
+		s.keys[c] = held
+	}
+	for _, door := range r.doors {
+		s.open[door] = door.Open
+	}
+	for pot, heat := range r.heat {
+		s.heat[pot] = heat
+		s.hot[pot] = pot.Hot
+	}
+	for _, p := range r.puddles {
+		s.puddles = append(s.puddles, *p)
+	}
+	return s
+}
+
+// Restore rolls the rules system back to s
+func (r *GameRulesSystem) Restore(s Snapshot) {
+	r.flames, r.ices, r.moves = s.flames, s.ices, s.moves
+	r.dead = s.dead
+	r.fallen = s.fallen
+	r.coins = s.coins
+	clear(r.keys)
+	for c, held := range s.keys {
+		r.keys[c] = held
+	}
+	for _, door := range r.doors {
+		door.Open = s.open[door]
+	}
+	r.phase = s.phase
+	for _, lever := range r.levers {
+		lever.On = r.phase
+	}
+	for _, wall := range r.phases {
+		wall.Solid = s.solid[wall]
+	}
+	for pot, heat := range s.heat {
+		r.heat[pot] = heat
+		pot.Hot = s.hot[pot]
+	}
+	r.puddles = r.puddles[:0]
+	for _, p := range s.puddles {
+		r.puddles = append(r.puddles, &p)
+	}
+}
+
+// CheckWin reports whether every flame has been put out with every player
+// still standing
+func (r *GameRulesSystem) CheckWin() bool {
+	return r.flames == 0 && !r.fallen && !r.TimedOut() && !r.Caught()
+}
+
+// CheckLose reports whether the level can no longer be won: a player has
+// fallen into a hole or water, time or moves have run out, an enemy
+// caught a player, or fewer ice blocks can still reach a flame than there
+// are flames left and no melted ice is waiting to freeze again
+func (r *GameRulesSystem) CheckLose() bool {
+	if r.fallen || r.TimedOut() || r.OutOfMoves() || r.Caught() {
+		return true
+	}
+	if r.flames == 0 || len(r.puddles) > 0 {
+		return false
+	}
+	usable := 0
+	for _, obj := range r.engine.Objects() {
+		switch obj.(type) {
+		case *sprites.Ice:
+			if !r.IsDead(obj.Position()) {
+				usable++
+			}
+		case *sprites.BigIce:
+			// may land on two flames at once
+			usable += 2
+		}
+	}
+	return usable < r.flames
+}
+
+func (r *GameRulesSystem) nextToFlame(pos utils.Cell) bool {
+	for _, dir := range utils.Directions {
+		for _, obj := range r.engine.ObjectsAt(pos.Step(dir)) {
+			if _, ok := obj.(*sprites.Flame); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
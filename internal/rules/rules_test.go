@@ -0,0 +1,205 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rules"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// row lays out a single row of walls (#), fake walls (H), ice (I), flames
+// (F), levers (L), phase walls (W), alternate phase walls (A) and the
+// player (P), blank cells being dots
+func row(t *testing.T, cells string) (*physics.PhysicsEngine, *sprites.Player) {
+	t.Helper()
+	var objects []sprites.Sprite
+	var player *sprites.Player
+	for x, ch := range cells {
+		switch ch {
+		case '#':
+			objects = append(objects, sprites.NewWall(x, 0))
+		case 'H':
+			objects = append(objects, sprites.NewFakeWall(x, 0))
+		case 'I':
+			objects = append(objects, sprites.NewIce(x, 0))
+		case 'F':
+			objects = append(objects, sprites.NewFlame(x, 0))
+		case 'P':
+			player = sprites.NewPlayer(x, 0)
+			objects = append(objects, player)
+		case 'L':
+			objects = append(objects, sprites.NewLever(x, 0))
+		case 'W', 'A':
+			objects = append(objects, sprites.NewPhaseWall(x, 0, ch == 'A'))
+		case '.':
+		default:
+			t.Fatalf("unexpected %q in row", ch)
+		}
+	}
+	return physics.NewPhysicsEngine(len(cells), 1, objects), player
+}
+
+func TestStranded(t *testing.T) {
+	tests := []struct {
+		name     string
+		cells    string
+		stranded bool
+	}{
+		{"ice puts out the flame", "PI..F", false},
+		{"ice stops short of a wall", "PI.#F", true},
+		{"ice stops where it can be pushed on", "PI.I.F", false},
+		{"ice against the edge", "F.PI..", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, player := row(t, tt.cells)
+			r := rules.NewGameRulesSystem(engine, rules.Config{})
+			moves := engine.MovePlayer(player, utils.Right)
+			if len(moves) == 0 {
+				t.Fatal("push went nowhere")
+			}
+			for _, move := range moves {
+				r.ProcessMove(move)
+			}
+			if got := r.Stranded(moves[0].Object); got != tt.stranded {
+				t.Errorf("stranded %v, want %v", got, tt.stranded)
+			}
+		})
+	}
+}
+
+func TestPushFromFakeWall(t *testing.T) {
+	// the ice can only be pushed by a player standing in the fake wall
+	engine, player := row(t, "P.HI..F")
+	r := rules.NewGameRulesSystem(engine, rules.Config{})
+	for step := range 3 {
+		if r.CheckLose() {
+			t.Fatalf("level lost after %d moves", step)
+		}
+		for _, move := range engine.MovePlayer(player, utils.Right) {
+			r.ProcessMove(move)
+		}
+	}
+	if !r.CheckWin() {
+		t.Error("level not won by pushing the ice from the fake wall")
+	}
+}
+
+// phases returns the levers and phase walls of engine, left to right
+func phases(engine *physics.PhysicsEngine) (levers []*sprites.Lever, walls []*sprites.PhaseWall) {
+	for _, obj := range engine.Objects() {
+		switch obj := obj.(type) {
+		case *sprites.Lever:
+			levers = append(levers, obj)
+		case *sprites.PhaseWall:
+			walls = append(walls, obj)
+		}
+	}
+	return levers, walls
+}
+
+func TestFlipLevers(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells string
+		flips int
+		// occupied puts ice in the alternate walls before the flips
+		occupied bool
+		// solid is whether each phase wall ends up solid, left to right
+		solid []bool
+	}{
+		{"walls start solid and alternates open", "LWA", 0, false, []bool{true, false}},
+		{"a flip swaps them", "LWA", 1, false, []bool{false, true}},
+		{"a second flip swaps them back", "LWA", 2, false, []bool{true, false}},
+		{"levers flip together", "LWL.A", 1, false, []bool{false, true}},
+		{"a wall stays open while ice stands in it", "LWA", 1, true, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _ := row(t, tt.cells)
+			levers, walls := phases(engine)
+			if tt.occupied {
+				for _, wall := range walls {
+					if wall.Alt {
+						engine.Add(sprites.NewIce(wall.Position().X, wall.Position().Y))
+					}
+				}
+			}
+			r := rules.NewGameRulesSystem(engine, rules.Config{})
+			for range tt.flips {
+				r.FlipLevers()
+			}
+			r.Update()
+			for _, lever := range levers {
+				if lever.On != (tt.flips%2 == 1) {
+					t.Errorf("lever at %v on %v after %d flips", lever.Position(), lever.On, tt.flips)
+				}
+			}
+			for i, wall := range walls {
+				if wall.Solid != tt.solid[i] {
+					t.Errorf("wall at %v solid %v, want %v", wall.Position(), wall.Solid, tt.solid[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLeversRestore(t *testing.T) {
+	engine, _ := row(t, "LWA")
+	levers, walls := phases(engine)
+	r := rules.NewGameRulesSystem(engine, rules.Config{})
+	s := r.Snapshot()
+	r.FlipLevers()
+	r.Restore(s)
+	if levers[0].On || !walls[0].Solid || walls[1].Solid {
+		t.Error("restoring a snapshot left the levers flipped")
+	}
+}
+
+func TestFlagOpensGate(t *testing.T) {
+	wall := sprites.NewToggleWall(2, 0, false)
+	engine := physics.NewPhysicsEngine(3, 1, []sprites.Sprite{sprites.NewPlayer(0, 0), wall})
+	flags := make(map[string]bool)
+	r := rules.NewGameRulesSystem(engine, rules.Config{
+		Gates: map[string][]*sprites.ToggleWall{"gate": {wall}},
+		Flag:  func(name string) bool { return flags[name] },
+	})
+	r.Update()
+	if wall.Open {
+		t.Fatal("gate open before its flag is raised")
+	}
+	flags["gate"] = true
+	r.Update()
+	if !wall.Open {
+		t.Fatal("gate still closed after its flag is raised")
+	}
+}
+
+// recorder is a hook counting what it is handed
+type recorder struct {
+	moves, ticks int
+}
+
+func (r *recorder) Moved(w rules.World, move physics.Move) { r.moves++ }
+func (r *recorder) Tick(w rules.World)                     { r.ticks++ }
+
+func TestHooks(t *testing.T) {
+	player := sprites.NewPlayer(0, 0)
+	engine := physics.NewPhysicsEngine(3, 1, []sprites.Sprite{player})
+	hook := &recorder{}
+	rules.RegisterHook(hook)
+	r := rules.NewGameRulesSystem(engine, rules.Config{})
+	r.Update()
+	for _, move := range engine.MovePlayer(player, utils.Right) {
+		r.ProcessMove(move)
+	}
+	r.ProcessMove(physics.Move{Object: player, From: player.Position()})
+	if hook.moves != 1 || hook.ticks != 1 {
+		t.Errorf("hook saw %d moves and %d ticks, want 1 of each", hook.moves, hook.ticks)
+	}
+	if !rules.Hooked() {
+		t.Error("rules not hooked after registering a hook")
+	}
+}
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"slices"
+
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Push is one push of a solution, with the reasoning behind it: where the
+// block goes, and which earlier pushes it can't do without
+type Push struct {
+	// Move is the index of the move making the push
+	Move int
+	// From is where the block stood, and To where it stopped: the flame it
+	// put out, when Flame is set
+	From, To utils.Cell
+	Dir      utils.Direction
+	Flame    bool
+	// After lists the earlier pushes, by index, that this one relies on:
+	// ones that brought the block to From, cleared the cells it slides
+	// over, or left the block it stops against
+	After []int
+}
+
+// Explain walks moves from b, annotating every push they make. The pushes
+// are in the order they are made; the steps walking the player between
+// them are left out.
+func Explain(b *physics.Board, moves []utils.Direction) []Push {
+	b = b.Clone()
+	var res []Push
+	// placed and cleared hold, by cell, the last push that left a block
+	// there and the last that emptied it
+	placed := make(map[int]int)
+	cleared := make(map[int]int)
+	for m, dir := range moves {
+		from := b.Position(b.Player).Step(dir)
+		pushing := inside(b, from) && b.Blocks.Has(b.Index(from))
+		prev := b.Clone()
+		if !b.MovePlayer(dir) {
+			break
+		}
+		if !pushing {
+			continue
+		}
+		n := len(res)
+		p := Push{Move: m, From: from, To: from, Dir: dir}
+		if i, ok := placed[b.Index(from)]; ok {
+			p.After = append(p.After, i)
+		}
+		// the block slid over empty cells, so the first block or put out
+		// flame past From is where it stopped
+		for !p.Flame && !b.Blocks.Has(b.Index(p.To)) {
+			p.To = p.To.Step(dir)
+			i := b.Index(p.To)
+			if j, ok := cleared[i]; ok {
+				p.After = append(p.After, j)
+			}
+			p.Flame = prev.Flames.Has(i) && !b.Flames.Has(i)
+		}
+		if stop := p.To.Step(dir); !p.Flame && inside(b, stop) {
+			if i, ok := placed[b.Index(stop)]; ok && b.Blocks.Has(b.Index(stop)) {
+				p.After = append(p.After, i)
+			}
+		}
+		slices.Sort(p.After)
+		p.After = slices.Compact(p.After)
+		delete(placed, b.Index(from))
+		cleared[b.Index(from)] = n
+		if p.Flame {
+			cleared[b.Index(p.To)] = n
+		} else {
+			placed[b.Index(p.To)] = n
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// inside reports whether c lies on b
+func inside(b *physics.Board, c utils.Cell) bool {
+	return c.X >= 0 && c.X < b.Width && c.Y >= 0 && c.Y < b.Height
+}
@@ -0,0 +1,49 @@
+package solver
+
+import (
+	"context"
+	"math"
+
+	"github.com/zrcoder/icer/internal/levels"
+)
+
+// effortSteps are the least effort each difficulty score above 1 takes,
+// effort being the solution length times the branching factor, raised by
+// how many moves lead into dead ends
+var effortSteps = [levels.MaxDifficulty - 1]float64{12, 30, 70, 150}
+
+// Rate rates how hard level is from its shortest solution: how long it
+// is, how many moves are open along the way and how many of those lead
+// nowhere. It gives up with ctx's error once ctx is done.
+func Rate(ctx context.Context, section *levels.Section, level *levels.Level, limit int) (levels.Difficulty, error) {
+	b, err := start(section, level)
+	if err != nil {
+		return levels.Difficulty{}, err
+	}
+	moves, stats, err := search(ctx, b, limit)
+	if err != nil {
+		return levels.Difficulty{}, err
+	}
+	d := levels.Difficulty{Moves: len(moves)}
+	if stats.Positions > 0 {
+		d.Branching = float64(stats.Moves) / float64(stats.Positions)
+	}
+	if stats.Moves > 0 {
+		d.DeadEnds = float64(stats.DeadEnds) / float64(stats.Moves)
+	}
+	effort := float64(d.Moves) * max(d.Branching, 1) * (1 + 2*d.DeadEnds)
+	d.Score = 1
+	for _, step := range effortSteps {
+		if effort >= step {
+			d.Score++
+		}
+	}
+	d.Branching = round(d.Branching)
+	d.DeadEnds = round(d.DeadEnds)
+	return d, nil
+}
+
+// round rounds x to two decimals, which is all a rating needs to keep
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,176 @@
+// Package solver finds the shortest solutions to levels, for hints and
+// for checking that levels can be won
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"slices"
+
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rules"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// MaxStates bounds how many positions a search looks at before giving up,
+// which keeps a hint under a frame or two on the levels boards can express
+const MaxStates = 200_000
+
+// cancelCheck is how many positions a search expands between looks at
+// whether it has been cancelled
+const cancelCheck = 1024
+
+var (
+	// ErrUnsolvable is returned when no moves put out every flame
+	ErrUnsolvable = errors.New("no solution")
+	// ErrGaveUp is returned when the search ran out of states to look at
+	ErrGaveUp = errors.New("gave up looking for a solution")
+	// ErrUnsupported is returned for levels using mechanics the solver
+	// does not model
+	ErrUnsupported = errors.New("level uses mechanics the solver does not model")
+)
+
+// node is a position reached in the search, and how
+type node struct {
+	board  *physics.Board
+	parent int
+	dir    utils.Direction
+}
+
+// Stats counts what a search came across on its way to a solution
+type Stats struct {
+	// Positions is the number of positions expanded
+	Positions int
+	// Moves is the number of moves made from them that went anywhere
+	Moves int
+	// DeadEnds is the number of those moves that left too few blocks able
+	// to reach a flame to put out every flame
+	DeadEnds int
+}
+
+// Solve returns the fewest moves that put out every flame on b, looking
+// at no more than limit positions. It searches breadth first, so the
+// first solution found is a shortest one. It gives up with ctx's error
+// once ctx is done.
+func Solve(ctx context.Context, b *physics.Board, limit int) ([]utils.Direction, error) {
+	moves, _, err := search(ctx, b, limit)
+	return moves, err
+}
+
+// search is Solve, also counting what it came across
+func search(ctx context.Context, b *physics.Board, limit int) ([]utils.Direction, Stats, error) {
+	var stats Stats
+	nodes := []node{{board: b, parent: -1}}
+	// positions are told apart by their Zobrist hashes; two positions
+	// sharing a 64-bit hash are too unlikely to be worth a full compare
+	seen := map[uint64]bool{b.Hash(): true}
+	for i := 0; i < len(nodes); i++ {
+		if i%cancelCheck == 0 && ctx.Err() != nil {
+			return nil, stats, ctx.Err()
+		}
+		if nodes[i].board.Solved() {
+			return path(nodes, i), stats, nil
+		}
+		stats.Positions++
+		for _, dir := range utils.Directions {
+			next := nodes[i].board.Clone()
+			if !next.MovePlayer(dir) {
+				continue
+			}
+			stats.Moves++
+			if !winnable(next) {
+				stats.DeadEnds++
+				continue
+			}
+			k := next.Hash()
+			if seen[k] {
+				continue
+			}
+			if len(seen) >= limit {
+				return nil, stats, ErrGaveUp
+			}
+			seen[k] = true
+			nodes = append(nodes, node{board: next, parent: i, dir: dir})
+		}
+		// positions already expanded are only needed to walk the path back
+		nodes[i].board = nil
+	}
+	return nil, stats, ErrUnsolvable
+}
+
+// NextPush returns the block the first push of moves pushes, and which
+// way, starting from b
+func NextPush(b *physics.Board, moves []utils.Direction) (block utils.Cell, dir utils.Direction, ok bool) {
+	b = b.Clone()
+	for _, dir := range moves {
+		target := b.Position(b.Player).Step(dir)
+		pushing := inside(b, target) && b.Blocks.Has(b.Index(target))
+		if !b.MovePlayer(dir) {
+			return utils.Cell{}, 0, false
+		}
+		if pushing {
+			return target, dir, true
+		}
+	}
+	return utils.Cell{}, 0, false
+}
+
+// Level solves level from its start as its section's rules play it
+func Level(ctx context.Context, section *levels.Section, level *levels.Level, limit int) ([]utils.Direction, error) {
+	b, err := start(section, level)
+	if err != nil {
+		return nil, err
+	}
+	return Solve(ctx, b, limit)
+}
+
+// start builds the board level starts on under its section's rules
+func start(section *levels.Section, level *levels.Level) (*physics.Board, error) {
+	objects, width, height, err := level.Build()
+	if err != nil {
+		return nil, err
+	}
+	if !Supports(section) {
+		return nil, ErrUnsupported
+	}
+	engine := physics.NewPhysicsEngine(width, height, objects)
+	engine.SetGravity(level.Gravity)
+	engine.SetChains(section.Variant(levels.VariantChainPush))
+	engine.SetSlippery(section.Variant(levels.VariantPlayerSlides))
+	engine.Settle()
+	b, ok := engine.Board()
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return b, nil
+}
+
+// Supports reports whether the solver models the rules of section. Ice
+// merging, spreading flames and the hooks of mods change the rules beyond
+// what boards hold.
+func Supports(section *levels.Section) bool {
+	return !section.Variant(levels.VariantMerge) && !section.Variant(levels.VariantFlamesSpread) && !rules.Hooked()
+}
+
+// winnable reports whether b still has as many blocks off dead cells as
+// flames, each block putting out one flame at most and none on a dead
+// cell ever reaching one
+func winnable(b *physics.Board) bool {
+	live := 0
+	for i, w := range b.Blocks {
+		live += bits.OnesCount64(w &^ b.Dead[i])
+	}
+	return live >= b.Flames.Count()
+}
+
+// path walks back from node i to the start, returning the moves made
+func path(nodes []node, i int) []utils.Direction {
+	var res []utils.Direction
+	for ; nodes[i].parent >= 0; i = nodes[i].parent {
+		res = append(res, nodes[i].dir)
+	}
+	slices.Reverse(res)
+	return res
+}
@@ -0,0 +1,188 @@
+package solver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// builtinBoards returns the starting boards of the built-in levels the
+// solver supports, by level code
+func builtinBoards(tb testing.TB) map[string]*physics.Board {
+	tb.Helper()
+	res := make(map[string]*physics.Board)
+	m := levels.NewManager()
+	for _, s := range m.Sections {
+		if s.Pack != "" {
+			continue
+		}
+		for i := range s.LevelCount + s.BonusCount {
+			b, err := start(s, s.Level(i))
+			if errors.Is(err, ErrUnsupported) {
+				continue
+			}
+			if err != nil {
+				tb.Fatal(err)
+			}
+			res[fmt.Sprintf("%d-%d", s.ID+1, i+1)] = b
+		}
+	}
+	if len(res) == 0 {
+		tb.Fatal("no built-in level the solver supports")
+	}
+	return res
+}
+
+func TestBuiltinLevelsMeetPar(t *testing.T) {
+	m := levels.NewManager()
+	for _, s := range m.Sections {
+		for i := range s.LevelCount + s.BonusCount {
+			level := s.Level(i)
+			moves, err := Level(context.Background(), s, level, MaxStates)
+			if errors.Is(err, ErrUnsupported) {
+				continue
+			}
+			if err != nil {
+				t.Errorf("level %d-%d: %v", s.ID+1, i+1, err)
+				continue
+			}
+			if level.Par > 0 && len(moves) != level.Par {
+				t.Errorf("level %d-%d solved in %d moves, par is %d", s.ID+1, i+1, len(moves), level.Par)
+			}
+		}
+	}
+}
+
+// BenchmarkSolve solves each built-in level the solver supports from its
+// start
+func BenchmarkSolve(b *testing.B) {
+	for code, board := range builtinBoards(b) {
+		b.Run(code, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := Solve(context.Background(), board, MaxStates); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSeen records positions reached by random walks on the built-in
+// levels in a seen-set, keyed by the Zobrist hash boards keep and by the
+// string of their bitsets the solver used to build for every position
+func BenchmarkSeen(b *testing.B) {
+	var positions []*physics.Board
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, board := range builtinBoards(b) {
+		for range 100 {
+			walk := board.Clone()
+			for range 20 {
+				walk.MovePlayer(utils.Directions[r.IntN(len(utils.Directions))])
+				positions = append(positions, walk.Clone())
+			}
+		}
+	}
+	b.Run("zobrist", func(b *testing.B) {
+		for b.Loop() {
+			seen := make(map[uint64]bool)
+			for _, p := range positions {
+				seen[p.Hash()] = true
+			}
+		}
+	})
+	b.Run("string", func(b *testing.B) {
+		for b.Loop() {
+			seen := make(map[string]bool)
+			for _, p := range positions {
+				seen[stringKey(p)] = true
+			}
+		}
+	})
+}
+
+// stringKey is the seen-set key the solver used before boards were hashed
+func stringKey(b *physics.Board) string {
+	buf := binary.AppendUvarint(nil, uint64(b.Player))
+	for _, set := range []physics.Bitset{b.Blocks, b.Flames, b.Pickups} {
+		for _, w := range set {
+			buf = binary.LittleEndian.AppendUint64(buf, w)
+		}
+	}
+	return string(buf)
+}
+
+func TestWinnableSkipsDeadBlocks(t *testing.T) {
+	// the ice is cornered: no push ever moves it toward the flame
+	objects := []sprites.Sprite{sprites.NewIce(1, 1), sprites.NewFlame(3, 1), sprites.NewPlayer(1, 2)}
+	for x := range 5 {
+		objects = append(objects, sprites.NewWall(x, 0), sprites.NewWall(x, 3))
+	}
+	for y := 1; y < 3; y++ {
+		objects = append(objects, sprites.NewWall(0, y), sprites.NewWall(4, y))
+	}
+	b, ok := physics.NewPhysicsEngine(5, 4, objects).Board()
+	if !ok {
+		t.Fatal("level not expressible as a board")
+	}
+	if winnable(b) {
+		t.Error("level with its only block on a dead cell counted as winnable")
+	}
+	if _, err := Solve(context.Background(), b, MaxStates); !errors.Is(err, ErrUnsolvable) {
+		t.Errorf("Solve error = %v, want %v", err, ErrUnsolvable)
+	}
+}
+
+func TestSolvePushingFromFakeWall(t *testing.T) {
+	// the ice can only be pushed by a player standing in the fake wall
+	objects := []sprites.Sprite{sprites.NewPlayer(0, 0), sprites.NewFakeWall(2, 0), sprites.NewIce(3, 0), sprites.NewFlame(6, 0)}
+	b, ok := physics.NewPhysicsEngine(7, 1, objects).Board()
+	if !ok {
+		t.Fatal("level not expressible as a board")
+	}
+	moves, err := Solve(context.Background(), b, MaxStates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(moves) != 3 {
+		t.Errorf("solved in %d moves, want 3", len(moves))
+	}
+}
+
+func TestExplain(t *testing.T) {
+	// the ice is pushed against the wall, then down into the flame
+	objects := []sprites.Sprite{sprites.NewPlayer(0, 0), sprites.NewIce(1, 1), sprites.NewWall(3, 1), sprites.NewFlame(2, 3)}
+	b, ok := physics.NewPhysicsEngine(4, 4, objects).Board()
+	if !ok {
+		t.Fatal("level not expressible as a board")
+	}
+	moves := []utils.Direction{utils.Down, utils.Right, utils.Up, utils.Right, utils.Right, utils.Down}
+	want := []Push{
+		{Move: 1, From: utils.Cell{X: 1, Y: 1}, To: utils.Cell{X: 2, Y: 1}, Dir: utils.Right},
+		{Move: 5, From: utils.Cell{X: 2, Y: 1}, To: utils.Cell{X: 2, Y: 3}, Dir: utils.Down, Flame: true, After: []int{0}},
+	}
+	got := Explain(b, moves)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Explain = %+v, want %+v", got, want)
+	}
+}
+
+func TestSolveCancelled(t *testing.T) {
+	objects := []sprites.Sprite{sprites.NewPlayer(0, 0), sprites.NewIce(1, 1), sprites.NewFlame(3, 1)}
+	b, ok := physics.NewPhysicsEngine(5, 3, objects).Board()
+	if !ok {
+		t.Fatal("level not expressible as a board")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Solve(ctx, b, MaxStates); !errors.Is(err, context.Canceled) {
+		t.Errorf("Solve error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -5,25 +5,76 @@ import (
 	"github.com/zrcoder/icer/internal/utils"
 )
 
-// Sprite interface defines the basic contract for all game objects
+// Sprite interface defines the basic contract for all game objects. The
+// physics engine moves objects with SetPosition; embedding Base provides
+// it along with Position.
 type Sprite interface {
 	Type() string
 	Draw(parent *ebiten.Image)
-	Position() utils.Position
+	Position() utils.Cell
+	SetPosition(pos utils.Cell)
+}
+
+// Tile is implemented by sprites lying flat on the floor: objects move over
+// them, and they are drawn beneath everything else
+type Tile interface {
+	Sprite
+	IsTile()
+}
+
+// Wide is implemented by sprites covering more than one cell. Their
+// position is their top left cell, and Footprint lists the steps from there
+// to every cell they cover, the zero step first.
+type Wide interface {
+	Sprite
+	Footprint() []utils.Vector
+}
+
+// Cells returns the cells s covers when it stands at pos
+func Cells(s Sprite, pos utils.Cell) []utils.Cell {
+	wide, ok := s.(Wide)
+	if !ok {
+		return []utils.Cell{pos}
+	}
+	footprint := wide.Footprint()
+	res := make([]utils.Cell, len(footprint))
+	for i, v := range footprint {
+		res[i] = pos.Add(v)
+	}
+	return res
+}
+
+// Covers reports whether s covers the cell pos
+func Covers(s Sprite, pos utils.Cell) bool {
+	wide, ok := s.(Wide)
+	if !ok {
+		return s.Position() == pos
+	}
+	for _, v := range wide.Footprint() {
+		if s.Position().Add(v) == pos {
+			return true
+		}
+	}
+	return false
 }
 
 // Base provides common functionality for game objects
 type Base struct {
-	position utils.Position
+	position utils.Cell
 }
 
 // NewBase creates a new base object
 func NewBase(x, y int) *Base {
 	return &Base{
-		position: utils.Position{X: x, Y: y},
+		position: utils.Cell{X: x, Y: y},
 	}
 }
 
-func (b *Base) Position() utils.Position {
+func (b *Base) Position() utils.Cell {
 	return b.position
 }
+
+// SetPosition moves the object to pos
+func (b *Base) SetPosition(pos utils.Cell) {
+	b.position = pos
+}
@@ -8,11 +8,10 @@ import (
 	"github.com/zrcoder/icer/internal/utils"
 )
 
+// Sprites are drawn on a grid of SpriteWidth x SpriteHeight pixel cells
 const (
-	SpriteWidth  = 10
-	SpriteHeight = 10
-
-	step = 10
+	SpriteWidth  = utils.CellSize
+	SpriteHeight = utils.CellSize
 )
 
 var (
@@ -24,6 +23,18 @@ var (
 	blue      = color.RGBA{0, 100, 255, 255}
 	orange    = color.RGBA{255, 165, 0, 255}
 	white     = color.RGBA{255, 255, 255, 255}
+	purple    = color.RGBA{160, 32, 240, 255}
+	yellow    = color.RGBA{255, 220, 0, 255}
+	black     = color.RGBA{0, 0, 0, 255}
+	lightGray = color.RGBA{190, 190, 190, 255}
+
+	beltGray  = color.RGBA{48, 48, 64, 255}
+	crackGray = color.RGBA{70, 70, 90, 255}
+	waterBlue = color.RGBA{30, 90, 160, 255}
+	mudBrown  = color.RGBA{90, 70, 45, 255}
+	frostBlue = color.RGBA{200, 230, 245, 255}
+
+	translucentGray = color.RGBA{32, 32, 32, 128}
 )
 
 type Wall struct {
@@ -46,6 +57,32 @@ func (w *Wall) Draw(parent *ebiten.Image) {
 	drawReact(parent, w.position, darkGray)
 }
 
+// FakeWall looks like a wall but lets the player walk through into a secret
+// cell; once discovered it is drawn translucent
+type FakeWall struct {
+	*Base
+	Revealed bool
+}
+
+func NewFakeWall(x, y int) *FakeWall {
+	wall := &FakeWall{
+		Base: NewBase(x, y),
+	}
+	return wall
+}
+
+func (w *FakeWall) Type() string {
+	return "fakewall"
+}
+
+func (w *FakeWall) Draw(parent *ebiten.Image) {
+	if w.Revealed {
+		drawReact(parent, w.position, translucentGray)
+	} else {
+		drawReact(parent, w.position, darkGray)
+	}
+}
+
 type Ice struct {
 	*Base
 }
@@ -65,6 +102,50 @@ func (i *Ice) Draw(parent *ebiten.Image) {
 	drawReact(parent, i.position, lightBlue)
 }
 
+// BigIce is two blocks of ice fused into one 1x2 block, lying across or
+// standing upright. It slides like ice and puts out every flame it lands on.
+type BigIce struct {
+	*Base
+	Vertical bool
+}
+
+func NewBigIce(x, y int, vertical bool) *BigIce {
+	ice := &BigIce{
+		Base:     NewBase(x, y),
+		Vertical: vertical,
+	}
+	return ice
+}
+
+func (i *BigIce) Type() string {
+	if i.Vertical {
+		return "bigice-v"
+	}
+	return "bigice"
+}
+
+// Footprint covers the block's cell and the one to its right, or below it
+// when upright
+func (i *BigIce) Footprint() []utils.Vector {
+	if i.Vertical {
+		return []utils.Vector{{}, {X: 0, Y: 1}}
+	}
+	return []utils.Vector{{}, {X: 1, Y: 0}}
+}
+
+func (i *BigIce) Draw(parent *ebiten.Image) {
+	p := i.position.Pixel()
+	w, h := float32(SpriteWidth), float32(SpriteHeight)
+	if i.Vertical {
+		h *= 2
+	} else {
+		w *= 2
+	}
+	vector.DrawFilledRect(parent, float32(p.X), float32(p.Y), w, h, lightBlue, false)
+	vector.StrokeRect(parent, float32(p.X)+2, float32(p.Y)+2, w-4, h-4, 2, white, false)
+}
+
+// Stone is a heavy block: pushed one cell at a time, it never slides or melts
 type Stone struct {
 	*Base
 }
@@ -85,6 +166,55 @@ func (s *Stone) Draw(parent *ebiten.Image) {
 	drawReact(parent, s.position, gray)
 }
 
+// Bomb is pushed one cell at a time like a stone and goes off when pushed
+// into a flame, blowing up the cracked walls around it
+type Bomb struct {
+	*Base
+}
+
+func NewBomb(x, y int) *Bomb {
+	bomb := &Bomb{
+		Base: NewBase(x, y),
+	}
+	return bomb
+}
+
+func (b *Bomb) Type() string {
+	return "bomb"
+}
+
+func (b *Bomb) Draw(parent *ebiten.Image) {
+	p := b.position.Center()
+	vector.DrawFilledCircle(parent, float32(p.X), float32(p.Y)+3, SpriteWidth/3, black, false)
+	vector.StrokeCircle(parent, float32(p.X), float32(p.Y)+3, SpriteWidth/3, 2, darkGray, false)
+	vector.DrawFilledCircle(parent, float32(p.X)+SpriteWidth/5, float32(p.Y)-SpriteHeight/4, 3, orange, false)
+}
+
+// CrackedWall is a wall that a bomb going off next to it destroys
+type CrackedWall struct {
+	*Base
+}
+
+func NewCrackedWall(x, y int) *CrackedWall {
+	wall := &CrackedWall{
+		Base: NewBase(x, y),
+	}
+	return wall
+}
+
+func (w *CrackedWall) Type() string {
+	return "crackedwall"
+}
+
+func (w *CrackedWall) Draw(parent *ebiten.Image) {
+	drawReact(parent, w.position, darkGray)
+	p := w.position.Pixel()
+	x, y := float32(p.X), float32(p.Y)
+	vector.StrokeLine(parent, x+SpriteWidth/4, y, x+SpriteWidth/2, y+SpriteHeight/2, 2, black, false)
+	vector.StrokeLine(parent, x+SpriteWidth/2, y+SpriteHeight/2, x+SpriteWidth/3, y+SpriteHeight, 2, black, false)
+	vector.StrokeLine(parent, x+SpriteWidth/2, y+SpriteHeight/2, x+SpriteWidth, y+SpriteHeight*2/3, 2, black, false)
+}
+
 type Flame struct {
 	*Base
 }
@@ -147,12 +277,26 @@ func (p *Player) Draw(parant *ebiten.Image) {
 	drawCircle(parant, p.position, blue)
 }
 
-func (p *Player) MoveLeft() {
-	p.position.X -= step
+// NPC is a friendly character that opens a dialog when bumped
+type NPC struct {
+	*Base
+	Index int
 }
 
-func (p *Player) MoveRight() {
-	p.position.X += step
+func NewNPC(x, y, index int) *NPC {
+	npc := &NPC{
+		Base:  NewBase(x, y),
+		Index: index,
+	}
+	return npc
+}
+
+func (n *NPC) Type() string {
+	return "npc"
+}
+
+func (n *NPC) Draw(parent *ebiten.Image) {
+	drawCircle(parent, n.position, yellow)
 }
 
 type Pot struct {
@@ -179,11 +323,503 @@ func (p *Pot) Draw(parent *ebiten.Image) {
 	}
 }
 
-func drawReact(parent *ebiten.Image, pos utils.Position, c color.Color) {
+type Gem struct {
+	*Base
+}
+
+func NewGem(x, y int) *Gem {
+	gem := &Gem{
+		Base: NewBase(x, y),
+	}
+	return gem
+}
+
+func (g *Gem) Type() string {
+	return "gem"
+}
+
+func (g *Gem) Draw(parent *ebiten.Image) {
+	drawCircle(parent, g.position, purple)
+}
+
+// Coin is an optional pickup; collecting every coin in a level earns a
+// bonus star
+type Coin struct {
+	*Base
+}
+
+func NewCoin(x, y int) *Coin {
+	coin := &Coin{
+		Base: NewBase(x, y),
+	}
+	return coin
+}
+
+func (c *Coin) Type() string {
+	return "coin"
+}
+
+func (c *Coin) Draw(parent *ebiten.Image) {
+	drawCircle(parent, c.position, yellow)
+}
+
+// Conveyor is a floor belt that carries whatever rests on it one cell in
+// its direction after every move
+type Conveyor struct {
+	*Base
+	Dir utils.Direction
+}
+
+func NewConveyor(x, y int, dir utils.Direction) *Conveyor {
+	conveyor := &Conveyor{
+		Base: NewBase(x, y),
+		Dir:  dir,
+	}
+	return conveyor
+}
+
+func (c *Conveyor) Type() string {
+	return "conveyor"
+}
+
+func (c *Conveyor) IsTile() {}
+
+func (c *Conveyor) Draw(parent *ebiten.Image) {
+	drawReact(parent, c.position, beltGray)
+	drawChevron(parent, c.position, c.Dir, lightBlue)
+}
+
+// OneWay is a floor tile that objects may only cross in the direction of
+// its arrow, entering and leaving it that way
+type OneWay struct {
+	*Base
+	Dir utils.Direction
+}
+
+func NewOneWay(x, y int, dir utils.Direction) *OneWay {
+	oneWay := &OneWay{
+		Base: NewBase(x, y),
+		Dir:  dir,
+	}
+	return oneWay
+}
+
+func (o *OneWay) Type() string {
+	return "oneway"
+}
+
+func (o *OneWay) IsTile() {}
+
+func (o *OneWay) Draw(parent *ebiten.Image) {
+	drawChevron(parent, o.position, o.Dir, lightGray)
+}
+
+// Fan is a solid blower that pushes ice sliding across the cells in front
+// of it one cell further in its direction
+type Fan struct {
+	*Base
+	Dir utils.Direction
+}
+
+func NewFan(x, y int, dir utils.Direction) *Fan {
+	fan := &Fan{
+		Base: NewBase(x, y),
+		Dir:  dir,
+	}
+	return fan
+}
+
+func (f *Fan) Type() string {
+	return "fan"
+}
+
+func (f *Fan) Draw(parent *ebiten.Image) {
+	drawReact(parent, f.position, darkGray)
+	drawChevron(parent, f.position, f.Dir, white)
+}
+
+// StickyFloor is a rough patch of floor: sliding ice stops the moment it
+// gets on, and the player needs two tries to get off
+type StickyFloor struct {
+	*Base
+}
+
+func NewStickyFloor(x, y int) *StickyFloor {
+	floor := &StickyFloor{
+		Base: NewBase(x, y),
+	}
+	return floor
+}
+
+func (f *StickyFloor) Type() string {
+	return "sticky"
+}
+
+func (f *StickyFloor) IsTile() {}
+
+func (f *StickyFloor) Draw(parent *ebiten.Image) {
+	drawReact(parent, f.position, mudBrown)
+	p := f.position.Pixel()
+	for i := range 5 {
+		x := float32(p.X) + float32(SpriteWidth*(2*i+1)/10)
+		y := float32(p.Y) + float32(SpriteHeight*(1+3*(i%3))/10)
+		vector.DrawFilledCircle(parent, x, y+4, 2, darkGray, false)
+	}
+}
+
+// CrackedFloor is a floor tile that gives way once something has crossed
+// it, leaving a Hole behind
+type CrackedFloor struct {
+	*Base
+}
+
+func NewCrackedFloor(x, y int) *CrackedFloor {
+	floor := &CrackedFloor{
+		Base: NewBase(x, y),
+	}
+	return floor
+}
+
+func (f *CrackedFloor) Type() string {
+	return "crackedfloor"
+}
+
+func (f *CrackedFloor) IsTile() {}
+
+func (f *CrackedFloor) Draw(parent *ebiten.Image) {
+	p := f.position.Pixel()
+	x, y := float32(p.X), float32(p.Y)
+	vector.StrokeLine(parent, x+8, y+6, x+20, y+18, 2, crackGray, false)
+	vector.StrokeLine(parent, x+20, y+18, x+16, y+32, 2, crackGray, false)
+	vector.StrokeLine(parent, x+20, y+18, x+33, y+24, 2, crackGray, false)
+}
+
+// Hole is what is left of a cracked floor: the player falls in, and ice
+// drops in and fills it
+type Hole struct {
+	*Base
+}
+
+func NewHole(x, y int) *Hole {
+	hole := &Hole{
+		Base: NewBase(x, y),
+	}
+	return hole
+}
+
+func (h *Hole) Type() string {
+	return "hole"
+}
+
+func (h *Hole) IsTile() {}
+
+func (h *Hole) Draw(parent *ebiten.Image) {
+	drawReact(parent, h.position, black)
+}
+
+// Water is a pool the player drowns in. Ice pushed into it freezes the
+// cell over, and stones sink out of sight.
+type Water struct {
+	*Base
+}
+
+func NewWater(x, y int) *Water {
+	water := &Water{
+		Base: NewBase(x, y),
+	}
+	return water
+}
+
+func (w *Water) Type() string {
+	return "water"
+}
+
+func (w *Water) IsTile() {}
+
+func (w *Water) Draw(parent *ebiten.Image) {
+	drawReact(parent, w.position, waterBlue)
+	p := w.position.Pixel()
+	x, y := float32(p.X), float32(p.Y)
+	for _, dy := range []float32{12, 26} {
+		vector.StrokeLine(parent, x+8, y+dy, x+18, y+dy-3, 2, lightBlue, false)
+		vector.StrokeLine(parent, x+18, y+dy-3, x+30, y+dy, 2, lightBlue, false)
+	}
+}
+
+// FrozenGround is water frozen over by a block of ice, safe to walk on
+type FrozenGround struct {
+	*Base
+}
+
+func NewFrozenGround(x, y int) *FrozenGround {
+	ground := &FrozenGround{
+		Base: NewBase(x, y),
+	}
+	return ground
+}
+
+func (g *FrozenGround) Type() string {
+	return "frozen"
+}
+
+func (g *FrozenGround) IsTile() {}
+
+func (g *FrozenGround) Draw(parent *ebiten.Image) {
+	drawReact(parent, g.position, frostBlue)
+}
+
+// Plate is a pressure plate: while something rests on it, the toggle walls
+// linked to it switch over
+type Plate struct {
+	*Base
+}
+
+func NewPlate(x, y int) *Plate {
+	plate := &Plate{
+		Base: NewBase(x, y),
+	}
+	return plate
+}
+
+func (p *Plate) Type() string {
+	return "plate"
+}
+
+func (p *Plate) IsTile() {}
+
+func (p *Plate) Draw(parent *ebiten.Image) {
+	pos := p.position.Pixel()
+	vector.DrawFilledRect(parent, float32(pos.X)+8, float32(pos.Y)+8, SpriteWidth-16, SpriteHeight-16, gray, false)
+	vector.StrokeRect(parent, float32(pos.X)+8, float32(pos.Y)+8, SpriteWidth-16, SpriteHeight-16, 2, lightGray, false)
+}
+
+// ToggleWall is a wall that pressure plates open and close. An inverted
+// one stands open until a linked plate is pressed.
+type ToggleWall struct {
+	*Base
+	Inverted bool
+	Open     bool
+}
+
+func NewToggleWall(x, y int, inverted bool) *ToggleWall {
+	wall := &ToggleWall{
+		Base:     NewBase(x, y),
+		Inverted: inverted,
+		Open:     inverted,
+	}
+	return wall
+}
+
+func (w *ToggleWall) Type() string {
+	return "togglewall"
+}
+
+func (w *ToggleWall) Draw(parent *ebiten.Image) {
+	p := w.position.Pixel()
+	if w.Open {
+		vector.StrokeRect(parent, float32(p.X)+2, float32(p.Y)+2, SpriteWidth-4, SpriteHeight-4, 2, darkGray, false)
+		return
+	}
+	drawReact(parent, w.position, darkGray)
+	for i := range 3 {
+		y := float32(p.Y) + float32(i+1)*SpriteHeight/4
+		vector.StrokeLine(parent, float32(p.X)+4, y, float32(p.X)+SpriteWidth-4, y, 2, gray, false)
+	}
+}
+
+// Lever is a post the player flips by walking into it, switching every
+// phase wall in the level between solid and passable
+type Lever struct {
+	*Base
+	On bool
+}
+
+func NewLever(x, y int) *Lever {
+	lever := &Lever{
+		Base: NewBase(x, y),
+	}
+	return lever
+}
+
+func (l *Lever) Type() string {
+	return "lever"
+}
+
+func (l *Lever) Draw(parent *ebiten.Image) {
+	p := l.position.Pixel()
+	x, y := float32(p.X), float32(p.Y)
+	vector.DrawFilledRect(parent, x+SpriteWidth/4, y+SpriteHeight*3/4, SpriteWidth/2, SpriteHeight/4, darkGray, false)
+	tip := x + SpriteWidth/4
+	if l.On {
+		tip = x + SpriteWidth*3/4
+	}
+	vector.StrokeLine(parent, x+SpriteWidth/2, y+SpriteHeight*3/4, tip, y+SpriteHeight/5, 3, lightGray, false)
+	vector.DrawFilledCircle(parent, tip, y+SpriteHeight/5, 4, red, false)
+}
+
+// PhaseWall is solid while the level's levers are off and passable while
+// they are on, or the other way around for an alternate wall
+type PhaseWall struct {
+	*Base
+	Alt   bool
+	Solid bool
+}
+
+func NewPhaseWall(x, y int, alt bool) *PhaseWall {
+	wall := &PhaseWall{
+		Base:  NewBase(x, y),
+		Alt:   alt,
+		Solid: !alt,
+	}
+	return wall
+}
+
+func (w *PhaseWall) Type() string {
+	return "phasewall"
+}
+
+func (w *PhaseWall) Draw(parent *ebiten.Image) {
+	clr := purple
+	if w.Alt {
+		clr = orange
+	}
+	if w.Solid {
+		drawReact(parent, w.position, clr)
+		return
+	}
+	p := w.position.Pixel()
+	vector.StrokeRect(parent, float32(p.X)+4, float32(p.Y)+4, SpriteWidth-8, SpriteHeight-8, 1, clr, false)
+}
+
+// KeyColor tells which doors a key opens
+type KeyColor int
+
+const (
+	KeyRed KeyColor = iota
+	KeyBlue
+	KeyGreen
+	KeyYellow
+)
+
+var keyColors = [...]color.RGBA{
+	KeyRed:    {220, 50, 50, 255},
+	KeyBlue:   {60, 120, 255, 255},
+	KeyGreen:  {60, 200, 90, 255},
+	KeyYellow: {240, 200, 40, 255},
+}
+
+var keyColorNames = [...]string{
+	KeyRed:    "red",
+	KeyBlue:   "blue",
+	KeyGreen:  "green",
+	KeyYellow: "yellow",
+}
+
+func (c KeyColor) String() string {
+	return keyColorNames[c]
+}
+
+// RGBA returns the color keys and doors of c are drawn in
+func (c KeyColor) RGBA() color.RGBA {
+	return keyColors[c]
+}
+
+// Key is picked up by walking over it, and opens every door of its color
+type Key struct {
+	*Base
+	Color KeyColor
+}
+
+func NewKey(x, y int, c KeyColor) *Key {
+	key := &Key{
+		Base:  NewBase(x, y),
+		Color: c,
+	}
+	return key
+}
+
+func (k *Key) Type() string {
+	return "key"
+}
+
+func (k *Key) Draw(parent *ebiten.Image) {
+	DrawKeyIcon(parent, k.position.Pixel(), k.Color)
+}
+
+// DrawKeyIcon draws a key of color c in the cell-sized square at p
+func DrawKeyIcon(parent *ebiten.Image, p utils.Pixel, c KeyColor) {
+	x, y := float32(p.X), float32(p.Y)
+	clr := c.RGBA()
+	vector.StrokeCircle(parent, x+13, y+20, 7, 3, clr, false)
+	vector.StrokeLine(parent, x+20, y+20, x+34, y+20, 3, clr, false)
+	vector.StrokeLine(parent, x+30, y+20, x+30, y+27, 3, clr, false)
+}
+
+// Door blocks the way until the player brings a key of its color
+type Door struct {
+	*Base
+	Color KeyColor
+	Open  bool
+}
+
+func NewDoor(x, y int, c KeyColor) *Door {
+	door := &Door{
+		Base:  NewBase(x, y),
+		Color: c,
+	}
+	return door
+}
+
+func (d *Door) Type() string {
+	return "door"
+}
+
+func (d *Door) Draw(parent *ebiten.Image) {
+	p := d.position.Pixel()
+	if d.Open {
+		vector.StrokeRect(parent, float32(p.X)+2, float32(p.Y)+2, SpriteWidth-4, SpriteHeight-4, 2, d.Color.RGBA(), false)
+		return
+	}
+	drawReact(parent, d.position, d.Color.RGBA())
+	vector.DrawFilledCircle(parent, float32(p.X)+SpriteWidth*3/4, float32(p.Y)+SpriteHeight/2, 3, darkGray, false)
+}
+
+// Enemy roams the level and catches the player on touch. It either walks
+// a fixed patrol, looping back to its start, or chases the player; either
+// way it takes a step each time its cooldown runs out.
+type Enemy struct {
+	*Base
+	Patrol   []utils.Direction
+	Chase    bool
+	Cooldown int // ticks between steps
+}
+
+func NewEnemy(x, y int) *Enemy {
+	enemy := &Enemy{
+		Base: NewBase(x, y),
+	}
+	return enemy
+}
+
+func (e *Enemy) Type() string {
+	return "enemy"
+}
+
+func (e *Enemy) Draw(parent *ebiten.Image) {
+	drawCircle(parent, e.position, purple)
+	p := e.position.Pixel()
+	for _, x := range []float32{SpriteWidth / 3, SpriteWidth * 2 / 3} {
+		vector.DrawFilledCircle(parent, float32(p.X)+x, float32(p.Y)+SpriteHeight*2/5, 4, white, false)
+		vector.DrawFilledCircle(parent, float32(p.X)+x, float32(p.Y)+SpriteHeight*2/5+1, 2, black, false)
+	}
+}
+
+func drawReact(parent *ebiten.Image, pos utils.Cell, c color.Color) {
+	p := pos.Pixel()
 	vector.DrawFilledRect(
 		parent,
-		float32(pos.X),
-		float32(pos.Y),
+		float32(p.X),
+		float32(p.Y),
 		SpriteWidth,
 		SpriteHeight,
 		c,
@@ -191,13 +827,27 @@ func drawReact(parent *ebiten.Image, pos utils.Position, c color.Color) {
 	)
 }
 
-func drawCircle(parent *ebiten.Image, pos utils.Position, c color.Color) {
+func drawCircle(parent *ebiten.Image, pos utils.Cell, c color.Color) {
+	p := pos.Center()
 	vector.DrawFilledCircle(
 		parent,
-		float32(pos.X),
-		float32(pos.Y),
+		float32(p.X),
+		float32(p.Y),
 		SpriteWidth/2,
 		c,
 		false,
 	)
 }
+
+// drawChevron draws an arrow head in the cell at pos pointing along dir
+func drawChevron(parent *ebiten.Image, pos utils.Cell, dir utils.Direction, c color.Color) {
+	center := pos.Center()
+	cx, cy := float32(center.X), float32(center.Y)
+	const size = SpriteWidth / 5
+	v := dir.Vector()
+	dx, dy := float32(v.X)*size, float32(v.Y)*size
+	// the two arms run back from the tip, spread across the direction
+	tipX, tipY := cx+dx, cy+dy
+	vector.StrokeLine(parent, tipX, tipY, cx-dx-dy*1.5, cy-dy-dx*1.5, 3, c, false)
+	vector.StrokeLine(parent, tipX, tipY, cx-dx+dy*1.5, cy-dy+dx*1.5, 3, c, false)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import "math"
+
+// CellSize is the side of a grid cell in pixels
+const CellSize = 40
+
+// Cell is a position on the level grid: X counts columns from the left and
+// Y rows from the top
+type Cell struct {
+	X int
+	Y int
+}
+
+// Pixel is a position on an image or the screen, in pixels from its top
+// left corner
+type Pixel struct {
+	X float64
+	Y float64
+}
+
+// Add returns the cell moved by v
+func (c Cell) Add(v Vector) Cell {
+	return Cell{X: c.X + v.X, Y: c.Y + v.Y}
+}
+
+// Pixel returns the top left corner of the cell
+func (c Cell) Pixel() Pixel {
+	return Pixel{X: float64(c.X * CellSize), Y: float64(c.Y * CellSize)}
+}
+
+// Center returns the middle of the cell
+func (c Cell) Center() Pixel {
+	return c.Pixel().Add(Pixel{X: CellSize / 2, Y: CellSize / 2})
+}
+
+// Add returns the sum of two pixel positions
+func (p Pixel) Add(other Pixel) Pixel {
+	return Pixel{X: p.X + other.X, Y: p.Y + other.Y}
+}
+
+// Cell returns the cell containing the pixel
+func (p Pixel) Cell() Cell {
+	return Cell{X: int(math.Floor(p.X / CellSize)), Y: int(math.Floor(p.Y / CellSize))}
+}
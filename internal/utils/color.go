@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"image/color"
+	"math"
+)
+
+// RGBA is a color with float channels in [0, 1], not premultiplied. Color
+// math runs on it so that rounding to 8 bits happens once, at the end.
+type RGBA struct {
+	R, G, B, A float64
+}
+
+// ToRGBA converts any color to float channels
+func ToRGBA(c color.Color) RGBA {
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	return RGBA{
+		R: float64(n.R) / 0xffff,
+		G: float64(n.G) / 0xffff,
+		B: float64(n.B) / 0xffff,
+		A: float64(n.A) / 0xffff,
+	}
+}
+
+// NRGBA rounds c to 8-bit channels
+func (c RGBA) NRGBA() color.NRGBA {
+	return color.NRGBA{R: to8(c.R), G: to8(c.G), B: to8(c.B), A: to8(c.A)}
+}
+
+// RGBA implements color.Color
+func (c RGBA) RGBA() (r, g, b, a uint32) {
+	return color.NRGBA64{R: to16(c.R), G: to16(c.G), B: to16(c.B), A: to16(c.A)}.RGBA()
+}
+
+// Premultiply returns the channels scaled by alpha, as ebiten's color
+// scales and vertex colors expect
+func (c RGBA) Premultiply() RGBA {
+	return RGBA{R: c.R * c.A, G: c.G * c.A, B: c.B * c.A, A: c.A}
+}
+
+// Unpremultiply undoes Premultiply
+func (c RGBA) Unpremultiply() RGBA {
+	if c.A == 0 {
+		return RGBA{}
+	}
+	return RGBA{R: c.R / c.A, G: c.G / c.A, B: c.B / c.A, A: c.A}
+}
+
+// Mix blends a towards b by t in [0, 1]
+func Mix(a, b color.Color, t float64) color.Color {
+	ca, cb := ToRGBA(a), ToRGBA(b)
+	return RGBA{
+		R: ca.R + (cb.R-ca.R)*t,
+		G: ca.G + (cb.G-ca.G)*t,
+		B: ca.B + (cb.B-ca.B)*t,
+		A: ca.A + (cb.A-ca.A)*t,
+	}
+}
+
+// HSL is a color as hue in degrees [0, 360), and saturation, lightness and
+// alpha in [0, 1]
+type HSL struct {
+	H, S, L, A float64
+}
+
+// ToHSL converts any color to hue, saturation and lightness
+func ToHSL(c color.Color) HSL {
+	f := ToRGBA(c)
+	hi := max(f.R, f.G, f.B)
+	lo := min(f.R, f.G, f.B)
+	res := HSL{L: (hi + lo) / 2, A: f.A}
+	d := hi - lo
+	if d == 0 {
+		return res
+	}
+	res.S = d / (1 - math.Abs(2*res.L-1))
+	switch hi {
+	case f.R:
+		res.H = math.Mod((f.G-f.B)/d, 6)
+	case f.G:
+		res.H = (f.B-f.R)/d + 2
+	default:
+		res.H = (f.R-f.G)/d + 4
+	}
+	res.H *= 60
+	if res.H < 0 {
+		res.H += 360
+	}
+	return res
+}
+
+// RGBA implements color.Color
+func (h HSL) RGBA() (r, g, b, a uint32) {
+	return h.ToRGBA().RGBA()
+}
+
+// ToRGBA converts h back to red, green and blue
+func (h HSL) ToRGBA() RGBA {
+	c := (1 - math.Abs(2*h.L-1)) * h.S
+	hue := math.Mod(math.Mod(h.H, 360)+360, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hue, 2)-1))
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := h.L - c/2
+	return RGBA{R: r + m, G: g + m, B: b + m, A: h.A}
+}
+
+// Lighten raises the lightness of c by amount in [0, 1]
+func Lighten(c color.Color, amount float64) color.Color {
+	h := ToHSL(c)
+	h.L = clamp01(h.L + amount)
+	return h.ToRGBA()
+}
+
+// Darken lowers the lightness of c by amount in [0, 1]
+func Darken(c color.Color, amount float64) color.Color {
+	return Lighten(c, -amount)
+}
+
+// WithAlpha returns c with its opacity set to a in [0, 1]
+func WithAlpha(c color.Color, a float64) color.Color {
+	f := ToRGBA(c)
+	f.A = clamp01(a)
+	return f
+}
+
+// Palette is a list of colors to interpolate between, such as the sky
+// through a day or a theme's shades
+type Palette []color.Color
+
+// At returns the color a fraction t in [0, 1] of the way along the palette,
+// mixing the two nearest entries
+func (p Palette) At(t float64) color.Color {
+	switch len(p) {
+	case 0:
+		return color.Transparent
+	case 1:
+		return p[0]
+	}
+	pos := clamp01(t) * float64(len(p)-1)
+	i := min(int(pos), len(p)-2)
+	return Mix(p[i], p[i+1], pos-float64(i))
+}
+
+func clamp01(v float64) float64 {
+	return min(max(v, 0), 1)
+}
+
+func to8(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 0xff))
+}
+
+func to16(v float64) uint16 {
+	return uint16(math.Round(clamp01(v) * 0xffff))
+}
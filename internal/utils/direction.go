@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Direction is one of the four ways to move on the grid. The values run
+// clockwise from Up, so turning is arithmetic.
+type Direction int
+
+const (
+	Up Direction = iota
+	Right
+	Down
+	Left
+)
+
+// Directions lists the four directions clockwise from Up
+var Directions = []Direction{Up, Right, Down, Left}
+
+var deltas = [...]Vector{
+	Up:    {X: 0, Y: -1},
+	Right: {X: 1, Y: 0},
+	Down:  {X: 0, Y: 1},
+	Left:  {X: -1, Y: 0},
+}
+
+var directionNames = [...]string{
+	Up:    "up",
+	Right: "right",
+	Down:  "down",
+	Left:  "left",
+}
+
+// directionLetters spell directions in move strings such as "RRUL"
+var directionLetters = [...]byte{
+	Up:    'U',
+	Right: 'R',
+	Down:  'D',
+	Left:  'L',
+}
+
+// Vector returns the one-cell step in d
+func (d Direction) Vector() Vector {
+	return deltas[d]
+}
+
+// Opposite returns the direction facing away from d
+func (d Direction) Opposite() Direction {
+	return (d + 2) % 4
+}
+
+// Clockwise returns d turned a quarter clockwise
+func (d Direction) Clockwise() Direction {
+	return (d + 1) % 4
+}
+
+// CounterClockwise returns d turned a quarter counterclockwise
+func (d Direction) CounterClockwise() Direction {
+	return (d + 3) % 4
+}
+
+// Mirror returns d reflected across the vertical axis, swapping left and right
+func (d Direction) Mirror() Direction {
+	if d == Left || d == Right {
+		return d.Opposite()
+	}
+	return d
+}
+
+// Horizontal reports whether d is Left or Right
+func (d Direction) Horizontal() bool {
+	return d == Left || d == Right
+}
+
+func (d Direction) String() string {
+	return directionNames[d]
+}
+
+// Letter returns the letter spelling d in a move string
+func (d Direction) Letter() byte {
+	return directionLetters[d]
+}
+
+// SpellDirections writes dirs as a move string, one letter each
+func SpellDirections(dirs []Direction) string {
+	b := make([]byte, len(dirs))
+	for i, d := range dirs {
+		b[i] = d.Letter()
+	}
+	return string(b)
+}
+
+// ParseDirections reads a move string such as "RRUL", ignoring case and
+// whitespace
+func ParseDirections(s string) ([]Direction, bool) {
+	var res []Direction
+	for _, r := range strings.ToUpper(s) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		i := strings.IndexRune(string(directionLetters[:]), r)
+		if i < 0 {
+			return nil, false
+		}
+		res = append(res, Direction(i))
+	}
+	return res, true
+}
+
+// ParseDirection returns the direction named s, ignoring case
+func ParseDirection(s string) (Direction, bool) {
+	for _, d := range Directions {
+		if strings.EqualFold(s, directionNames[d]) {
+			return d, true
+		}
+	}
+	return Up, false
+}
+
+// DirectionOf returns the direction of a one-cell step v
+func DirectionOf(v Vector) (Direction, bool) {
+	for _, d := range Directions {
+		if deltas[d] == v {
+			return d, true
+		}
+	}
+	return Up, false
+}
+
+// Step returns the neighbouring cell in d
+func (c Cell) Step(d Direction) Cell {
+	return c.Add(d.Vector())
+}
@@ -0,0 +1,43 @@
+package utils
+
+import "math"
+
+// Easing maps linear progress in [0, 1] to eased progress, starting at 0
+// and ending at 1
+type Easing func(t float64) float64
+
+// Linear keeps progress unchanged
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseInQuad starts slow and speeds up
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+// EaseOutQuad starts fast and slows down
+func EaseOutQuad(t float64) float64 {
+	return 1 - (1-t)*(1-t)
+}
+
+// EaseInOutQuad speeds up through the first half and slows down through
+// the second
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 2)/2
+}
+
+// EaseOutCubic slows down more sharply than EaseOutQuad
+func EaseOutCubic(t float64) float64 {
+	return 1 - math.Pow(1-t, 3)
+}
+
+// EaseOutBack overshoots slightly before settling at 1
+func EaseOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+}
@@ -0,0 +1,43 @@
+package utils
+
+// Rect is an axis-aligned rectangle spanning Min inclusive to Max exclusive
+type Rect struct {
+	Min Vec2
+	Max Vec2
+}
+
+// NewRect creates the rectangle with top left corner (x, y) and the given size
+func NewRect(x, y, width, height float64) Rect {
+	return Rect{Min: Vec2{X: x, Y: y}, Max: Vec2{X: x + width, Y: y + height}}
+}
+
+// Width returns the horizontal extent of r
+func (r Rect) Width() float64 {
+	return r.Max.X - r.Min.X
+}
+
+// Height returns the vertical extent of r
+func (r Rect) Height() float64 {
+	return r.Max.Y - r.Min.Y
+}
+
+// Center returns the middle of r
+func (r Rect) Center() Vec2 {
+	return r.Min.Lerp(r.Max, 0.5)
+}
+
+// Contains reports whether p lies inside r
+func (r Rect) Contains(p Vec2) bool {
+	return p.X >= r.Min.X && p.X < r.Max.X && p.Y >= r.Min.Y && p.Y < r.Max.Y
+}
+
+// Intersects reports whether r and other overlap
+func (r Rect) Intersects(other Rect) bool {
+	return r.Min.X < other.Max.X && other.Min.X < r.Max.X &&
+		r.Min.Y < other.Max.Y && other.Min.Y < r.Max.Y
+}
+
+// Translate returns r moved by v
+func (r Rect) Translate(v Vec2) Rect {
+	return Rect{Min: r.Min.Add(v), Max: r.Max.Add(v)}
+}
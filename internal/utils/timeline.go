@@ -0,0 +1,60 @@
+package utils
+
+// Timeline sequences scripted actions over game ticks
+type Timeline struct {
+	tick   int
+	events []event
+}
+
+type event struct {
+	start    int
+	duration int
+	action   func(progress float64)
+}
+
+// NewTimeline creates an empty timeline
+func NewTimeline() *Timeline {
+	return &Timeline{}
+}
+
+// At schedules action to run once when the timeline reaches tick
+func (t *Timeline) At(tick int, action func()) *Timeline {
+	return t.During(tick, 0, func(float64) { action() })
+}
+
+// During schedules action to run every tick from start for duration ticks,
+// passing the progress through the span in [0, 1]
+func (t *Timeline) During(start, duration int, action func(progress float64)) *Timeline {
+	t.events = append(t.events, event{start: start, duration: duration, action: action})
+	return t
+}
+
+// Update advances the timeline by one tick and runs the due actions
+func (t *Timeline) Update() {
+	for _, e := range t.events {
+		if t.tick < e.start || t.tick > e.start+e.duration {
+			continue
+		}
+		progress := 1.0
+		if e.duration > 0 {
+			progress = float64(t.tick-e.start) / float64(e.duration)
+		}
+		e.action(progress)
+	}
+	t.tick++
+}
+
+// Tick returns the number of ticks elapsed
+func (t *Timeline) Tick() int {
+	return t.tick
+}
+
+// Done reports whether every scheduled action has finished
+func (t *Timeline) Done() bool {
+	for _, e := range t.events {
+		if t.tick <= e.start+e.duration {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package utils
+
+// Timer counts a number of ticks down to zero. The zero Timer is already
+// done.
+type Timer struct {
+	duration int
+	left     int
+	paused   bool
+}
+
+// NewTimer creates a timer that is done after ticks updates
+func NewTimer(ticks int) Timer {
+	return Timer{duration: ticks, left: ticks}
+}
+
+// Update advances the timer by one tick unless it is paused, and reports
+// whether it ran out on this tick
+func (t *Timer) Update() bool {
+	if t.paused || t.left == 0 {
+		return false
+	}
+	t.left--
+	return t.left == 0
+}
+
+// Reset starts the countdown over
+func (t *Timer) Reset() {
+	t.left = t.duration
+}
+
+// Pause stops the countdown until Resume
+func (t *Timer) Pause() {
+	t.paused = true
+}
+
+// Resume continues a paused countdown
+func (t *Timer) Resume() {
+	t.paused = false
+}
+
+// Done reports whether the timer has run out
+func (t *Timer) Done() bool {
+	return t.left == 0
+}
+
+// Left returns the number of ticks until the timer runs out
+func (t *Timer) Left() int {
+	return t.left
+}
+
+// Progress returns how far the countdown has gone, in [0, 1]
+func (t *Timer) Progress() float64 {
+	if t.duration == 0 {
+		return 1
+	}
+	return 1 - float64(t.left)/float64(t.duration)
+}
+
+// Scheduler runs actions after a number of ticks, once or repeatedly. While
+// paused, no time passes for any of them.
+type Scheduler struct {
+	tasks  []*Task
+	paused bool
+}
+
+// Task is an action waiting in a scheduler
+type Task struct {
+	timer   Timer
+	repeat  bool
+	action  func()
+	stopped bool
+}
+
+// After schedules action to run once, ticks updates from now
+func (s *Scheduler) After(ticks int, action func()) *Task {
+	return s.add(&Task{timer: NewTimer(max(ticks, 1)), action: action})
+}
+
+// Every schedules action to run every ticks updates until stopped
+func (s *Scheduler) Every(ticks int, action func()) *Task {
+	return s.add(&Task{timer: NewTimer(max(ticks, 1)), repeat: true, action: action})
+}
+
+func (s *Scheduler) add(t *Task) *Task {
+	s.tasks = append(s.tasks, t)
+	return t
+}
+
+// Update advances every task by one tick and runs the due actions. Tasks
+// scheduled by those actions start counting on the next update.
+func (s *Scheduler) Update() {
+	if s.paused {
+		return
+	}
+	tasks := s.tasks
+	for _, t := range tasks {
+		if t.stopped || !t.timer.Update() {
+			continue
+		}
+		t.action()
+		if t.repeat {
+			t.timer.Reset()
+		} else {
+			t.stopped = true
+		}
+	}
+	remaining := s.tasks[:0]
+	for _, t := range s.tasks {
+		if !t.stopped {
+			remaining = append(remaining, t)
+		}
+	}
+	clear(s.tasks[len(remaining):])
+	s.tasks = remaining
+}
+
+// Pause freezes every task until Resume
+func (s *Scheduler) Pause() {
+	s.paused = true
+}
+
+// Resume lets time pass for the tasks again
+func (s *Scheduler) Resume() {
+	s.paused = false
+}
+
+// Paused reports whether the scheduler is paused
+func (s *Scheduler) Paused() bool {
+	return s.paused
+}
+
+// Clear drops every task
+func (s *Scheduler) Clear() {
+	s.tasks = nil
+}
+
+// Stop cancels the task; its action will not run again
+func (t *Task) Stop() {
+	t.stopped = true
+}
+
+// Left returns the number of ticks until the task's action next runs
+func (t *Task) Left() int {
+	return t.timer.Left()
+}
@@ -0,0 +1,121 @@
+package utils
+
+// Tween drives a change over a number of ticks, passing the eased progress
+// in [0, 1] to its apply function on every tick
+type Tween struct {
+	ticks      int
+	tick       int
+	ease       Easing
+	apply      func(progress float64)
+	onComplete func()
+	done       bool
+}
+
+// NewTween creates a tween lasting ticks updates, shaped by ease, or run
+// linearly when ease is nil
+func NewTween(ticks int, ease Easing, apply func(progress float64)) *Tween {
+	if ease == nil {
+		ease = Linear
+	}
+	return &Tween{ticks: max(ticks, 1), ease: ease, apply: apply}
+}
+
+// TweenFloat moves *target from its current value to to
+func TweenFloat(target *float64, to float64, ticks int, ease Easing) *Tween {
+	from := *target
+	return NewTween(ticks, ease, func(p float64) {
+		*target = from + (to-from)*p
+	})
+}
+
+// TweenVec2 moves *target from its current value to to in a straight line
+func TweenVec2(target *Vec2, to Vec2, ticks int, ease Easing) *Tween {
+	from := *target
+	return NewTween(ticks, ease, func(p float64) {
+		*target = from.Lerp(to, p)
+	})
+}
+
+// OnComplete sets fn to run once the tween reaches its end, but not when
+// it is cancelled
+func (t *Tween) OnComplete(fn func()) *Tween {
+	t.onComplete = fn
+	return t
+}
+
+// Update advances the tween by one tick
+func (t *Tween) Update() {
+	if t.done {
+		return
+	}
+	t.tick++
+	t.apply(t.Progress())
+	if t.tick >= t.ticks {
+		t.done = true
+		if t.onComplete != nil {
+			t.onComplete()
+		}
+	}
+}
+
+// Progress returns the eased progress of the tween
+func (t *Tween) Progress() float64 {
+	return t.ease(float64(t.tick) / float64(t.ticks))
+}
+
+// Finish jumps the tween to its end
+func (t *Tween) Finish() {
+	if !t.done {
+		t.tick = t.ticks - 1
+		t.Update()
+	}
+}
+
+// Cancel stops the tween where it is, without completing it
+func (t *Tween) Cancel() {
+	t.done = true
+}
+
+// Done reports whether the tween has finished or been cancelled
+func (t *Tween) Done() bool {
+	return t.done
+}
+
+// Tweens runs a set of tweens side by side, dropping each once done
+type Tweens struct {
+	list []*Tween
+}
+
+// Add starts t alongside the others
+func (s *Tweens) Add(t *Tween) *Tween {
+	s.list = append(s.list, t)
+	return t
+}
+
+// Update advances every running tween by one tick. Tweens added by
+// completion callbacks start on the next update.
+func (s *Tweens) Update() {
+	current := s.list
+	s.list = nil
+	var running []*Tween
+	for _, t := range current {
+		t.Update()
+		if !t.Done() {
+			running = append(running, t)
+		}
+	}
+	s.list = append(running, s.list...)
+}
+
+// Busy reports whether any tween is still running
+func (s *Tweens) Busy() bool {
+	return len(s.list) > 0
+}
+
+// Cancel stops every running tween
+func (s *Tweens) Cancel() {
+	for _, t := range s.list {
+		t.Cancel()
+	}
+	s.list = nil
+}
@@ -0,0 +1,76 @@
+package utils
+
+import "math"
+
+// Vec2 is a 2D vector with float components, for smooth motion such as
+// cameras, tweens and particles; grid math stays on Vector
+type Vec2 struct {
+	X float64
+	Y float64
+}
+
+// Vec2 returns v with float components
+func (v Vector) Vec2() Vec2 {
+	return Vec2{X: float64(v.X), Y: float64(v.Y)}
+}
+
+// Vec2 returns the pixel as a vector from the image's top left corner
+func (p Pixel) Vec2() Vec2 {
+	return Vec2{X: p.X, Y: p.Y}
+}
+
+// Vector returns v rounded to the nearest int components
+func (v Vec2) Vector() Vector {
+	return Vector{X: int(math.Round(v.X)), Y: int(math.Round(v.Y))}
+}
+
+// Pixel returns the pixel v points at from the image's top left corner
+func (v Vec2) Pixel() Pixel {
+	return Pixel{X: v.X, Y: v.Y}
+}
+
+// Add returns the sum of two vectors
+func (v Vec2) Add(other Vec2) Vec2 {
+	return Vec2{X: v.X + other.X, Y: v.Y + other.Y}
+}
+
+// Sub returns the difference of two vectors
+func (v Vec2) Sub(other Vec2) Vec2 {
+	return Vec2{X: v.X - other.X, Y: v.Y - other.Y}
+}
+
+// Scale returns v multiplied by a scalar
+func (v Vec2) Scale(scalar float64) Vec2 {
+	return Vec2{X: v.X * scalar, Y: v.Y * scalar}
+}
+
+// Dot returns the dot product of two vectors
+func (v Vec2) Dot(other Vec2) float64 {
+	return v.X*other.X + v.Y*other.Y
+}
+
+// Len returns the length of v
+func (v Vec2) Len() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize returns v scaled to length 1, or the zero vector if v is zero
+func (v Vec2) Normalize() Vec2 {
+	l := v.Len()
+	if l == 0 {
+		return Vec2{}
+	}
+	return v.Scale(1 / l)
+}
+
+// Lerp returns the point a fraction t of the way from v to other
+func (v Vec2) Lerp(other Vec2, t float64) Vec2 {
+	return Vec2{X: v.X + (other.X-v.X)*t, Y: v.Y + (other.Y-v.Y)*t}
+}
+
+// Rotate returns v turned by angle radians, clockwise on screen where Y
+// points down
+func (v Vec2) Rotate(angle float64) Vec2 {
+	sin, cos := math.Sincos(angle)
+	return Vec2{X: v.X*cos - v.Y*sin, Y: v.X*sin + v.Y*cos}
+}
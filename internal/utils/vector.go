@@ -1,13 +1,11 @@
 package utils
 
-// Vector represents a 2D vector
+// Vector represents a 2D vector, such as a direction or a distance in cells
 type Vector struct {
 	X int
 	Y int
 }
 
-type Position Vector
-
 // Add returns the sum of two vectors
 func (v Vector) Add(other Vector) Vector {
 	return Vector{X: v.X + other.X, Y: v.Y + other.Y}
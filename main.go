@@ -1,9 +1,46 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
 	"github.com/charmbracelet/log"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/zrcoder/icer/internal/game"
+	"github.com/zrcoder/icer/internal/i18n"
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/links"
+	"github.com/zrcoder/icer/internal/profile"
+	"github.com/zrcoder/icer/internal/solver"
+)
+
+var (
+	registerLinks = flag.Bool("register-links", false, "make this executable open icer:// links, then exit")
+	exportProfile = flag.String("export-profile", "", "write the player profile to this archive, then exit")
+	importProfile = flag.String("import-profile", "", "replace the player profile with this archive, then exit")
+	classroomDir  = flag.String("classroom", "", "record the session's results for a teacher, exported as CSV into this folder on exit")
+	student       = flag.String("student", "", "the student's name in classroom mode")
+	ambience      = flag.Float64("ambience-volume", game.DefaultAmbienceVolume, "volume of the background ambience, from 0 to 1")
+	packsDir      = flag.String("levels", "", "load external level packs from this folder instead of the profile's levels folder")
+	captions      = flag.Bool("captions", false, "show captions for sound cues, also toggled with F10")
+	largeHUD      = flag.Bool("large-hud", false, "start with the large-text HUD, also toggled with F8")
+	seasonal      = flag.Bool("events", true, "celebrate seasonal events, such as with snow on the menus in December; -events=false opts out")
+	clockTheme    = flag.Bool("clock-theme", false, "shade backgrounds from day to dusk to night with the system clock, also toggled with F5")
+	lang          = flag.String("lang", i18n.System(), "show level text in this language, such as fr or pt-BR, where levels have it")
+	font          = flag.String("font", "", "draw text with this TrueType or OpenType font file, falling back on the bundled and system fonts")
+	devMode       = flag.Bool("dev", os.Getenv("ICER_DEV") != "", "reload levels as their files change, reading the built-in ones from the source tree; also set by ICER_DEV")
+	solveLevels   = flag.Bool("solve-levels", false, "solve every level, report any that can't be won, then exit")
+	solveWorkers  = flag.Int("solve-workers", runtime.NumCPU(), "how many levels -solve-levels solves at once")
+	solveJSON     = flag.Bool("json", false, "with -solve-levels, write how each level went to stdout as a line of JSON")
+	hardcore      = flag.Bool("hardcore", false, "make the profile hardcore for good, with no undo or hints")
+	kioskMode     = flag.Bool("kiosk", false, "lock the game down for unattended kiosks, quitting only with the passcode in kiosk.toml")
 )
 
 func init() {
@@ -11,8 +48,236 @@ func init() {
 	log.SetLevel(log.DebugLevel)
 }
 func main() {
+	flag.Parse()
+	var notices []string
+	kiosk, err := game.LoadKioskConfig()
+	if err != nil {
+		log.Error("cannot load kiosk settings", "err", err)
+		notices = append(notices, "kiosk.toml is damaged, so kiosk settings were left out")
+	}
+	kiosk.Enabled = kiosk.Enabled || *kioskMode
+	if kiosk.Enabled {
+		if kiosk.Passcode == "" {
+			log.Fatal("kiosk mode needs a passcode in kiosk.toml")
+		}
+		if *registerLinks || *exportProfile != "" || *importProfile != "" {
+			log.Fatal("links and profiles cannot be changed in kiosk mode")
+		}
+	}
+	if *registerLinks {
+		exe, err := os.Executable()
+		if err == nil {
+			err = links.Register(exe)
+		}
+		if err != nil {
+			log.Fatal("cannot register links", "err", err)
+		}
+		log.Info("registered", "scheme", links.Scheme, "exe", exe)
+		return
+	}
+	if *exportProfile != "" || *importProfile != "" {
+		if err := transferProfile(*exportProfile, *importProfile); err != nil {
+			log.Fatal("cannot transfer profile", "err", err)
+		}
+		return
+	}
+	if *packsDir != "" {
+		levels.SetPacksDir(*packsDir)
+	}
+	levels.SetDev(*devMode)
+	i18n.Set(*lang)
+	game.SetFont(*font)
+	if *solveLevels {
+		if !checkLevels(*solveWorkers, *solveJSON) {
+			os.Exit(1)
+		}
+		return
+	}
+	audioConfig, err := game.LoadAudioConfig()
+	if err == nil {
+		err = game.SetupAudio(audioConfig)
+	}
+	if err != nil {
+		log.Error("cannot load audio settings", "err", err)
+		notices = append(notices, "audio.toml is damaged, so the default audio settings are used")
+		game.SetupAudio(game.DefaultAudioConfig())
+	}
 	g := game.NewGame()
-	if err := ebiten.RunGame(g); err != nil {
+	for _, n := range notices {
+		g.AddNotice(n)
+	}
+	g.SetLargeHUD(*largeHUD)
+	g.SetCaptions(*captions)
+	g.SetClockTheme(*clockTheme)
+	g.SetEvents(*seasonal)
+	g.SetAmbienceVolume(*ambience)
+	if *hardcore {
+		g.SetHardcore()
+	}
+	if kiosk.Enabled {
+		g.SetKiosk(kiosk.Passcode)
+	}
+	if *classroomDir != "" {
+		if *student == "" {
+			log.Fatal("classroom mode needs a -student name")
+		}
+		g.SetClassroom(*student, *classroomDir)
+	}
+	if link, ok, err := links.Launch(flag.Args()); err != nil {
+		log.Error("bad link", "err", err)
+	} else if ok {
+		g.Open(link)
+	}
+	err = ebiten.RunGame(g)
+	if path, err := g.ExportClassroom(); err != nil {
+		log.Error("cannot export classroom results", "err", err)
+	} else if path != "" {
+		log.Info("classroom results exported", "path", path)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// levelCheck is how checking one level went, as written by -solve-levels
+// -json, one line per level
+type levelCheck struct {
+	Level string `json:"level"`
+	Pack  string `json:"pack,omitempty"`
+	Title string `json:"title"`
+	// Status is solved, broken, unsolvable, unchecked or par, the last for
+	// levels solved in fewer or more moves than their par
+	Status     string   `json:"status"`
+	Moves      int      `json:"moves,omitempty"`
+	Par        int      `json:"par,omitempty"`
+	Difficulty int      `json:"difficulty,omitempty"`
+	Problems   []string `json:"problems,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// checkLevels solves every level across workers goroutines, reporting
+// how each went as soon as it is done, and reports whether all of them
+// can be won as far as the solver can tell. Reports are logged, or
+// written to stdout as JSON lines when asJSON is set.
+func checkLevels(workers int, asJSON bool) bool {
+	type job struct {
+		section *levels.Section
+		index   int
+	}
+	m := levels.NewManager()
+	jobs := make(chan job)
+	results := make(chan levelCheck)
+	go func() {
+		for _, s := range m.Sections {
+			for i := range s.LevelCount + s.BonusCount {
+				jobs <- job{s, i}
+			}
+		}
+		close(jobs)
+	}()
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			for j := range jobs {
+				results <- checkLevel(j.section, j.index)
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	ok := true
+	enc := json.NewEncoder(os.Stdout)
+	for c := range results {
+		if c.Status == "broken" || c.Status == "unsolvable" {
+			ok = false
+		}
+		if asJSON {
+			if err := enc.Encode(c); err != nil {
+				log.Fatal("cannot write results", "err", err)
+			}
+			continue
+		}
+		switch c.Status {
+		case "broken":
+			log.Error("level is broken", "level", c.Level, "problems", strings.Join(c.Problems, "; "))
+		case "unsolvable":
+			log.Error("level cannot be won", "level", c.Level, "title", c.Title)
+		case "unchecked":
+			log.Warn("level not checked", "level", c.Level, "title", c.Title, "err", c.Error)
+		case "par":
+			log.Warn("level par differs from the shortest solution", "level", c.Level, "par", c.Par, "moves", c.Moves)
+		default:
+			log.Info("level solved", "level", c.Level, "title", c.Title, "moves", c.Moves, "difficulty", c.Difficulty)
+		}
+	}
+	return ok
+}
+
+// checkLevel solves level i of s
+func checkLevel(s *levels.Section, i int) levelCheck {
+	level := s.Level(i)
+	c := levelCheck{
+		Level: fmt.Sprintf("%d-%d", s.ID+1, i+1),
+		Pack:  s.Pack,
+		Title: level.Title,
+		Par:   level.Par,
+	}
+	if problems := level.Problems(); len(problems) > 0 {
+		c.Status = "broken"
+		for _, p := range problems {
+			c.Problems = append(c.Problems, p.String())
+		}
+		return c
+	}
+	d, err := solver.Rate(context.Background(), s, level, solver.MaxStates)
+	switch {
+	case errors.Is(err, solver.ErrUnsolvable):
+		c.Status = "unsolvable"
+	case err != nil:
+		c.Status = "unchecked"
+		c.Error = err.Error()
+	case level.Par > 0 && level.Par != d.Moves:
+		c.Status = "par"
+	default:
+		c.Status = "solved"
+	}
+	c.Moves, c.Difficulty = d.Moves, d.Score
+	return c
+}
+
+// transferProfile exports the profile to one archive and imports another,
+// whichever is given
+func transferProfile(exportPath, importPath string) error {
+	if exportPath != "" {
+		f, err := os.Create(exportPath)
+		if err != nil {
+			return err
+		}
+		if err := profile.Export(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		log.Info("profile exported", "path", exportPath)
+	}
+	if importPath != "" {
+		f, err := os.Open(importPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if err := profile.Import(f, info.Size()); err != nil {
+			return err
+		}
+		log.Info("profile imported", "path", importPath)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package icer is the public surface for mods compiled into the game.
+//
+// A mod is an ordinary Go package that registers its extensions from an
+// init function. It is enabled by a build-tagged file in package main that
+// imports it for side effects, so forks can add content without patching
+// internal packages.
+package icer
+
+import (
+	"github.com/zrcoder/icer/internal/levels"
+	"github.com/zrcoder/icer/internal/physics"
+	"github.com/zrcoder/icer/internal/rendering"
+	"github.com/zrcoder/icer/internal/rules"
+	"github.com/zrcoder/icer/internal/sprites"
+	"github.com/zrcoder/icer/internal/utils"
+)
+
+// Sprite is the contract every game object implements, SetPosition
+// included, as the physics engine moves sprites with it
+type Sprite = sprites.Sprite
+
+// Base provides the grid position shared by all sprites, with Position and
+// SetPosition; embed it in custom sprites
+type Base = sprites.Base
+
+// Move is an object's slide from one cell along a path of cells
+type Move = physics.Move
+
+// World is what hooks see of the level being played
+type World = rules.World
+
+// Hook extends the rules of every level
+type Hook = rules.Hook
+
+// Layer draws over every board
+type Layer = rendering.Layer
+
+// Cell is a position on the level grid
+type Cell = utils.Cell
+
+// Position is the former name of Cell
+//
+// Deprecated: use Cell.
+type Position = Cell
+
+// NewBase creates a base placed at a grid cell
+func NewBase(x, y int) *Base {
+	return sprites.NewBase(x, y)
+}
+
+// RegisterSprite makes levels place the sprite built by ctor wherever char
+// appears in a grid. It fails if char is reserved or already registered.
+func RegisterSprite(char rune, ctor func(x, y int) Sprite) error {
+	return levels.RegisterSprite(char, ctor)
+}
+
+// RegisterHook adds h to the rules of every level. Levels played with
+// hooks get no hints, as the solver only knows the built-in rules.
+func RegisterHook(h Hook) {
+	rules.RegisterHook(h)
+}
+
+// RegisterLayer adds l to the layers drawn over every board
+func RegisterLayer(l Layer) {
+	rendering.RegisterLayer(l)
+}